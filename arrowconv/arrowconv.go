@@ -0,0 +1,388 @@
+// Package arrowconv converts .yxdb files to the Apache Arrow IPC stream and file formats.
+//
+// Field types are mapped to Arrow types as follows:
+//
+//	Bool                                  bool
+//	Byte                                  uint8
+//	Int16, Int32, Int64                   int16, int32, int64
+//	FixedDecimal                          decimal128(size, scale), or decimal256 above 38 digits
+//	Float, Double                         float32, float64
+//	String, WString, V_String, V_WString  utf8
+//	Date                                  date32
+//	DateTime                              timestamp[us] without a time zone
+//	Blob                                  binary
+//	SpatialObj                            binary holding WKB, with the geoarrow.wkb extension type
+//
+// Every Arrow field is nullable. Records are written in batches, so files of any size can be converted without
+// holding them in memory.
+package arrowconv
+
+import (
+	"encoding/binary"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/convert"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"math"
+	"math/big"
+)
+
+// Format is the Arrow IPC format written by a Writer.
+type Format int
+
+const (
+	// Stream is the Arrow IPC streaming format, usually saved with the .arrows extension.
+	Stream Format = iota
+
+	// File is the Arrow IPC file format, also known as Feather V2, usually saved with the .arrow or .feather
+	// extension. Unlike Stream, it ends with a footer that allows readers to jump to any record batch.
+	File
+)
+
+// DefaultBatchSize is the number of records in each record batch when Options.BatchSize is not set.
+const DefaultBatchSize = 65536
+
+// Options configures Convert and ConvertFile.
+type Options struct {
+	Format Format
+
+	// BatchSize is the maximum number of records in each Arrow record batch. Values below 1 use DefaultBatchSize.
+	BatchSize int
+}
+
+var fileMagic = []byte("ARROW1\x00\x00")
+
+var continuation = []byte{0xff, 0xff, 0xff, 0xff}
+
+// ConvertFile converts the .yxdb file at yxdbPath to an Arrow file or stream at arrowPath, replacing it if it
+// exists.
+func ConvertFile(yxdbPath string, arrowPath string, options Options) error {
+	return convert.File(yxdbPath, arrowPath, func(w io.Writer, reader yx.Reader) error {
+		return Convert(w, reader, options)
+	})
+}
+
+// Convert writes the remaining records of reader to w in Arrow IPC format. The fields of the Arrow schema are the
+// fields of the reader.
+func Convert(w io.Writer, reader yx.Reader, options Options) error {
+	batchSize := options.BatchSize
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+	writer, err := NewWriter(w, reader.ListFields(), options.Format)
+	if err != nil {
+		return err
+	}
+	return convert.Copy(writer, reader, func() int { return batchSize })
+}
+
+// A Writer writes batches of records read with Reader.NextBatch as Arrow record batches.
+//
+// The schema is written by NewWriter. Close must be called after the last batch to end the stream and, in the File
+// format, to write the footer. Close does not close the underlying io.Writer.
+type Writer struct {
+	out     io.Writer
+	written int64
+	format  Format
+	fields  []yxrecord.YxdbField
+	schema  fbTable
+	blocks  []block
+	closed  bool
+}
+
+// block locates a record batch in the File format.
+type block struct {
+	offset         int64
+	metadataLength int
+	bodyLength     int64
+}
+
+// NewWriter writes the Arrow schema for fields to w and returns a Writer for batches with those fields.
+func NewWriter(w io.Writer, fields []yxrecord.YxdbField, format Format) (*Writer, error) {
+	schema, err := schemaTable(fields)
+	if err != nil {
+		return nil, err
+	}
+	writer := &Writer{out: w, format: format, fields: fields, schema: schema}
+	if format == File {
+		err = writer.write(fileMagic)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = writer.writeMessage(headerSchema, schema, nil)
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write writes batch as a single Arrow record batch. The columns of batch must match the fields passed to NewWriter.
+func (w *Writer) Write(batch *yx.Batch) error {
+	if w.closed {
+		return convert.ErrWriterClosed
+	}
+	if len(batch.Columns) != len(w.fields) {
+		return fmt.Errorf(`expected a batch with %v columns but got %v`, len(w.fields), len(batch.Columns))
+	}
+	body := &recordBatchBody{}
+	for index := range batch.Columns {
+		err := body.addColumn(&batch.Columns[index], batch.Len)
+		if err != nil {
+			return err
+		}
+	}
+	recordBatch := fbTable{
+		fbScalar(8, uint64(batch.Len)),
+		fbOffset(fbStructs{count: len(body.nodes) / 16, bytes: body.nodes}),
+		fbOffset(fbStructs{count: len(body.buffers) / 16, bytes: body.buffers}),
+	}
+	written, err := w.writeMessage(headerRecordBatch, recordBatch, body.data)
+	if err != nil {
+		return err
+	}
+	w.blocks = append(w.blocks, written)
+	return nil
+}
+
+// Close ends the Arrow stream and, in the File format, writes the footer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.write(append(continuation, 0, 0, 0, 0))
+	if err != nil || w.format != File {
+		return err
+	}
+	blocks := make([]byte, 0, 24*len(w.blocks))
+	for _, written := range w.blocks {
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(written.offset))
+		blocks = binary.LittleEndian.AppendUint32(blocks, uint32(written.metadataLength))
+		blocks = binary.LittleEndian.AppendUint32(blocks, 0)
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(written.bodyLength))
+	}
+	footer := finish(fbTable{
+		fbScalar(2, metadataV5),
+		fbOffset(w.schema),
+		fbOffset(fbStructs{}),
+		fbOffset(fbStructs{count: len(w.blocks), bytes: blocks}),
+	})
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return w.write(append(footer, fileMagic[:6]...))
+}
+
+// writeMessage writes an encapsulated IPC message: a continuation marker, the length of the metadata, the Message
+// FlatBuffer, and the body.
+func (w *Writer) writeMessage(headerType byte, header fbTable, body []byte) (block, error) {
+	metadata := finish(fbTable{
+		fbScalar(2, metadataV5),
+		fbScalar(1, uint64(headerType)),
+		fbOffset(header),
+		fbScalar(8, uint64(len(body))),
+	})
+	written := block{offset: w.written, metadataLength: 8 + len(metadata), bodyLength: int64(len(body))}
+	prefix := binary.LittleEndian.AppendUint32(append([]byte{}, continuation...), uint32(len(metadata)))
+	err := w.write(prefix)
+	if err == nil {
+		err = w.write(metadata)
+	}
+	if err == nil {
+		err = w.write(body)
+	}
+	return written, err
+}
+
+func (w *Writer) write(data []byte) error {
+	n, err := w.out.Write(data)
+	w.written += int64(n)
+	return err
+}
+
+// recordBatchBody accumulates the FieldNode and Buffer structs of a record batch along with the body holding the
+// buffers. Every buffer starts on an 8 byte boundary.
+type recordBatchBody struct {
+	nodes   []byte
+	buffers []byte
+	data    []byte
+}
+
+func (b *recordBatchBody) addBuffer(data []byte) {
+	b.buffers = binary.LittleEndian.AppendUint64(b.buffers, uint64(len(b.data)))
+	b.buffers = binary.LittleEndian.AppendUint64(b.buffers, uint64(len(data)))
+	b.data = append(b.data, data...)
+	for len(b.data)%8 != 0 {
+		b.data = append(b.data, 0)
+	}
+}
+
+func (b *recordBatchBody) addColumn(column *yx.Column, length int) error {
+	validity, nullCount := validityBitmap(column, length)
+	b.nodes = binary.LittleEndian.AppendUint64(b.nodes, uint64(length))
+	b.nodes = binary.LittleEndian.AppendUint64(b.nodes, uint64(nullCount))
+	b.addBuffer(validity)
+
+	switch column.Field.FieldType {
+	case yxrecord.TypeBool:
+		values := make([]byte, (length+7)/8)
+		for index, value := range column.Bools {
+			if value {
+				values[index/8] |= 1 << (index % 8)
+			}
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeByte:
+		b.addBuffer(column.Bytes)
+	case yxrecord.TypeInt16:
+		values := make([]byte, 0, 2*length)
+		for _, value := range column.Int64s {
+			values = binary.LittleEndian.AppendUint16(values, uint16(value))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeInt32:
+		values := make([]byte, 0, 4*length)
+		for _, value := range column.Int64s {
+			values = binary.LittleEndian.AppendUint32(values, uint32(value))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeInt64:
+		values := make([]byte, 0, 8*length)
+		for _, value := range column.Int64s {
+			values = binary.LittleEndian.AppendUint64(values, uint64(value))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeFixedDecimal:
+		_, bitWidth := decimalPrecision(column.Field)
+		width := bitWidth / 8
+		values := make([]byte, width*length)
+		for index, value := range column.Decimals {
+			if column.IsNull(index) {
+				continue
+			}
+			unscaled, err := value.Unscaled()
+			if err == nil {
+				err = putDecimal(values[index*width:(index+1)*width], unscaled)
+			}
+			if err != nil {
+				return fmt.Errorf(`field '%v': %w`, column.Field.Name, err)
+			}
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeFloat:
+		values := make([]byte, 0, 4*length)
+		for _, value := range column.Float64s {
+			values = binary.LittleEndian.AppendUint32(values, math.Float32bits(float32(value)))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeDouble:
+		values := make([]byte, 0, 8*length)
+		for _, value := range column.Float64s {
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(value))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeDate:
+		values := make([]byte, 0, 4*length)
+		for index, value := range column.Times {
+			days := int64(0)
+			if !column.IsNull(index) {
+				days = floorDiv(value.Unix(), 86400)
+			}
+			values = binary.LittleEndian.AppendUint32(values, uint32(days))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeDateTime:
+		values := make([]byte, 0, 8*length)
+		for index, value := range column.Times {
+			micros := int64(0)
+			if !column.IsNull(index) {
+				micros = value.UnixMicro()
+			}
+			values = binary.LittleEndian.AppendUint64(values, uint64(micros))
+		}
+		b.addBuffer(values)
+	case yxrecord.TypeBlob:
+		return b.addVariableLength(column.Field, column.Blobs)
+	case yxrecord.TypeSpatialObj:
+		values := make([][]byte, len(column.Blobs))
+		for index, value := range column.Blobs {
+			wkb, err := spatial.ToWKB(value)
+			if err != nil {
+				return fmt.Errorf(`field '%v': %w`, column.Field.Name, err)
+			}
+			values[index] = wkb
+		}
+		return b.addVariableLength(column.Field, values)
+	default:
+		values := make([][]byte, len(column.Strings))
+		for index, value := range column.Strings {
+			values[index] = []byte(value)
+		}
+		return b.addVariableLength(column.Field, values)
+	}
+	return nil
+}
+
+// addVariableLength adds the offsets and data buffers of a utf8 or binary column.
+func (b *recordBatchBody) addVariableLength(field yxrecord.YxdbField, values [][]byte) error {
+	offsets := make([]byte, 0, 4*(len(values)+1))
+	offsets = binary.LittleEndian.AppendUint32(offsets, 0)
+	size := 0
+	for _, value := range values {
+		size += len(value)
+		if size > math.MaxInt32 {
+			return fmt.Errorf(`field '%v' holds more than 2 GB in one batch; use a smaller batch size`, field.Name)
+		}
+		offsets = binary.LittleEndian.AppendUint32(offsets, uint32(size))
+	}
+	data := make([]byte, 0, size)
+	for _, value := range values {
+		data = append(data, value...)
+	}
+	b.addBuffer(offsets)
+	b.addBuffer(data)
+	return nil
+}
+
+// validityBitmap converts the null bitmap of a column into an Arrow validity bitmap, in which set bits mark values
+// that are not null. A column without nulls has an empty validity bitmap.
+func validityBitmap(column *yx.Column, length int) ([]byte, int) {
+	if !column.HasNulls() {
+		return nil, 0
+	}
+	validity := make([]byte, (length+7)/8)
+	nullCount := 0
+	for index := 0; index < length; index++ {
+		if column.IsNull(index) {
+			nullCount++
+			continue
+		}
+		validity[index/8] |= 1 << (index % 8)
+	}
+	return validity, nullCount
+}
+
+// putDecimal writes value into dst as a little-endian two's complement integer.
+func putDecimal(dst []byte, value *big.Int) error {
+	bits := uint(len(dst) * 8)
+	if value.BitLen() >= int(bits) {
+		return fmt.Errorf(`decimal %v does not fit in %v bits`, value, bits)
+	}
+	if value.Sign() < 0 {
+		value = new(big.Int).Add(value, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+	value.FillBytes(dst)
+	for i, j := 0, len(dst)-1; i < j; i, j = i+1, j-1 {
+		dst[i], dst[j] = dst[j], dst[i]
+	}
+	return nil
+}
+
+func floorDiv(value int64, divisor int64) int64 {
+	quotient := value / divisor
+	if value%divisor < 0 {
+		quotient--
+	}
+	return quotient
+}
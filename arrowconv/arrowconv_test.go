@@ -0,0 +1,199 @@
+package arrowconv_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/arrowconv"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	headerSchema      = 1
+	headerRecordBatch = 3
+)
+
+func TestConvertStream(t *testing.T) {
+	data := convert(t, `AllNormalFields.yxdb`, arrowconv.Options{})
+	messages := readMessages(t, data)
+	if len(messages) != 2 {
+		t.Fatalf(`expected a schema and 1 record batch but got %v messages`, len(messages))
+	}
+	schema := messages[0]
+	if schema.headerType != headerSchema {
+		t.Fatalf(`expected a schema message but got header type %v`, schema.headerType)
+	}
+	fields := schema.object(t, schema.header, 1)
+	if count := binary.LittleEndian.Uint32(schema.metadata[fields:]); count != 16 {
+		t.Fatalf(`expected 16 fields but got %v`, count)
+	}
+	batch := messages[1]
+	if batch.headerType != headerRecordBatch || batch.int64(t, batch.header, 0) != 1 {
+		t.Fatalf(`expected a record batch of 1 record`)
+	}
+
+	// the data buffer of FixedDecimalField follows the validity and data buffers of the 5 preceding fields
+	buffers := batch.object(t, batch.header, 2)
+	buffer := buffers + 4 + 11*16
+	offset := binary.LittleEndian.Uint64(batch.metadata[buffer:])
+	length := binary.LittleEndian.Uint64(batch.metadata[buffer+8:])
+	if length != 16 {
+		t.Fatalf(`expected a 16 byte decimal but got %v bytes`, length)
+	}
+	value := batch.body[offset : offset+16]
+	if unscaled := new(big.Int).SetBytes(reverse(value)); unscaled.Int64() != 123450000 {
+		t.Fatalf(`expected 123450000 but got %v`, unscaled)
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	data := convert(t, `LotsOfRecords.yxdb`, arrowconv.Options{Format: arrowconv.File, BatchSize: 30000})
+	if !bytes.HasPrefix(data, []byte("ARROW1\x00\x00")) || !bytes.HasSuffix(data, []byte(`ARROW1`)) {
+		t.Fatalf(`expected the file to start and end with ARROW1`)
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+	footerStart := len(data) - 10 - footerLength
+	messages := readMessages(t, data[8:footerStart])
+	var lengths []int64
+	for _, message := range messages[1:] {
+		lengths = append(lengths, message.int64(t, message.header, 0))
+	}
+	if len(lengths) != 4 || lengths[0] != 30000 || lengths[3] != 10000 {
+		t.Fatalf(`expected batches of 30000, 30000, 30000, and 10000 records but got %v`, lengths)
+	}
+
+	footer := message{metadata: data[footerStart : len(data)-10]}
+	root := int(binary.LittleEndian.Uint32(footer.metadata))
+	blocks := footer.object(t, root, 3)
+	if count := binary.LittleEndian.Uint32(footer.metadata[blocks:]); count != 4 {
+		t.Fatalf(`expected 4 blocks but got %v`, count)
+	}
+	for index := 0; index < 4; index++ {
+		offset := binary.LittleEndian.Uint64(footer.metadata[blocks+4+24*index:])
+		if !bytes.Equal(data[offset:offset+4], []byte{0xff, 0xff, 0xff, 0xff}) {
+			t.Fatalf(`expected block %v to point at a message`, index)
+		}
+	}
+}
+
+func TestSpatialObjAsGeoArrow(t *testing.T) {
+	data := convert(t, `point.yxdb`, arrowconv.Options{})
+	if !bytes.Contains(data, []byte(`geoarrow.wkb`)) {
+		t.Fatalf(`expected geoarrow.wkb extension metadata`)
+	}
+}
+
+func TestConvertFileToPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `out.arrow`)
+	err := arrowconv.ConvertFile(`../test_files/AllNormalFields.yxdb`, path, arrowconv.Options{Format: arrowconv.File})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(data, []byte(`ARROW1`)) {
+		t.Fatalf(`expected an arrow file`)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	reader, err := yx.ReadFile(`../test_files/AllNormalFields.yxdb`)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	writer, err := arrowconv.NewWriter(&bytes.Buffer{}, reader.ListFields(), arrowconv.Stream)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	batch, _ := reader.NextBatch(10)
+	_ = writer.Close()
+	if err = writer.Write(batch); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+func convert(t *testing.T, fileName string, options arrowconv.Options) []byte {
+	reader, err := yx.ReadFile(`../test_files/` + fileName)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	var data bytes.Buffer
+	err = arrowconv.Convert(&data, reader, options)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return data.Bytes()
+}
+
+// message is an encapsulated IPC message with enough FlatBuffer decoding to check its contents.
+type message struct {
+	metadata   []byte
+	body       []byte
+	header     int
+	headerType byte
+}
+
+// readMessages splits an IPC stream into messages, checking that it ends with an end-of-stream marker.
+func readMessages(t *testing.T, data []byte) []message {
+	var messages []message
+	for {
+		if len(data) < 8 || binary.LittleEndian.Uint32(data) != 0xffffffff {
+			t.Fatalf(`expected a continuation marker`)
+		}
+		length := int(binary.LittleEndian.Uint32(data[4:]))
+		if length == 0 {
+			if len(data) != 8 {
+				t.Fatalf(`expected the stream to end after the end-of-stream marker`)
+			}
+			return messages
+		}
+		if length%8 != 0 {
+			t.Fatalf(`expected metadata padded to 8 bytes but got %v bytes`, length)
+		}
+		m := message{metadata: data[8 : 8+length]}
+		root := int(binary.LittleEndian.Uint32(m.metadata))
+		m.headerType = m.metadata[m.field(t, root, 1)]
+		m.header = m.object(t, root, 2)
+		bodyLength := int(m.int64(t, root, 3))
+		m.body = data[8+length : 8+length+bodyLength]
+		messages = append(messages, m)
+		data = data[8+length+bodyLength:]
+	}
+}
+
+// field returns the position of field id of the table at position table.
+func (m message) field(t *testing.T, table int, id int) int {
+	vtable := table - int(int32(binary.LittleEndian.Uint32(m.metadata[table:])))
+	vtableSize := int(binary.LittleEndian.Uint16(m.metadata[vtable:]))
+	if 4+2*id >= vtableSize {
+		t.Fatalf(`field %v is missing`, id)
+	}
+	offset := int(binary.LittleEndian.Uint16(m.metadata[vtable+4+2*id:]))
+	if offset == 0 {
+		t.Fatalf(`field %v is missing`, id)
+	}
+	return table + offset
+}
+
+// object returns the position of the table, vector, or string referred to by field id of the table at position
+// table.
+func (m message) object(t *testing.T, table int, id int) int {
+	position := m.field(t, table, id)
+	return position + int(binary.LittleEndian.Uint32(m.metadata[position:]))
+}
+
+func (m message) int64(t *testing.T, table int, id int) int64 {
+	return int64(binary.LittleEndian.Uint64(m.metadata[m.field(t, table, id):]))
+}
+
+func reverse(value []byte) []byte {
+	reversed := make([]byte, len(value))
+	for index, b := range value {
+		reversed[len(value)-1-index] = b
+	}
+	return reversed
+}
@@ -0,0 +1,165 @@
+package arrowconv
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// Arrow IPC metadata is encoded with FlatBuffers. The few tables, vectors, and strings needed to describe a schema
+// and its record batches are built here rather than depending on the FlatBuffers library.
+//
+// Objects are written front to back: a table is written before the objects it refers to, so every offset points
+// forward as FlatBuffers requires, and each table's vtable is written immediately before the table.
+
+type fbObject interface {
+	// write appends the object to b, aligned as needed, and returns its position.
+	write(b *fbBuilder) int
+}
+
+type fbBuilder struct {
+	bytes []byte
+}
+
+// finish serializes root as a FlatBuffer, padded to a multiple of 8 bytes.
+func finish(root fbObject) []byte {
+	b := &fbBuilder{bytes: make([]byte, 4, 512)}
+	position := root.write(b)
+	binary.LittleEndian.PutUint32(b.bytes[0:4], uint32(position))
+	b.align(8)
+	return b.bytes
+}
+
+func (b *fbBuilder) align(size int) {
+	for len(b.bytes)%size != 0 {
+		b.bytes = append(b.bytes, 0)
+	}
+}
+
+// patch points the offset at position to target.
+func (b *fbBuilder) patch(position int, target int) {
+	binary.LittleEndian.PutUint32(b.bytes[position:], uint32(target-position))
+}
+
+// fbField is a field of a table. Fields are either scalars of 1, 2, 4, or 8 bytes or offsets to other objects.
+type fbField struct {
+	present bool
+	size    int
+	value   uint64
+	object  fbObject
+}
+
+func fbScalar(size int, value uint64) fbField {
+	return fbField{present: true, size: size, value: value}
+}
+
+func fbBool(value bool) fbField {
+	if value {
+		return fbScalar(1, 1)
+	}
+	return fbScalar(1, 0)
+}
+
+func fbOffset(object fbObject) fbField {
+	return fbField{present: true, size: 4, object: object}
+}
+
+// fbTable is a table whose fields are indexed by their id in the schema; missing fields take their default value.
+type fbTable []fbField
+
+func (t fbTable) write(b *fbBuilder) int {
+	// lay out the fields from largest to smallest so that each is aligned without padding
+	order := make([]int, 0, len(t))
+	for id, field := range t {
+		if field.present {
+			order = append(order, id)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return t[order[i]].size > t[order[j]].size })
+	tableAlign := 4
+	inlineSize := 4
+	fieldOffsets := make([]int, len(t))
+	for _, id := range order {
+		size := t[id].size
+		if size > tableAlign {
+			tableAlign = size
+		}
+		inlineSize = (inlineSize + size - 1) / size * size
+		fieldOffsets[id] = inlineSize
+		inlineSize += size
+	}
+
+	b.align(2)
+	vtablePosition := len(b.bytes)
+	vtableSize := 4 + 2*len(t)
+	b.bytes = binary.LittleEndian.AppendUint16(b.bytes, uint16(vtableSize))
+	b.bytes = binary.LittleEndian.AppendUint16(b.bytes, uint16(inlineSize))
+	for _, offset := range fieldOffsets {
+		b.bytes = binary.LittleEndian.AppendUint16(b.bytes, uint16(offset))
+	}
+
+	b.align(tableAlign)
+	tablePosition := len(b.bytes)
+	b.bytes = append(b.bytes, make([]byte, inlineSize)...)
+	binary.LittleEndian.PutUint32(b.bytes[tablePosition:], uint32(tablePosition-vtablePosition))
+	for _, id := range order {
+		field := t[id]
+		position := tablePosition + fieldOffsets[id]
+		switch field.size {
+		case 1:
+			b.bytes[position] = byte(field.value)
+		case 2:
+			binary.LittleEndian.PutUint16(b.bytes[position:], uint16(field.value))
+		case 4:
+			binary.LittleEndian.PutUint32(b.bytes[position:], uint32(field.value))
+		case 8:
+			binary.LittleEndian.PutUint64(b.bytes[position:], field.value)
+		}
+	}
+	for _, id := range order {
+		if object := t[id].object; object != nil {
+			b.patch(tablePosition+fieldOffsets[id], object.write(b))
+		}
+	}
+	return tablePosition
+}
+
+type fbString string
+
+func (s fbString) write(b *fbBuilder) int {
+	b.align(4)
+	position := len(b.bytes)
+	b.bytes = binary.LittleEndian.AppendUint32(b.bytes, uint32(len(s)))
+	b.bytes = append(b.bytes, s...)
+	b.bytes = append(b.bytes, 0)
+	return position
+}
+
+// fbVector is a vector of offsets to tables or strings.
+type fbVector []fbObject
+
+func (v fbVector) write(b *fbBuilder) int {
+	b.align(4)
+	position := len(b.bytes)
+	b.bytes = binary.LittleEndian.AppendUint32(b.bytes, uint32(len(v)))
+	b.bytes = append(b.bytes, make([]byte, 4*len(v))...)
+	for index, object := range v {
+		b.patch(position+4+4*index, object.write(b))
+	}
+	return position
+}
+
+// fbStructs is a vector of structs whose fields are all 8 byte aligned, already encoded as little-endian bytes.
+type fbStructs struct {
+	count int
+	bytes []byte
+}
+
+func (s fbStructs) write(b *fbBuilder) int {
+	for len(b.bytes)%8 != 4 {
+		b.bytes = append(b.bytes, 0)
+	}
+	position := len(b.bytes)
+	b.bytes = binary.LittleEndian.AppendUint32(b.bytes, uint32(s.count))
+	b.bytes = append(b.bytes, s.bytes...)
+	return position
+}
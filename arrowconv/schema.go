@@ -0,0 +1,123 @@
+package arrowconv
+
+import (
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+)
+
+// Values of the Type union in Schema.fbs.
+const (
+	typeInt           = 2
+	typeFloatingPoint = 3
+	typeBinary        = 4
+	typeUtf8          = 5
+	typeBool          = 6
+	typeDecimal       = 7
+	typeDate          = 8
+	typeTimestamp     = 10
+)
+
+// Values of the MessageHeader union in Message.fbs.
+const (
+	headerSchema      = 1
+	headerRecordBatch = 3
+)
+
+const (
+	metadataV5          = 4
+	precisionSingle     = 1
+	precisionDouble     = 2
+	dateUnitDay         = 0
+	timeUnitMicrosecond = 2
+	maxDecimal128       = 38
+)
+
+// GeoArrow extension metadata of SpatialObj fields, which are written as WKB. Alteryx spatial objects are always
+// longitude/latitude coordinates on WGS 84.
+const (
+	extensionNameKey     = `ARROW:extension:name`
+	extensionMetadataKey = `ARROW:extension:metadata`
+	geoArrowWKB          = `geoarrow.wkb`
+	geoArrowMetadata     = `{"crs":"OGC:CRS84","crs_type":"authority_code"}`
+)
+
+// decimalPrecision returns the precision and bit width of the Arrow decimal used for a FixedDecimal field. Fields
+// wider than 38 digits use decimal256.
+func decimalPrecision(field yxrecord.YxdbField) (precision int, bitWidth int) {
+	precision = max(field.Size, field.Scale, 1)
+	if precision > maxDecimal128 {
+		return precision, 256
+	}
+	return precision, 128
+}
+
+// arrowType returns the Type union value and table of the Arrow type used for a field.
+func arrowType(field yxrecord.YxdbField) (byte, fbTable, error) {
+	switch field.FieldType {
+	case yxrecord.TypeBool:
+		return typeBool, fbTable{}, nil
+	case yxrecord.TypeByte:
+		return typeInt, intType(8, false), nil
+	case yxrecord.TypeInt16:
+		return typeInt, intType(16, true), nil
+	case yxrecord.TypeInt32:
+		return typeInt, intType(32, true), nil
+	case yxrecord.TypeInt64:
+		return typeInt, intType(64, true), nil
+	case yxrecord.TypeFixedDecimal:
+		precision, bitWidth := decimalPrecision(field)
+		return typeDecimal, fbTable{
+			fbScalar(4, uint64(precision)),
+			fbScalar(4, uint64(field.Scale)),
+			fbScalar(4, uint64(bitWidth)),
+		}, nil
+	case yxrecord.TypeFloat:
+		return typeFloatingPoint, fbTable{fbScalar(2, precisionSingle)}, nil
+	case yxrecord.TypeDouble:
+		return typeFloatingPoint, fbTable{fbScalar(2, precisionDouble)}, nil
+	case yxrecord.TypeString, yxrecord.TypeWString, yxrecord.TypeV_String, yxrecord.TypeV_WString:
+		return typeUtf8, fbTable{}, nil
+	case yxrecord.TypeDate:
+		return typeDate, fbTable{fbScalar(2, dateUnitDay)}, nil
+	case yxrecord.TypeDateTime:
+		return typeTimestamp, fbTable{fbScalar(2, timeUnitMicrosecond)}, nil
+	case yxrecord.TypeBlob, yxrecord.TypeSpatialObj:
+		return typeBinary, fbTable{}, nil
+	}
+	return 0, nil, fmt.Errorf(`field '%v' has unsupported type %v`, field.Name, field.FieldType)
+}
+
+func intType(bitWidth int, signed bool) fbTable {
+	return fbTable{fbScalar(4, uint64(bitWidth)), fbBool(signed)}
+}
+
+// schemaTable builds the Schema table describing fields. Every field is nullable.
+func schemaTable(fields []yxrecord.YxdbField) (fbTable, error) {
+	fieldTables := make(fbVector, len(fields))
+	for index, field := range fields {
+		typeType, typeTable, err := arrowType(field)
+		if err != nil {
+			return nil, err
+		}
+		fieldTable := fbTable{
+			fbOffset(fbString(field.Name)),
+			fbBool(true),
+			fbScalar(1, uint64(typeType)),
+			fbOffset(typeTable),
+			{},
+			fbOffset(fbVector{}),
+		}
+		if field.FieldType == yxrecord.TypeSpatialObj {
+			fieldTable = append(fieldTable, fbOffset(fbVector{
+				keyValue(extensionNameKey, geoArrowWKB),
+				keyValue(extensionMetadataKey, geoArrowMetadata),
+			}))
+		}
+		fieldTables[index] = fieldTable
+	}
+	return fbTable{{}, fbOffset(fieldTables)}, nil
+}
+
+func keyValue(key string, value string) fbTable {
+	return fbTable{fbOffset(fbString(key)), fbOffset(fbString(value))}
+}
@@ -0,0 +1,192 @@
+package yxdb
+
+import (
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"time"
+)
+
+// A Batch holds consecutive records of a .yxdb file in columnar form, produced by Reader.NextBatch.
+type Batch struct {
+	// Len is the number of records in the batch.
+	Len int
+
+	// Columns holds one Column for every field of the Reader, in field order.
+	Columns []Column
+}
+
+// A Column holds the values of one field for every record in a Batch.
+//
+// Only the slice matching the DataType of the field is filled, with one element per record; the others are nil.
+// FixedDecimal fields fill both Float64s and Decimals. Null values are stored as the zero value of the slice type and
+// marked in Nulls.
+type Column struct {
+	Field yxrecord.YxdbField
+
+	// Nulls is a bitmap of the null values in the column: bit i%64 of Nulls[i/64] is set when record i is null.
+	Nulls []uint64
+
+	Bools    []bool
+	Bytes    []byte
+	Int64s   []int64
+	Float64s []float64
+	Decimals []decimal.Decimal
+	Strings  []string
+	Times    []time.Time
+
+	// Blobs holds the values of Blob and SpatialObj fields. The values are copies and remain valid after the batch.
+	Blobs [][]byte
+}
+
+// IsNull reports whether the value of the column for the record at index i of the batch is null.
+func (c *Column) IsNull(i int) bool {
+	return c.Nulls[i/64]&(1<<(i%64)) != 0
+}
+
+// HasNulls reports whether any value of the column is null.
+func (c *Column) HasNulls() bool {
+	for _, word := range c.Nulls {
+		if word != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Column) setNull(i int, isNull bool) {
+	if isNull {
+		c.Nulls[i/64] |= 1 << (i % 64)
+	}
+}
+
+// columnAppender appends the value of one field in buffer to a column.
+type columnAppender func(column *Column, row int, buffer []byte)
+
+func (r *r) NextBatch(n int) (*Batch, error) {
+	if n < 1 {
+		n = 1
+	}
+	if r.appenders == nil {
+		r.appenders = newColumnAppenders(r.record)
+	}
+	// n may be far larger than the file, so the columns start no larger than the records left and grow as needed
+	batch := newBatch(r.record, int(min(int64(n), r.recordReader.RemainingRecords())))
+	for batch.Len < n && r.Next() {
+		if batch.Len%64 == 0 {
+			for index := range batch.Columns {
+				batch.Columns[index].Nulls = append(batch.Columns[index].Nulls, 0)
+			}
+		}
+		buffer := r.recordReader.RecordBuffer
+		for index, appendValue := range r.appenders {
+			appendValue(&batch.Columns[index], batch.Len, buffer)
+		}
+		batch.Len++
+	}
+	if err := r.Err(); err != nil {
+		return batch, err
+	}
+	if batch.Len == 0 {
+		return nil, io.EOF
+	}
+	return batch, nil
+}
+
+// newBatch returns an empty batch whose columns have room for n records.
+func newBatch(record *yxrecord.YxdbRecord, n int) *Batch {
+	batch := &Batch{Columns: make([]Column, len(record.Fields))}
+	for index, field := range record.Fields {
+		column := &batch.Columns[index]
+		column.Field = field
+		column.Nulls = make([]uint64, 0, (n+63)/64)
+		switch field.Type {
+		case yxrecord.Boolean:
+			column.Bools = make([]bool, 0, n)
+		case yxrecord.Byte:
+			column.Bytes = make([]byte, 0, n)
+		case yxrecord.Int64:
+			column.Int64s = make([]int64, 0, n)
+		case yxrecord.Float64:
+			column.Float64s = make([]float64, 0, n)
+			if field.FieldType == yxrecord.TypeFixedDecimal {
+				column.Decimals = make([]decimal.Decimal, 0, n)
+			}
+		case yxrecord.String:
+			column.Strings = make([]string, 0, n)
+		case yxrecord.Date:
+			column.Times = make([]time.Time, 0, n)
+		default:
+			column.Blobs = make([][]byte, 0, n)
+		}
+	}
+	return batch
+}
+
+// newColumnAppenders looks up the extractor of every field once, so that filling a batch does not look up extractors
+// for every record.
+func newColumnAppenders(record *yxrecord.YxdbRecord) []columnAppender {
+	appenders := make([]columnAppender, len(record.Fields))
+	for index, field := range record.Fields {
+		switch field.Type {
+		case yxrecord.Boolean:
+			extractor, _ := record.BoolExtractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value, isNull := extractor(buffer)
+				column.Bools = append(column.Bools, value)
+				column.setNull(row, isNull)
+			}
+		case yxrecord.Byte:
+			extractor, _ := record.ByteExtractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value, isNull := extractor(buffer)
+				column.Bytes = append(column.Bytes, value)
+				column.setNull(row, isNull)
+			}
+		case yxrecord.Int64:
+			extractor, _ := record.Int64Extractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value, isNull := extractor(buffer)
+				column.Int64s = append(column.Int64s, value)
+				column.setNull(row, isNull)
+			}
+		case yxrecord.Float64:
+			extractor, _ := record.Float64Extractor(index)
+			decimalExtractor, isDecimal := record.DecimalExtractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value, isNull := extractor(buffer)
+				column.Float64s = append(column.Float64s, value)
+				column.setNull(row, isNull)
+				if isDecimal {
+					decimalValue, _ := decimalExtractor(buffer)
+					column.Decimals = append(column.Decimals, decimalValue)
+				}
+			}
+		case yxrecord.String:
+			extractor, _ := record.StringExtractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value, isNull := extractor(buffer)
+				column.Strings = append(column.Strings, value)
+				column.setNull(row, isNull)
+			}
+		case yxrecord.Date:
+			extractor, _ := record.TimeExtractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value, isNull := extractor(buffer)
+				column.Times = append(column.Times, value)
+				column.setNull(row, isNull)
+			}
+		default:
+			extractor, _ := record.BlobExtractor(index)
+			appenders[index] = func(column *Column, row int, buffer []byte) {
+				value := extractor(buffer)
+				if value != nil {
+					value = append([]byte{}, value...)
+				}
+				column.Blobs = append(column.Blobs, value)
+				column.setNull(row, value == nil)
+			}
+		}
+	}
+	return appenders
+}
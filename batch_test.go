@@ -0,0 +1,120 @@
+package yxdb_test
+
+import (
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextBatch(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	expected := int64(1)
+	var lengths []int
+	for {
+		batch, err := yxdb.NextBatch(30000)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		lengths = append(lengths, batch.Len)
+		column := batch.Columns[0]
+		if column.Field.Name != `RowCount` || len(column.Int64s) != batch.Len || column.HasNulls() {
+			t.Fatalf(`unexpected column %v with %v values`, column.Field, len(column.Int64s))
+		}
+		for _, value := range column.Int64s {
+			if value != expected {
+				t.Fatalf(`expected %v but got %v`, expected, value)
+			}
+			expected++
+		}
+	}
+	if !reflect.DeepEqual(lengths, []int{30000, 30000, 30000, 10000}) {
+		t.Fatalf(`expected batches of [30000 30000 30000 10000] but got %v`, lengths)
+	}
+}
+
+func TestNextBatchLargerThanFile(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	batch, err := yxdb.NextBatch(1 << 30)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	column := batch.Columns[0]
+	if batch.Len != 99999 || cap(column.Int64s) != 99999 || len(column.Nulls) != (99999+63)/64 {
+		t.Fatalf(`expected 99999 records in columns sized to the file but got %v records with capacity %v and %v null words`, batch.Len, cap(column.Int64s), len(column.Nulls))
+	}
+	if column.Int64s[0] != 2 || column.Int64s[99998] != 100000 {
+		t.Fatalf(`expected records 2 to 100000 but got %v to %v`, column.Int64s[0], column.Int64s[99998])
+	}
+}
+
+func TestNextBatchAllFieldTypes(t *testing.T) {
+	yxdb := openYxdb(t, writeValuesAndNulls(t))
+	defer yxdb.Close()
+
+	batch, err := yxdb.NextBatch(10)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if batch.Len != 2 || len(batch.Columns) != 18 {
+		t.Fatalf(`expected 2 records and 18 columns but got %v and %v`, batch.Len, len(batch.Columns))
+	}
+	for _, column := range batch.Columns {
+		if column.IsNull(0) || !column.IsNull(1) {
+			t.Fatalf(`expected only the second value of %v to be null`, column.Field.Name)
+		}
+	}
+	columns := batch.Columns
+	checkBatchValues(t, columns[0].Bytes, []byte{1, 0})
+	checkBatchValues(t, columns[1].Bools, []bool{true, false})
+	checkBatchValues(t, columns[2].Int64s, []int64{16, 0})
+	checkBatchValues(t, columns[5].Float64s, []float64{123.45, 0})
+	checkBatchValues(t, columns[5].Decimals, []decimal.Decimal{{Text: `123.450000`, Scale: 6}, {}})
+	checkBatchValues(t, columns[7].Float64s, []float64{0.12345, 0})
+	checkBatchValues(t, columns[12].Strings, []string{`XZY`, ``})
+	checkBatchValues(t, columns[14].Times, []time.Time{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), {}})
+	checkBatchValues(t, columns[17].Blobs, [][]byte{[]byte(strings.Repeat(`S`, 100)), nil})
+	if columns[0].Int64s != nil || columns[2].Float64s != nil || columns[7].Decimals != nil {
+		t.Fatalf(`expected only the slices matching each field type to be filled`)
+	}
+
+	_, err = yxdb.NextBatch(10)
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf(`expected io.EOF but got %v`, err)
+	}
+}
+
+func TestNextBatchWithColumnsAndPredicate(t *testing.T) {
+	yxdb, err := yx.ReadFile(writeValuesAndNulls(t), yx.WithColumns(`StringField`), yx.WithPredicate(yx.IsNull(`ByteField`)))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	batch, err := yxdb.NextBatch(10)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if batch.Len != 1 || len(batch.Columns) != 1 || !batch.Columns[0].IsNull(0) {
+		t.Fatalf(`expected a single null StringField value but got %+v`, batch)
+	}
+}
+
+func checkBatchValues[T any](t *testing.T, actual []T, expected []T) {
+	t.Helper()
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, actual)
+	}
+}
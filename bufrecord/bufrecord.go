@@ -2,13 +2,21 @@ package bufrecord
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	l "github.com/tlarsendataguy-yxdb/yxdb-go/lzf"
 	"io"
+	"sync"
 )
 
 const lzfBufferSize = 262144
 
+// ErrTruncated is reported when a .yxdb file ends before all of its records have been read.
+var ErrTruncated = errors.New(`yxdb file is truncated`)
+
+// ErrCorruptBlock is reported when an LZF block in a .yxdb file cannot be read.
+var ErrCorruptBlock = errors.New(`yxdb file contains a corrupt lzf block`)
+
 type BufferedRecordReader struct {
 	RecordBuffer      []byte
 	Err               error
@@ -17,13 +25,25 @@ type BufferedRecordReader struct {
 	stream            io.ReadCloser
 	FixedLen          int
 	HasVarFields      bool
-	lzfIn             []byte
+	blocks            *lzfBlockReader
 	lzfOut            []byte
-	lzf               l.Lzf
-	lzfLengthBuffer   []byte
 	lzfOutIndex       int
 	lzfOutSize        int
 	currentRecord     int64
+	pipelineBuffers   [][]byte
+	pipeline          *pipeline
+}
+
+// lzfBlockReader reads LZF blocks from a stream and decompresses them. position is the offset of the stream and end,
+// if it is not 0, the offset at which the blocks end. mu guards the stream while blocks are read in the background.
+type lzfBlockReader struct {
+	stream          io.Reader
+	lzfIn           []byte
+	lzf             l.Lzf
+	lzfLengthBuffer []byte
+	position        int64
+	end             int64
+	mu              sync.Mutex
 }
 
 func NewBufferedRecordReader(stream io.ReadCloser, fixedLen int, hasVarFields bool, totalRecords int64) *BufferedRecordReader {
@@ -33,9 +53,6 @@ func NewBufferedRecordReader(stream io.ReadCloser, fixedLen int, hasVarFields bo
 	} else {
 		recordBuffer = make([]byte, fixedLen)
 	}
-	lzfIn := make([]byte, lzfBufferSize)
-	lzfOut := make([]byte, lzfBufferSize)
-	lzf := l.Lzf{InBuffer: lzfIn, OutBuffer: lzfOut}
 	reader := &BufferedRecordReader{
 		RecordBuffer:      recordBuffer,
 		recordBufferIndex: 0,
@@ -43,10 +60,8 @@ func NewBufferedRecordReader(stream io.ReadCloser, fixedLen int, hasVarFields bo
 		stream:            stream,
 		FixedLen:          fixedLen,
 		HasVarFields:      hasVarFields,
-		lzfIn:             lzfIn,
-		lzfOut:            lzfOut,
-		lzf:               lzf,
-		lzfLengthBuffer:   make([]byte, 4),
+		blocks:            newLzfBlockReader(stream),
+		lzfOut:            make([]byte, lzfBufferSize),
 		lzfOutIndex:       0,
 		lzfOutSize:        0,
 		currentRecord:     0,
@@ -68,14 +83,80 @@ func (r *BufferedRecordReader) NextRecord() bool {
 		err = r.read(r.FixedLen)
 	}
 	if err != nil {
-		r.Err = err
+		r.Err = fmt.Errorf(`error reading record %v of %v: %w`, r.currentRecord, r.totalRecords, err)
 		return false
 	}
 	return true
 }
 
+// RemainingRecords returns the number of records that NextRecord has not read yet.
+func (r *BufferedRecordReader) RemainingRecords() int64 {
+	return max(r.totalRecords-r.currentRecord, 0)
+}
+
+// SeekBlock moves the stream to the LZF block that starts at position. recordsBefore is the number of records in the
+// file that precede the block; the next call to NextRecord reads the first record in the block.
+//
+// If the stream does not implement io.Seeker, SeekBlock returns an error.
+func (r *BufferedRecordReader) SeekBlock(position int64, recordsBefore int64) error {
+	seeker, ok := r.stream.(io.Seeker)
+	if !ok {
+		return errors.New(`stream does not support seeking`)
+	}
+	_ = r.stopPipeline(nil)
+	_, err := seeker.Seek(position, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	r.blocks.position = position
+	r.currentRecord = recordsBefore
+	r.lzfOutIndex = 0
+	r.lzfOutSize = 0
+	r.Err = nil
+	return nil
+}
+
+// LimitBlocks records that the stream is at position and that the LZF blocks of the records end at end, where the
+// record block index of a .yxdb file begins. Blocks are never read past end, so that the background goroutine started
+// by EnablePipelining stops after the last block instead of reading the index as LZF data.
+func (r *BufferedRecordReader) LimitBlocks(position int64, end int64) {
+	r.blocks.position = position
+	r.blocks.end = end
+}
+
+// ReadAt reads len(buffer) bytes of the stream starting at position, leaving the stream where the next block of
+// records begins. It is safe to call while blocks are read in the background.
+//
+// If the stream implements io.ReaderAt it is used directly. Otherwise the stream must implement io.Seeker; it is
+// moved to position and back while the background goroutine waits.
+func (r *BufferedRecordReader) ReadAt(buffer []byte, position int64) error {
+	if readerAt, ok := r.stream.(io.ReaderAt); ok {
+		_, err := io.ReadFull(io.NewSectionReader(readerAt, position, int64(len(buffer))), buffer)
+		return err
+	}
+	seeker, ok := r.stream.(io.Seeker)
+	if !ok {
+		return errors.New(`stream does not support seeking`)
+	}
+	r.blocks.mu.Lock()
+	defer r.blocks.mu.Unlock()
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = seeker.Seek(position, io.SeekStart)
+	if err == nil {
+		_, err = io.ReadFull(r.stream, buffer)
+	}
+	_, seekErr := seeker.Seek(current, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	return seekErr
+}
+
 func (r *BufferedRecordReader) Close() error {
-	return r.stream.Close()
+	return r.stopPipeline(r.stream.Close)
 }
 
 func (r *BufferedRecordReader) readVariableRecord() error {
@@ -130,29 +211,79 @@ func (r *BufferedRecordReader) copyRemainingLzfOutToRecord() int {
 }
 
 func (r *BufferedRecordReader) readNextLzfBlock() (int, error) {
-	lzfBlockLength, err := r.readLzfBlockLength()
+	if r.pipelineBuffers != nil {
+		return r.receivePipelinedBlock()
+	}
+	return r.blocks.readBlock(r.lzfOut)
+}
+
+func newLzfBlockReader(stream io.Reader) *lzfBlockReader {
+	lzfIn := make([]byte, lzfBufferSize)
+	return &lzfBlockReader{
+		stream:          stream,
+		lzfIn:           lzfIn,
+		lzf:             l.Lzf{InBuffer: lzfIn},
+		lzfLengthBuffer: make([]byte, 4),
+	}
+}
+
+// readBlock reads the next LZF block from the stream, decompressing it into lzfOut if necessary, and returns the
+// number of bytes written to lzfOut.
+func (b *lzfBlockReader) readBlock(lzfOut []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.end > 0 && b.position >= b.end {
+		return 0, ErrTruncated
+	}
+	lzfBlockLength, err := b.readLzfBlockLength()
 	if err != nil {
 		return 0, err
 	}
 	checkbit := lzfBlockLength & 0x80000000
 	if checkbit > 0 {
 		lzfBlockLength &= 0x7fffffff
-		return r.stream.Read(r.lzfOut[0:lzfBlockLength])
+		if lzfBlockLength > len(lzfOut) {
+			return 0, ErrCorruptBlock
+		}
+		return b.readFull(lzfOut[0:lzfBlockLength])
+	}
+	if lzfBlockLength > len(b.lzfIn) {
+		return 0, ErrCorruptBlock
 	}
-	readIn, err := r.stream.Read(r.lzfIn[0:lzfBlockLength])
+	readIn, err := b.readFull(b.lzfIn[0:lzfBlockLength])
 	if err != nil {
 		return readIn, err
 	}
-	return r.lzf.Decompress(readIn), nil
+	b.lzf.OutBuffer = lzfOut
+	return b.decompress(readIn)
 }
 
-func (r *BufferedRecordReader) readLzfBlockLength() (int, error) {
-	read, err := r.stream.Read(r.lzfLengthBuffer)
-	if read < 4 {
-		return read, fmt.Errorf("yxdb file is not valid")
+func (b *lzfBlockReader) readLzfBlockLength() (int, error) {
+	_, err := b.readFull(b.lzfLengthBuffer)
+	if err != nil {
+		return 0, err
 	}
-	blockLength := int(binary.LittleEndian.Uint32(r.lzfLengthBuffer))
-	return blockLength, err
+	blockLength := int(binary.LittleEndian.Uint32(b.lzfLengthBuffer))
+	return blockLength, nil
+}
+
+func (b *lzfBlockReader) readFull(buffer []byte) (int, error) {
+	read, err := io.ReadFull(b.stream, buffer)
+	b.position += int64(read)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return read, ErrTruncated
+	}
+	return read, err
+}
+
+// decompress converts the panic raised by Lzf when a block is malformed into ErrCorruptBlock.
+func (b *lzfBlockReader) decompress(length int) (size int, err error) {
+	defer func() {
+		if recover() != nil {
+			size, err = 0, ErrCorruptBlock
+		}
+	}()
+	return b.lzf.Decompress(length), nil
 }
 
 func min(a int, b int) int {
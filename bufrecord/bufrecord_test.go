@@ -1,9 +1,13 @@
 package bufrecord_test
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	r "github.com/tlarsendataguy-yxdb/yxdb-go/bufrecord"
+	"io"
+	"math/rand"
 	"os"
 	"testing"
 )
@@ -49,6 +53,156 @@ func TestVeryLongField(t *testing.T) {
 	_ = reader.Close()
 }
 
+func TestPipelinedLotsOfRecords(t *testing.T) {
+	reader := generateReader(getPath(`LotsOfRecords.yxdb`), 5, false)
+	reader.EnablePipelining(3)
+	defer reader.Close()
+
+	recordsRead := 0
+	for reader.NextRecord() {
+		recordsRead++
+		value := int(binary.LittleEndian.Uint32(reader.RecordBuffer[0:4]))
+		if value != recordsRead {
+			t.Fatalf(`expected %v but got %v`, recordsRead, value)
+		}
+	}
+	if reader.Err != nil {
+		t.Fatalf(`expected no error but got: %v`, reader.Err.Error())
+	}
+	if recordsRead != 100000 {
+		t.Fatalf(`expected 100000 records but got %v`, recordsRead)
+	}
+}
+
+func TestPipelinedVeryLongField(t *testing.T) {
+	reader := generateReader(getPath(`VeryLongField.yxdb`), 6, true)
+	reader.EnablePipelining(2)
+	defer reader.Close()
+
+	var recordsRead byte = 0
+	for reader.NextRecord() {
+		recordsRead++
+		if value := reader.RecordBuffer[0]; value != recordsRead {
+			t.Fatalf(`expected %v but got %v`, recordsRead, value)
+		}
+	}
+	if recordsRead != 3 {
+		t.Fatalf(`expected 3 records but got %v`, recordsRead)
+	}
+}
+
+func TestPipelinedTruncatedStream(t *testing.T) {
+	raw, _ := os.ReadFile(getPath(`LotsOfRecords.yxdb`))
+	stream := bytes.NewReader(raw[0:300000])
+	_, _ = stream.Seek(int64(512+binary.LittleEndian.Uint32(raw[80:84])*2), io.SeekStart)
+	reader := r.NewBufferedRecordReader(io.NopCloser(stream), 5, false, 100000)
+	reader.EnablePipelining(4)
+
+	for reader.NextRecord() {
+	}
+	if !errors.Is(reader.Err, r.ErrTruncated) {
+		t.Fatalf(`expected ErrTruncated but got %v`, reader.Err)
+	}
+	if reader.NextRecord() {
+		t.Fatalf(`expected no more records after an error`)
+	}
+	_ = reader.Close()
+}
+
+func TestPipelinedSeekBlock(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writer := r.NewBufferedRecordWriter(stream, 0)
+	record := make([]byte, 5)
+	for i := 1; i <= 200000; i++ {
+		binary.LittleEndian.PutUint32(record, uint32(i))
+		_ = writer.WriteRecord(record)
+	}
+	_ = writer.Flush()
+
+	reader := r.NewBufferedRecordReader(nopSeekCloser{bytes.NewReader(stream.Bytes())}, 5, false, 200000)
+	reader.EnablePipelining(2)
+	reader.NextRecord()
+	err := reader.SeekBlock(writer.RecordBlockIndex[2], 2*r.RecordsPerBlock)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if !reader.NextRecord() {
+		t.Fatalf(`expected a record but got error %v`, reader.Err)
+	}
+	if value := binary.LittleEndian.Uint32(reader.RecordBuffer); value != 2*r.RecordsPerBlock+1 {
+		t.Fatalf(`expected %v but got %v`, 2*r.RecordsPerBlock+1, value)
+	}
+	_ = reader.Close()
+}
+
+func TestPipelineStopsAtLimit(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writer := r.NewBufferedRecordWriter(stream, 0)
+	record := make([]byte, 5)
+	for i := 1; i <= 200000; i++ {
+		_ = writer.WriteRecord(record)
+	}
+	_ = writer.Flush()
+	end := int64(stream.Len())
+	stream.Write([]byte{0xff, 0xff, 0xff, 0xff})
+
+	source := bytes.NewReader(stream.Bytes())
+	reader := r.NewBufferedRecordReader(io.NopCloser(source), 5, false, 200000)
+	reader.LimitBlocks(0, end)
+	reader.EnablePipelining(4)
+	for reader.NextRecord() {
+	}
+	_ = reader.Close()
+	if reader.Err != nil {
+		t.Fatalf(`expected no error but got: %v`, reader.Err.Error())
+	}
+	if source.Len() != 4 {
+		t.Fatalf(`expected the 4 bytes after the limit to be unread but %v remain`, source.Len())
+	}
+}
+
+func TestPipelinedReadAt(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writer := r.NewBufferedRecordWriter(stream, 0)
+	record := make([]byte, 5)
+	for i := 1; i <= 200000; i++ {
+		binary.LittleEndian.PutUint32(record, uint32(i))
+		_ = writer.WriteRecord(record)
+	}
+	_ = writer.Flush()
+
+	reader := r.NewBufferedRecordReader(nopSeekCloser{bytes.NewReader(stream.Bytes())}, 5, false, 200000)
+	reader.EnablePipelining(2)
+	reader.NextRecord()
+	buffer := make([]byte, 4)
+	err := reader.ReadAt(buffer, writer.RecordBlockIndex[1])
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if err = reader.ReadAt(buffer, int64(stream.Len())); err == nil {
+		t.Fatalf(`expected an error reading past the end of the stream`)
+	}
+	read := 1
+	for reader.NextRecord() {
+		read++
+		if value := binary.LittleEndian.Uint32(reader.RecordBuffer); value != uint32(read) {
+			t.Fatalf(`expected %v but got %v`, read, value)
+		}
+	}
+	if read != 200000 {
+		t.Fatalf(`expected 200000 records but got %v: %v`, read, reader.Err)
+	}
+	_ = reader.Close()
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
 func getPath(fileName string) string {
 	return fmt.Sprintf(`../test_files/%v`, fileName)
 }
@@ -77,3 +231,69 @@ func generateReader(path string, fixedLen int, hasVarFields bool) *r.BufferedRec
 	}
 	return r.NewBufferedRecordReader(stream, fixedLen, hasVarFields, totalRecords)
 }
+
+func TestWriteRecordsAcrossBlocks(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writer := r.NewBufferedRecordWriter(stream, 100)
+	record := make([]byte, 5)
+	for i := 1; i <= 100000; i++ {
+		binary.LittleEndian.PutUint32(record, uint32(i))
+		err := writer.WriteRecord(record)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+	}
+	_ = writer.Flush()
+	if writer.TotalRecords != 100000 {
+		t.Fatalf(`expected 100000 records but got %v`, writer.TotalRecords)
+	}
+	if blocks := len(writer.RecordBlockIndex); blocks != 2 || writer.RecordBlockIndex[0] != 100 {
+		t.Fatalf(`expected 2 blocks starting at 100 but got %v`, writer.RecordBlockIndex)
+	}
+	if position := writer.Position(); position != int64(100+stream.Len()) {
+		t.Fatalf(`expected position %v but got %v`, 100+stream.Len(), position)
+	}
+
+	reader := r.NewBufferedRecordReader(io.NopCloser(stream), 5, false, 100000)
+	recordsRead := 0
+	for reader.NextRecord() {
+		recordsRead++
+		value := int(binary.LittleEndian.Uint32(reader.RecordBuffer[0:4]))
+		if value != recordsRead {
+			t.Fatalf(`expected %v but got %v`, recordsRead, value)
+		}
+	}
+	if recordsRead != 100000 {
+		t.Fatalf(`expected 100000 records but got %v`, recordsRead)
+	}
+}
+
+func TestWriteIncompressibleRecords(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writer := r.NewBufferedRecordWriter(stream, 0)
+	record := make([]byte, 1000)
+	rand.New(rand.NewSource(1)).Read(record)
+	_ = writer.WriteRecord(record)
+	_ = writer.Flush()
+
+	blockLength := binary.LittleEndian.Uint32(stream.Bytes()[0:4])
+	if blockLength != 0x80000000|1000 {
+		t.Fatalf(`expected an uncompressed block of 1000 bytes but got %x`, blockLength)
+	}
+	reader := r.NewBufferedRecordReader(io.NopCloser(stream), 1000, false, 1)
+	if !reader.NextRecord() || !bytes.Equal(reader.RecordBuffer, record) {
+		t.Fatalf(`expected to read back the original record`)
+	}
+}
+
+func TestWriteCompressibleRecords(t *testing.T) {
+	stream := &bytes.Buffer{}
+	writer := r.NewBufferedRecordWriter(stream, 0)
+	_ = writer.WriteRecord(bytes.Repeat([]byte{1, 2, 3, 4}, 250))
+	_ = writer.Flush()
+
+	blockLength := binary.LittleEndian.Uint32(stream.Bytes()[0:4])
+	if blockLength&0x80000000 != 0 || blockLength >= 1000 {
+		t.Fatalf(`expected a compressed block but got %x`, blockLength)
+	}
+}
@@ -0,0 +1,113 @@
+package bufrecord
+
+const minPipelineBuffers = 2
+
+// pipeline reads and decompresses LZF blocks in a background goroutine. Decompressed blocks are passed to the
+// consumer through blocks; the consumer returns each buffer through free once it has copied the records out of it.
+type pipeline struct {
+	blocks  chan lzfBlock
+	free    chan []byte
+	done    chan struct{}
+	stopped chan struct{}
+	holding bool
+	err     error
+}
+
+type lzfBlock struct {
+	buffer []byte
+	size   int
+	err    error
+}
+
+// EnablePipelining makes the reader read and decompress upcoming LZF blocks in a background goroutine while the
+// caller reads records. buffers is the number of decompressed blocks held in memory at once, including the block
+// being read; values below 2 are raised to 2.
+//
+// EnablePipelining must be called before the first call to NextRecord. The background goroutine is started by the
+// first call to NextRecord and stopped by Close and SeekBlock. Errors encountered by the background goroutine are
+// reported by NextRecord when the reader reaches the block that failed.
+func (r *BufferedRecordReader) EnablePipelining(buffers int) {
+	if buffers < minPipelineBuffers {
+		buffers = minPipelineBuffers
+	}
+	r.pipelineBuffers = make([][]byte, buffers)
+	for index := range r.pipelineBuffers {
+		r.pipelineBuffers[index] = make([]byte, lzfBufferSize)
+	}
+}
+
+func (r *BufferedRecordReader) receivePipelinedBlock() (int, error) {
+	if r.pipeline == nil {
+		r.pipeline = startPipeline(r.blocks, r.pipelineBuffers)
+	}
+	p := r.pipeline
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.holding {
+		p.free <- r.lzfOut
+		p.holding = false
+	}
+	block := <-p.blocks
+	if block.err != nil {
+		p.err = block.err
+		return 0, block.err
+	}
+	r.lzfOut = block.buffer
+	p.holding = true
+	return block.size, nil
+}
+
+// stopPipeline stops the background goroutine and waits for it to exit. If closeStream is not nil, it is called
+// before waiting so that a goroutine blocked reading the stream is released.
+func (r *BufferedRecordReader) stopPipeline(closeStream func() error) error {
+	var err error
+	if r.pipeline == nil {
+		if closeStream != nil {
+			err = closeStream()
+		}
+		return err
+	}
+	close(r.pipeline.done)
+	if closeStream != nil {
+		err = closeStream()
+	}
+	<-r.pipeline.stopped
+	r.pipeline = nil
+	return err
+}
+
+func startPipeline(reader *lzfBlockReader, buffers [][]byte) *pipeline {
+	p := &pipeline{
+		blocks:  make(chan lzfBlock, len(buffers)),
+		free:    make(chan []byte, len(buffers)),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	for _, buffer := range buffers {
+		p.free <- buffer
+	}
+	go p.run(reader)
+	return p
+}
+
+func (p *pipeline) run(reader *lzfBlockReader) {
+	defer close(p.stopped)
+	for {
+		var buffer []byte
+		select {
+		case buffer = <-p.free:
+		case <-p.done:
+			return
+		}
+		size, err := reader.readBlock(buffer)
+		select {
+		case p.blocks <- lzfBlock{buffer: buffer, size: size, err: err}:
+		case <-p.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package bufrecord
+
+import (
+	"encoding/binary"
+	l "github.com/tlarsendataguy-yxdb/yxdb-go/lzf"
+	"io"
+)
+
+// RecordsPerBlock is the number of records Alteryx stores in each block referenced by the record block index.
+const RecordsPerBlock = 0x10000
+
+// BufferedRecordWriter packs encoded records into LZF blocks and writes them to a stream.
+//
+// Records are grouped into blocks of RecordsPerBlock records. The stream position at which each block starts is
+// collected in RecordBlockIndex so it can be written after the records. LZF blocks that do not shrink when
+// compressed are stored uncompressed.
+type BufferedRecordWriter struct {
+	RecordBlockIndex []int64
+	TotalRecords     int64
+	stream           io.Writer
+	position         int64
+	lzfIn            []byte
+	lzfOut           []byte
+	lzf              l.Lzf
+	lzfInSize        int
+	lzfLengthBuffer  []byte
+}
+
+func NewBufferedRecordWriter(stream io.Writer, startPosition int64) *BufferedRecordWriter {
+	lzfIn := make([]byte, lzfBufferSize)
+	lzfOut := make([]byte, lzfBufferSize)
+	return &BufferedRecordWriter{
+		RecordBlockIndex: make([]int64, 0),
+		TotalRecords:     0,
+		stream:           stream,
+		position:         startPosition,
+		lzfIn:            lzfIn,
+		lzfOut:           lzfOut,
+		lzf:              l.Lzf{InBuffer: lzfIn, OutBuffer: lzfOut},
+		lzfInSize:        0,
+		lzfLengthBuffer:  make([]byte, 4),
+	}
+}
+
+// WriteRecord appends an encoded record to the current block.
+func (w *BufferedRecordWriter) WriteRecord(record []byte) error {
+	if w.TotalRecords%RecordsPerBlock == 0 {
+		err := w.Flush()
+		if err != nil {
+			return err
+		}
+		w.RecordBlockIndex = append(w.RecordBlockIndex, w.position)
+	}
+	for len(record) > 0 {
+		copied := copy(w.lzfIn[w.lzfInSize:], record)
+		w.lzfInSize += copied
+		record = record[copied:]
+		if w.lzfInSize == len(w.lzfIn) {
+			err := w.Flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.TotalRecords++
+	return nil
+}
+
+// Flush writes any buffered record bytes to the stream as a single LZF block.
+func (w *BufferedRecordWriter) Flush() error {
+	if w.lzfInSize == 0 {
+		return nil
+	}
+	var err error
+	compressedSize := w.lzf.Compress(w.lzfInSize)
+	if compressedSize == 0 || compressedSize >= w.lzfInSize {
+		err = w.writeLzfBlock(w.lzfIn[0:w.lzfInSize], true)
+	} else {
+		err = w.writeLzfBlock(w.lzfOut[0:compressedSize], false)
+	}
+	w.lzfInSize = 0
+	return err
+}
+
+// Position returns the stream position immediately after the last byte written.
+func (w *BufferedRecordWriter) Position() int64 {
+	return w.position
+}
+
+func (w *BufferedRecordWriter) writeLzfBlock(block []byte, isUncompressed bool) error {
+	blockLength := uint32(len(block))
+	if isUncompressed {
+		blockLength |= 0x80000000
+	}
+	binary.LittleEndian.PutUint32(w.lzfLengthBuffer, blockLength)
+	err := w.write(w.lzfLengthBuffer)
+	if err != nil {
+		return err
+	}
+	return w.write(block)
+}
+
+func (w *BufferedRecordWriter) write(data []byte) error {
+	written, err := w.stream.Write(data)
+	w.position += int64(written)
+	return err
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/arrowconv"
+)
+
+func runArrow(args []string) error {
+	flags, columns := newFlagSet(`arrow`)
+	stream := flags.Bool(`stream`, false, `write the Arrow IPC stream format instead of the file format`)
+	batchSize := flags.Int(`batch-size`, arrowconv.DefaultBatchSize, `maximum number of records in each record batch`)
+	input, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	reader, err := openYxdb(input, *columns)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	options := arrowconv.Options{Format: arrowconv.File, BatchSize: *batchSize}
+	if *stream {
+		options.Format = arrowconv.Stream
+	}
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(out)
+	err = arrowconv.Convert(buffered, reader, options)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/csvconv"
+	"unicode/utf8"
+)
+
+var blobFormats = map[string]csvconv.BlobFormat{
+	`base64`:  csvconv.Base64,
+	`hex`:     csvconv.Hex,
+	`geojson`: csvconv.GeoJSON,
+	`omit`:    csvconv.Omit,
+}
+
+func runCsv(args []string) error {
+	flags, columns := newFlagSet(`csv`)
+	delimiter := flags.String(`delimiter`, `,`, `character separating values; \t for a tab`)
+	tsv := flags.Bool(`tsv`, false, `separate values with tabs, the same as -delimiter '\t'`)
+	null := flags.String(`null`, ``, `text written for null values`)
+	dateFormat := flags.String(`date-format`, csvconv.DefaultDateFormat, `Go time layout of Date values`)
+	dateTimeFormat := flags.String(`datetime-format`, csvconv.DefaultDateTimeFormat, `Go time layout of DateTime values`)
+	floatFormat := flags.String(`float-format`, ``, `fmt verb for Float and Double values, such as %.2f; shortest round-trip representation if empty`)
+	noHeader := flags.Bool(`no-header`, false, `leave out the header row`)
+	crlf := flags.Bool(`crlf`, false, `end lines with \r\n`)
+	blobs := flags.String(`blobs`, `base64`, `format of Blob and SpatialObj values: base64, hex, geojson or omit`)
+	input, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	options := csvconv.Options{
+		Null:           *null,
+		DateFormat:     *dateFormat,
+		DateTimeFormat: *dateTimeFormat,
+		FloatFormat:    *floatFormat,
+		NoHeader:       *noHeader,
+		UseCRLF:        *crlf,
+	}
+	options.Delimiter, err = parseDelimiter(*delimiter)
+	if err != nil {
+		return err
+	}
+	if *tsv {
+		options.Delimiter = '\t'
+	}
+	var ok bool
+	options.Blobs, ok = blobFormats[*blobs]
+	if !ok {
+		return fmt.Errorf(`unknown blob format '%v'`, *blobs)
+	}
+
+	reader, err := openYxdb(input, *columns)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(out)
+	err = csvconv.Convert(buffered, reader, options)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// parseDelimiter returns the single character of value, accepting \t for a tab since tabs are awkward to pass in a
+// shell.
+func parseDelimiter(value string) (rune, error) {
+	if value == `\t` {
+		return '\t', nil
+	}
+	delimiter, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) {
+		return 0, fmt.Errorf(`the delimiter must be a single character but got '%v'`, value)
+	}
+	return delimiter, nil
+}
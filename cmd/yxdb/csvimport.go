@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/csvconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func runCsvImport(args []string) error {
+	flags := newCommandFlagSet(`csv-import`)
+	delimiter := flags.String(`delimiter`, `,`, `character separating values; \t for a tab`)
+	tsv := flags.Bool(`tsv`, false, `separate values with tabs, the same as -delimiter '\t'`)
+	null := flags.String(`null`, ``, `text of null values`)
+	dateFormat := flags.String(`date-format`, csvconv.DefaultDateFormat, `Go time layout of Date values`)
+	dateTimeFormat := flags.String(`datetime-format`, csvconv.DefaultDateTimeFormat, `Go time layout of DateTime values`)
+	noHeader := flags.Bool(`no-header`, false, `read the first line as data and name the fields Field_1, Field_2, ...`)
+	sampleSize := flags.Int(`sample-size`, csvconv.DefaultSampleSize, `number of records used to infer the field types`)
+	fields := flags.String(`fields`, ``, `comma-separated list of name:type[:size[:scale]] replacing inferred fields, such as Zip:String:10`)
+	input, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	options := csvconv.ImportOptions{
+		Null:           *null,
+		DateFormat:     *dateFormat,
+		DateTimeFormat: *dateTimeFormat,
+		NoHeader:       *noHeader,
+		SampleSize:     *sampleSize,
+	}
+	options.Delimiter, err = parseDelimiter(*delimiter)
+	if err != nil {
+		return err
+	}
+	if *tsv {
+		options.Delimiter = '\t'
+	}
+	options.Fields, err = parseFields(*fields)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if input != `-` {
+		file, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	result, err := csvconv.Import(in, out, options)
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	for _, rowErr := range result.Errors {
+		fmt.Fprintln(os.Stderr, rowErr.Error())
+	}
+	fmt.Fprintf(os.Stderr, "imported %v records with %v errors\n", result.Records, len(result.Errors))
+	return closeErr
+}
+
+// parseFields parses the -fields flag of csv-import.
+func parseFields(value string) ([]metafield.MetaInfoField, error) {
+	if value == `` {
+		return nil, nil
+	}
+	var fields []metafield.MetaInfoField
+	for _, spec := range strings.Split(value, `,`) {
+		parts := strings.Split(strings.TrimSpace(spec), `:`)
+		if len(parts) < 2 || len(parts) > 4 {
+			return nil, fmt.Errorf(`expected name:type[:size[:scale]] but got '%v'`, spec)
+		}
+		field := metafield.MetaInfoField{Name: parts[0], Type: parts[1]}
+		var err error
+		if len(parts) > 2 {
+			field.Size, err = strconv.Atoi(parts[2])
+			if err != nil {
+				return nil, fmt.Errorf(`invalid size in '%v': %w`, spec, err)
+			}
+		}
+		if len(parts) > 3 {
+			field.Scale, err = strconv.Atoi(parts[3])
+			if err != nil {
+				return nil, fmt.Errorf(`invalid scale in '%v': %w`, spec, err)
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
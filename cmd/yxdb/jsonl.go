@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/jsonlconv"
+	"io"
+	"os"
+)
+
+func runJsonl(args []string) error {
+	flags, columns := newFlagSet(`jsonl`)
+	input, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	reader, err := openYxdb(input, *columns)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(out)
+	err = jsonlconv.Convert(buffered, reader)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func runJsonlImport(args []string) error {
+	flags := newCommandFlagSet(`jsonl-import`)
+	sampleSize := flags.Int(`sample-size`, jsonlconv.DefaultSampleSize, `number of lines used to infer the fields`)
+	schema := flags.String(`schema`, ``, `comma-separated list of name:type[:size[:scale]] to write instead of inferring the fields`)
+	input, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	options := jsonlconv.ImportOptions{SampleSize: *sampleSize}
+	options.Fields, err = parseFields(*schema)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if input != `-` {
+		file, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	result, err := jsonlconv.Import(in, out, options)
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	for _, rowErr := range result.Errors {
+		fmt.Fprintln(os.Stderr, rowErr.Error())
+	}
+	fmt.Fprintf(os.Stderr, "imported %v records with %v errors\n", result.Records, len(result.Errors))
+	return closeErr
+}
@@ -0,0 +1,132 @@
+// Command yxdb converts .yxdb files to and from other formats.
+//
+// Usage:
+//
+//	yxdb <command> [flags] <input> <output>
+//
+// The commands are:
+//
+//	arrow         convert a .yxdb file to an Arrow IPC file or stream
+//	parquet       convert a .yxdb file to a Parquet file
+//	csv           convert a .yxdb file to CSV or TSV
+//	csv-import    convert a CSV or TSV file to a .yxdb file, inferring the field types
+//	jsonl         convert a .yxdb file to JSON Lines
+//	jsonl-import  convert a JSON Lines file to a .yxdb file
+//	query         run a SQL query over .yxdb files and write the result in any of the formats above
+//
+// Run yxdb <command> -h for the flags of a command. An output of "-" writes to standard output, except for .yxdb
+// files, which must be written to a file. An input of "-" reads text formats from standard input.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"io"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{name: `arrow`, summary: `convert a .yxdb file to an Arrow IPC file or stream`, run: runArrow},
+	{name: `parquet`, summary: `convert a .yxdb file to a Parquet file`, run: runParquet},
+	{name: `csv`, summary: `convert a .yxdb file to CSV or TSV`, run: runCsv},
+	{name: `csv-import`, summary: `convert a CSV or TSV file to a .yxdb file, inferring the field types`, run: runCsvImport},
+	{name: `jsonl`, summary: `convert a .yxdb file to JSON Lines`, run: runJsonl},
+	{name: `jsonl-import`, summary: `convert a JSON Lines file to a .yxdb file`, run: runJsonlImport},
+	{name: `query`, summary: `run a SQL query over .yxdb files and write the result in any of the formats above`, run: runQuery},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		err := cmd.run(os.Args[2:])
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "yxdb %v: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: yxdb <command> [flags] <input> <output>\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-13v %v\n", cmd.name, cmd.summary)
+	}
+}
+
+// newFlagSet creates the flag set of a command, with the -columns flag shared by every command that reads a .yxdb
+// file.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	flags := newCommandFlagSet(name)
+	columns := flags.String(`columns`, ``, `comma-separated list of fields to convert; all fields if empty`)
+	return flags, columns
+}
+
+func newCommandFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: yxdb %v [flags] <input> <output>\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseArgs parses the flags of a command and returns its input and output paths.
+func parseArgs(flags *flag.FlagSet, args []string) (string, string, error) {
+	err := flags.Parse(args)
+	if err != nil {
+		return ``, ``, err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return ``, ``, errors.New(`expected an input and an output`)
+	}
+	return flags.Arg(0), flags.Arg(1), nil
+}
+
+func openYxdb(path string, columns string) (yx.Reader, error) {
+	var opts []yx.Option
+	if columns != `` {
+		names := strings.Split(columns, `,`)
+		for index, name := range names {
+			names[index] = strings.TrimSpace(name)
+		}
+		opts = append(opts, yx.WithColumns(names...))
+	}
+	return yx.ReadFile(path, opts...)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == `-` {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
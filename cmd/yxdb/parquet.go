@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/parquetconv"
+)
+
+var compressions = map[string]parquetconv.Compression{
+	`snappy`: parquetconv.Snappy,
+	`gzip`:   parquetconv.Gzip,
+	`none`:   parquetconv.Uncompressed,
+}
+
+func runParquet(args []string) error {
+	flags, columns := newFlagSet(`parquet`)
+	compression := flags.String(`compression`, `snappy`, `page compression: snappy, gzip or none`)
+	rowGroupSize := flags.Int(`row-group-size`, parquetconv.DefaultRowGroupSize, `maximum number of records in each row group`)
+	pageSize := flags.Int(`page-size`, parquetconv.DefaultPageSize, `maximum number of records in each data page`)
+	input, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	codec, ok := compressions[*compression]
+	if !ok {
+		return fmt.Errorf(`unknown compression '%v'`, *compression)
+	}
+
+	reader, err := openYxdb(input, *columns)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	options := parquetconv.Options{RowGroupSize: *rowGroupSize, PageSize: *pageSize, Compression: codec}
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(out)
+	err = parquetconv.Convert(buffered, reader, options)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/arrowconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/csvconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/jsonlconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/parquetconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxquery"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// queryFormats maps the extensions of output files to the formats they are written in when -format is not set.
+var queryFormats = map[string]string{
+	`.yxdb`:    `yxdb`,
+	`.csv`:     `csv`,
+	`.tsv`:     `tsv`,
+	`.jsonl`:   `jsonl`,
+	`.arrow`:   `arrow`,
+	`.parquet`: `parquet`,
+}
+
+func runQuery(args []string) error {
+	flags := newCommandFlagSet(`query`)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: yxdb query [flags] <query> <output>\n")
+		flags.PrintDefaults()
+	}
+	format := flags.String(`format`, ``, `output format: yxdb, csv, tsv, jsonl, arrow or parquet; from the extension of the output if empty, or csv`)
+	var opts []yxquery.Option
+	flags.Func(`table`, `name=path of a .yxdb file that the query can refer to by name; may be repeated`, func(value string) error {
+		name, path, ok := strings.Cut(value, `=`)
+		if !ok {
+			return errors.New(`expected name=path`)
+		}
+		opts = append(opts, yxquery.WithTable(name, path))
+		return nil
+	})
+	tempDir := flags.String(`temp-dir`, ``, `directory for the temporary files of ORDER BY; the system temporary directory if empty`)
+	sortMemory := flags.Int64(`sort-memory`, yxquery.DefaultSortMemory, `bytes of rows sorted in memory before spilling to disk`)
+	query, output, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if *format == `` {
+		*format = queryFormats[strings.ToLower(filepath.Ext(output))]
+		if *format == `` {
+			*format = `csv`
+		}
+	}
+	opts = append(opts, yxquery.WithTempDir(*tempDir), yxquery.WithSortMemory(*sortMemory))
+
+	rows, err := yxquery.Query(query, opts...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if *format == `yxdb` {
+		if output == `-` {
+			return errors.New(`.yxdb output must be written to a file`)
+		}
+		return rows.WriteFile(output)
+	}
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriter(out)
+	err = copyRows(rows, buffered, *format)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	closeErr := out.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func copyRows(rows *yxquery.Rows, w io.Writer, format string) error {
+	var writer yxquery.BatchWriter
+	var err error
+	switch format {
+	case `csv`:
+		writer, err = csvconv.NewWriter(w, rows.ListFields(), csvconv.Options{})
+	case `tsv`:
+		writer, err = csvconv.NewWriter(w, rows.ListFields(), csvconv.Options{Delimiter: '\t'})
+	case `jsonl`:
+		writer, err = jsonlconv.NewWriter(w, rows.ListFields())
+	case `arrow`:
+		writer, err = arrowconv.NewWriter(w, rows.ListFields(), arrowconv.File)
+	case `parquet`:
+		writer, err = parquetconv.NewWriter(w, rows.ListFields(), parquetconv.Options{})
+	default:
+		return fmt.Errorf(`unknown format '%v'`, format)
+	}
+	if err != nil {
+		return err
+	}
+	return rows.Copy(writer)
+}
@@ -0,0 +1,270 @@
+// Package csvconv converts .yxdb files to delimited text such as CSV and TSV.
+//
+// Output follows RFC 4180: values containing the delimiter, quotes, or line breaks are quoted, and quotes are
+// doubled. Values are formatted by field type:
+//
+//	Bool                                  true or false
+//	Byte, Int16, Int32, Int64             decimal integers
+//	FixedDecimal                          the exact decimal with the scale of the field
+//	Float, Double                         Options.FloatFormat, or the shortest representation that round-trips
+//	String, WString, V_String, V_WString  the text as is
+//	Date, DateTime                        Options.DateFormat and Options.DateTimeFormat
+//	Blob, SpatialObj                      Options.Blobs
+//
+// Null values of every type are written as Options.Null.
+package csvconv
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/convert"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"strconv"
+)
+
+// BlobFormat is how Blob and SpatialObj values are written.
+type BlobFormat int
+
+const (
+	// Base64 writes blobs with standard base64 encoding.
+	Base64 BlobFormat = iota
+
+	// Hex writes blobs as lowercase hexadecimal.
+	Hex
+
+	// GeoJSON writes SpatialObj values as GeoJSON geometries with spatial.ToGeoJSON. Blob fields are written as
+	// base64.
+	GeoJSON
+
+	// Omit leaves Blob and SpatialObj fields out of the output entirely, including the header.
+	Omit
+)
+
+const (
+	// DefaultDateFormat is the layout of Date values when Options.DateFormat is not set.
+	DefaultDateFormat = `2006-01-02`
+
+	// DefaultDateTimeFormat is the layout of DateTime values when Options.DateTimeFormat is not set. It matches the
+	// format Alteryx uses for date times.
+	DefaultDateTimeFormat = `2006-01-02 15:04:05`
+)
+
+// batchSize is the number of records read at a time by Convert.
+const batchSize = 4096
+
+// Options configures Convert and ConvertFile.
+type Options struct {
+	// Delimiter separates the values of a record. The zero value uses a comma; use '\t' for TSV.
+	Delimiter rune
+
+	// Null is written in place of null values. The zero value writes an empty string.
+	Null string
+
+	// DateFormat and DateTimeFormat are time.Time layouts for Date and DateTime values. Empty strings use
+	// DefaultDateFormat and DefaultDateTimeFormat.
+	DateFormat     string
+	DateTimeFormat string
+
+	// FloatFormat is a fmt verb such as "%.2f" used for Float and Double values. An empty string writes the shortest
+	// representation that parses back to the same value.
+	FloatFormat string
+
+	// NoHeader leaves out the header row of field names.
+	NoHeader bool
+
+	// UseCRLF ends records with \r\n, as RFC 4180 specifies, instead of \n.
+	UseCRLF bool
+
+	Blobs BlobFormat
+}
+
+// ConvertFile converts the .yxdb file at yxdbPath to a delimited text file at csvPath, replacing it if it exists.
+func ConvertFile(yxdbPath string, csvPath string, options Options) error {
+	return convert.File(yxdbPath, csvPath, func(w io.Writer, reader yx.Reader) error {
+		return Convert(w, reader, options)
+	})
+}
+
+// Convert writes the remaining records of reader to w as delimited text. The columns are the fields of the reader.
+func Convert(w io.Writer, reader yx.Reader, options Options) error {
+	writer, err := NewWriter(w, reader.ListFields(), options)
+	if err != nil {
+		return err
+	}
+	return convert.Copy(writer, reader, func() int { return batchSize })
+}
+
+// A Writer writes batches of records read with Reader.NextBatch as delimited text.
+//
+// The header row is written by NewWriter. Output is buffered, so Close must be called after the last batch. Close
+// does not close the underlying io.Writer.
+type Writer struct {
+	out        *csv.Writer
+	options    Options
+	formatters []formatter
+	row        []string
+	closed     bool
+
+	// records is the number of records written, for reporting the record that cannot be formatted.
+	records int64
+}
+
+// formatter formats the value at row of a column, which is not null.
+type formatter struct {
+	index  int
+	format func(column *yx.Column, row int) (string, error)
+}
+
+// NewWriter writes the header row for fields to w, unless options.NoHeader is set, and returns a Writer for batches
+// with those fields.
+func NewWriter(w io.Writer, fields []yxrecord.YxdbField, options Options) (*Writer, error) {
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.DateFormat == `` {
+		options.DateFormat = DefaultDateFormat
+	}
+	if options.DateTimeFormat == `` {
+		options.DateTimeFormat = DefaultDateTimeFormat
+	}
+	out := csv.NewWriter(w)
+	out.Comma = options.Delimiter
+	out.UseCRLF = options.UseCRLF
+	writer := &Writer{out: out, options: options}
+	var header []string
+	for index, field := range fields {
+		format, err := writer.newFormat(field)
+		if err != nil {
+			return nil, err
+		}
+		if format == nil {
+			continue
+		}
+		writer.formatters = append(writer.formatters, formatter{index: index, format: format})
+		header = append(header, field.Name)
+	}
+	writer.row = make([]string, len(writer.formatters))
+	if !options.NoHeader {
+		err := out.Write(header)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+// Write writes the records of batch. The batch must have the fields passed to NewWriter.
+func (w *Writer) Write(batch *yx.Batch) error {
+	if w.closed {
+		return convert.ErrWriterClosed
+	}
+	for row := 0; row < batch.Len; row++ {
+		for index, f := range w.formatters {
+			column := &batch.Columns[f.index]
+			if column.IsNull(row) {
+				w.row[index] = w.options.Null
+				continue
+			}
+			value, err := f.format(column, row)
+			if err != nil {
+				return fmt.Errorf(`error formatting field '%v' of record %v: %w`, column.Field.Name, w.records+1, err)
+			}
+			w.row[index] = value
+		}
+		err := w.out.Write(w.row)
+		if err != nil {
+			return err
+		}
+		w.records++
+	}
+	return nil
+}
+
+// Close writes any buffered records to the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	w.out.Flush()
+	return w.out.Error()
+}
+
+// newFormat returns the function that formats values of field, or nil if the field is omitted.
+func (w *Writer) newFormat(field yxrecord.YxdbField) (func(*yx.Column, int) (string, error), error) {
+	switch field.FieldType {
+	case yxrecord.TypeBool:
+		return func(c *yx.Column, row int) (string, error) {
+			return strconv.FormatBool(c.Bools[row]), nil
+		}, nil
+	case yxrecord.TypeByte:
+		return func(c *yx.Column, row int) (string, error) {
+			return strconv.Itoa(int(c.Bytes[row])), nil
+		}, nil
+	case yxrecord.TypeInt16, yxrecord.TypeInt32, yxrecord.TypeInt64:
+		return func(c *yx.Column, row int) (string, error) {
+			return strconv.FormatInt(c.Int64s[row], 10), nil
+		}, nil
+	case yxrecord.TypeFixedDecimal:
+		return func(c *yx.Column, row int) (string, error) {
+			return c.Decimals[row].String(), nil
+		}, nil
+	case yxrecord.TypeFloat:
+		return w.floatFormat(32), nil
+	case yxrecord.TypeDouble:
+		return w.floatFormat(64), nil
+	case yxrecord.TypeString, yxrecord.TypeWString, yxrecord.TypeV_String, yxrecord.TypeV_WString:
+		return func(c *yx.Column, row int) (string, error) {
+			return c.Strings[row], nil
+		}, nil
+	case yxrecord.TypeDate:
+		return w.timeFormat(w.options.DateFormat), nil
+	case yxrecord.TypeDateTime:
+		return w.timeFormat(w.options.DateTimeFormat), nil
+	case yxrecord.TypeBlob, yxrecord.TypeSpatialObj:
+		return w.blobFormat(field.FieldType), nil
+	default:
+		return nil, fmt.Errorf(`field '%v' has unsupported type %v`, field.Name, field.FieldType)
+	}
+}
+
+func (w *Writer) floatFormat(bitSize int) func(*yx.Column, int) (string, error) {
+	if w.options.FloatFormat != `` {
+		return func(c *yx.Column, row int) (string, error) {
+			return fmt.Sprintf(w.options.FloatFormat, c.Float64s[row]), nil
+		}
+	}
+	return func(c *yx.Column, row int) (string, error) {
+		return strconv.FormatFloat(c.Float64s[row], 'g', -1, bitSize), nil
+	}
+}
+
+func (w *Writer) timeFormat(layout string) func(*yx.Column, int) (string, error) {
+	return func(c *yx.Column, row int) (string, error) {
+		return c.Times[row].Format(layout), nil
+	}
+}
+
+func (w *Writer) blobFormat(fieldType yxrecord.FieldType) func(*yx.Column, int) (string, error) {
+	switch {
+	case w.options.Blobs == Omit:
+		return nil
+	case w.options.Blobs == Hex:
+		return func(c *yx.Column, row int) (string, error) {
+			return hex.EncodeToString(c.Blobs[row]), nil
+		}
+	case w.options.Blobs == GeoJSON && fieldType == yxrecord.TypeSpatialObj:
+		return func(c *yx.Column, row int) (string, error) {
+			return spatial.ToGeoJSON(c.Blobs[row])
+		}
+	default:
+		return func(c *yx.Column, row int) (string, error) {
+			return base64.StdEncoding.EncodeToString(c.Blobs[row]), nil
+		}
+	}
+}
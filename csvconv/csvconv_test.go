@@ -0,0 +1,163 @@
+package csvconv_test
+
+import (
+	"bytes"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/csvconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	path := createTestFile(t)
+	expected := "Id,Amount,Price,Ratio,Name,Date,DateTime,Data\n" +
+		"1,12.50,1.25,0.1,\"Smith, \"\"Jr\"\"\",2024-02-29,2024-02-29 13:45:30,AQL/\n" +
+		"2,,,,,,,\n"
+	if actual := convert(t, path, csvconv.Options{}); actual != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, actual)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	path := createTestFile(t)
+	options := csvconv.Options{
+		Delimiter:      '\t',
+		Null:           `NULL`,
+		DateFormat:     `01/02/2006`,
+		DateTimeFormat: time.RFC3339,
+		FloatFormat:    `%.3f`,
+		NoHeader:       true,
+		UseCRLF:        true,
+		Blobs:          csvconv.Hex,
+	}
+	expected := "1\t12.50\t1.250\t0.100\t\"Smith, \"\"Jr\"\"\"\t02/29/2024\t2024-02-29T13:45:30Z\t0102ff\r\n" +
+		"2\tNULL\tNULL\tNULL\tNULL\tNULL\tNULL\tNULL\r\n"
+	if actual := convert(t, path, options); actual != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, actual)
+	}
+}
+
+func TestOmitBlobs(t *testing.T) {
+	path := createTestFile(t)
+	expected := "Id,Amount,Price,Ratio,Name,Date,DateTime\n"
+	actual := convert(t, path, csvconv.Options{Blobs: csvconv.Omit})
+	if actual[:len(expected)] != expected {
+		t.Fatalf("expected a header of\n%v\nbut got\n%v", expected, actual)
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	expected := "RecordID,Spatial\n1,\"{\"\"type\"\":\"\"Point\"\",\"\"coordinates\"\":[-96.679688,37.230328]}\"\n"
+	reader, err := yx.ReadFile(`../test_files/point.yxdb`)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	var data bytes.Buffer
+	err = csvconv.Convert(&data, reader, csvconv.Options{Blobs: csvconv.GeoJSON})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if actual := data.String(); actual != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, actual)
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `out.csv`)
+	err := csvconv.ConvertFile(`../test_files/LotsOfRecords.yxdb`, path, csvconv.Options{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	data, _ := os.ReadFile(path)
+	if lines := bytes.Count(data, []byte("\n")); lines != 100001 {
+		t.Fatalf(`expected 100001 lines but got %v`, lines)
+	}
+	if !bytes.HasSuffix(data, []byte("\n100000\n")) {
+		t.Fatalf(`expected the last line to be 100000`)
+	}
+}
+
+func TestErrorReportsRecordNumber(t *testing.T) {
+	field := yxrecord.YxdbField{Name: `Shape`, Type: yxrecord.Blob, FieldType: yxrecord.TypeSpatialObj}
+	writer, err := csvconv.NewWriter(&bytes.Buffer{}, []yxrecord.YxdbField{field}, csvconv.Options{Blobs: csvconv.GeoJSON})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	nulls := &yx.Batch{Len: 2, Columns: []yx.Column{{Field: field, Nulls: []uint64{3}, Blobs: [][]byte{nil, nil}}}}
+	if err = writer.Write(nulls); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	invalid := &yx.Batch{Len: 1, Columns: []yx.Column{{Field: field, Nulls: []uint64{0}, Blobs: [][]byte{{1, 2, 3}}}}}
+	err = writer.Write(invalid)
+	if err == nil || !strings.Contains(err.Error(), `of record 3:`) {
+		t.Fatalf(`expected an error for record 3 but got %v`, err)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	writer, err := csvconv.NewWriter(&bytes.Buffer{}, nil, csvconv.Options{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	_ = writer.Close()
+	if err = writer.Write(&yx.Batch{}); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf(`expected a second Close to return nil but got: %v`, err.Error())
+	}
+}
+
+// createTestFile writes a record with a value in every field followed by a record of nulls.
+func createTestFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), `test.yxdb`)
+	writer, err := yx.CreateFile(path, []metafield.MetaInfoField{
+		{Name: `Id`, Type: `Int32`},
+		{Name: `Amount`, Type: `FixedDecimal`, Size: 10, Scale: 2},
+		{Name: `Price`, Type: `Float`},
+		{Name: `Ratio`, Type: `Double`},
+		{Name: `Name`, Type: `V_WString`},
+		{Name: `Date`, Type: `Date`},
+		{Name: `DateTime`, Type: `DateTime`},
+		{Name: `Data`, Type: `Blob`},
+	})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	writer.WriteInt64WithIndex(0, 1)
+	writer.WriteFloat64WithIndex(1, 12.5)
+	writer.WriteFloat64WithIndex(2, 1.25)
+	writer.WriteFloat64WithIndex(3, 0.1)
+	writer.WriteStringWithIndex(4, `Smith, "Jr"`)
+	writer.WriteTimeWithIndex(5, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	writer.WriteTimeWithIndex(6, time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC))
+	writer.WriteBlobWithIndex(7, []byte{1, 2, 255})
+	_ = writer.WriteRecord()
+	writer.WriteInt64WithIndex(0, 2)
+	_ = writer.WriteRecord()
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return path
+}
+
+func convert(t *testing.T, path string, options csvconv.Options) string {
+	reader, err := yx.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	var data bytes.Buffer
+	err = csvconv.Convert(&data, reader, options)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return data.String()
+}
@@ -0,0 +1,485 @@
+package csvconv
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/yximport"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSampleSize is the number of records used to infer field types when ImportOptions.SampleSize is not set.
+const DefaultSampleSize = yximport.DefaultSampleSize
+
+// ImportOptions configures Import, ImportFile, and InferFields.
+type ImportOptions struct {
+	// Delimiter separates the values of a record. The zero value uses a comma; use '\t' for TSV.
+	Delimiter rune
+
+	// Null is the text of null values. Empty values of fields that are not strings are always null.
+	Null string
+
+	// DateFormat and DateTimeFormat are time.Time layouts for Date and DateTime values. Empty strings use
+	// DefaultDateFormat and DefaultDateTimeFormat.
+	DateFormat     string
+	DateTimeFormat string
+
+	// NoHeader reads the first record as data. The fields are named Field_1, Field_2, and so on.
+	NoHeader bool
+
+	// SampleSize is the number of records read to infer the type of each field. Values below 1 use
+	// DefaultSampleSize.
+	SampleSize int
+
+	// Fields replaces the inferred fields with the same name, for columns whose type cannot be inferred from the
+	// sample or should be different.
+	Fields []metafield.MetaInfoField
+}
+
+// ImportResult describes the .yxdb file written by Import.
+type ImportResult = yximport.Result
+
+// A RowError is a problem with one record of the CSV. Values that cannot be converted to the type of their field are
+// written as null, and String and WString values longer than their field are truncated. Records that cannot be parsed
+// as CSV are skipped. Record counts the records of the CSV, starting at 1 for the first record after the header.
+type RowError = yximport.RowError
+
+// ImportFile converts the CSV file at csvPath to a .yxdb file at yxdbPath, replacing it if it exists.
+func ImportFile(csvPath string, yxdbPath string, options ImportOptions) (*ImportResult, error) {
+	return yximport.File(csvPath, yxdbPath, func(r io.Reader, w io.WriteSeeker) (*ImportResult, error) {
+		return Import(r, w, options)
+	})
+}
+
+// Import reads delimited text from r and writes it to w as a .yxdb file.
+//
+// The type of each field is inferred from the first ImportOptions.SampleSize records, unless it is set in
+// ImportOptions.Fields. Problems with individual records are listed in the result rather than stopping the import;
+// the returned error is only set when the file cannot be read or written.
+func Import(r io.Reader, w io.WriteSeeker, options ImportOptions) (*ImportResult, error) {
+	in, err := newImporter(r, options)
+	if err != nil {
+		return nil, err
+	}
+	converters := make([]converter, len(in.fields))
+	for index, field := range in.fields {
+		converters[index], err = in.newConverter(field)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return yximport.Write(w, in.fields, in.sampler, func(writer yx.Writer, row row) ([]*RowError, bool) {
+		if row.values == nil {
+			return row.errors, false
+		}
+		rowErrors := row.errors
+		for index, value := range row.values {
+			if in.isNull(value, in.fields[index]) {
+				continue
+			}
+			err := converters[index](writer, index, value)
+			if err != nil {
+				rowErrors = append(rowErrors, &RowError{
+					Record: row.record,
+					Line:   row.line,
+					Field:  in.fields[index].Name,
+					Value:  value,
+					Err:    err,
+				})
+			}
+		}
+		return rowErrors, true
+	})
+}
+
+// InferFields reads the header and sample of delimited text from r and returns the fields Import would write.
+func InferFields(r io.Reader, options ImportOptions) ([]metafield.MetaInfoField, error) {
+	in, err := newImporter(r, options)
+	if err != nil {
+		return nil, err
+	}
+	return in.fields, nil
+}
+
+// importer reads the records of a CSV, holding back the sample used to infer the fields until they are written.
+type importer struct {
+	reader  *csv.Reader
+	options ImportOptions
+	fields  []metafield.MetaInfoField
+	sampler *yximport.Sampler[row]
+	records int
+}
+
+// row is one record of the CSV, with values padded or cut to the number of fields. Values is nil for records that
+// could not be parsed.
+type row struct {
+	record int
+	line   int
+	values []string
+	errors []*RowError
+}
+
+// converter writes the text of a value to the field at index, returning an error if the value cannot be converted.
+type converter = yximport.Converter[string]
+
+func newImporter(r io.Reader, options ImportOptions) (*importer, error) {
+	if options.Delimiter == 0 {
+		options.Delimiter = ','
+	}
+	if options.DateFormat == `` {
+		options.DateFormat = DefaultDateFormat
+	}
+	if options.DateTimeFormat == `` {
+		options.DateTimeFormat = DefaultDateTimeFormat
+	}
+	if options.SampleSize < 1 {
+		options.SampleSize = DefaultSampleSize
+	}
+	reader := csv.NewReader(r)
+	reader.Comma = options.Delimiter
+	reader.FieldsPerRecord = -1
+	in := &importer{reader: reader, options: options}
+
+	var names []string
+	if !options.NoHeader {
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New(`the csv has no header`)
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = fieldNames(header)
+	}
+	inferences := make([]inference, len(names))
+	sampler, err := yximport.Sample(options.SampleSize, func() (row, error) {
+		return in.read(len(names))
+	}, func(row row) {
+		if names == nil && row.values != nil {
+			// without a header, the first record decides the number of fields
+			names = fieldNames(make([]string, len(row.values)))
+			inferences = make([]inference, len(names))
+		}
+		for index, value := range row.values {
+			if value != options.Null && value != `` {
+				inferences[index].observe(value, options)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	in.sampler = sampler
+	if len(names) == 0 {
+		return nil, errors.New(`the csv has no fields`)
+	}
+
+	in.fields = make([]metafield.MetaInfoField, len(names))
+	for index, name := range names {
+		in.fields[index] = inferences[index].field(name)
+	}
+	for _, override := range options.Fields {
+		found := false
+		for index := range in.fields {
+			if in.fields[index].Name == override.Name {
+				in.fields[index] = override
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf(`field '%v' is not in the csv: %w`, override.Name, yx.ErrFieldNotFound)
+		}
+	}
+	return in, nil
+}
+
+// fieldNames returns the names of the fields for a header, naming empty columns after their position and
+// distinguishing duplicates with a suffix.
+func fieldNames(header []string) []string {
+	names := make([]string, len(header))
+	used := make(map[string]bool, len(header))
+	for index, name := range header {
+		if name == `` {
+			name = fmt.Sprintf(`Field_%v`, index+1)
+		}
+		unique := name
+		for suffix := 2; used[unique]; suffix++ {
+			unique = fmt.Sprintf(`%v_%v`, name, suffix)
+		}
+		used[unique] = true
+		names[index] = unique
+	}
+	return names
+}
+
+// read reads a record from the CSV. Records with a different number of values than fields are reported and padded
+// with nulls or cut.
+func (in *importer) read(fields int) (row, error) {
+	values, err := in.reader.Read()
+	in.records++
+	r := row{record: in.records}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		r.line = parseErr.StartLine
+		r.errors = append(r.errors, &RowError{Record: r.record, Line: r.line, Err: parseErr.Err})
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	r.line, _ = in.reader.FieldPos(0)
+	if fields > 0 && len(values) != fields {
+		r.errors = append(r.errors, &RowError{
+			Record: r.record,
+			Line:   r.line,
+			Err:    fmt.Errorf(`expected %v values but got %v`, fields, len(values)),
+		})
+		for len(values) < fields {
+			values = append(values, in.options.Null)
+		}
+		values = values[:fields]
+	}
+	r.values = values
+	return r, nil
+}
+
+func (in *importer) isNull(value string, field metafield.MetaInfoField) bool {
+	if value == in.options.Null {
+		return true
+	}
+	return value == `` && !isStringType(field.Type)
+}
+
+func isStringType(fieldType string) bool {
+	switch fieldType {
+	case `String`, `WString`, `V_String`, `V_WString`:
+		return true
+	}
+	return false
+}
+
+// newConverter returns the converter for the values of field.
+func (in *importer) newConverter(field metafield.MetaInfoField) (converter, error) {
+	switch field.Type {
+	case `Bool`:
+		return func(writer yx.Writer, index int, value string) error {
+			switch {
+			case strings.EqualFold(value, `true`):
+				writer.WriteBoolWithIndex(index, true)
+			case strings.EqualFold(value, `false`):
+				writer.WriteBoolWithIndex(index, false)
+			default:
+				return errors.New(`expected true or false`)
+			}
+			return nil
+		}, nil
+	case `Byte`:
+		return func(writer yx.Writer, index int, value string) error {
+			parsed, err := strconv.ParseUint(value, 10, 8)
+			if err != nil {
+				return err
+			}
+			writer.WriteByteWithIndex(index, byte(parsed))
+			return nil
+		}, nil
+	case `Int16`:
+		return yximport.IntConverter[string](16), nil
+	case `Int32`:
+		return yximport.IntConverter[string](32), nil
+	case `Int64`:
+		return yximport.IntConverter[string](64), nil
+	case `FixedDecimal`:
+		return func(writer yx.Writer, index int, value string) error {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			return yximport.WriteDecimal(writer, index, field, parsed)
+		}, nil
+	case `Float`, `Double`:
+		return func(writer yx.Writer, index int, value string) error {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return err
+			}
+			writer.WriteFloat64WithIndex(index, parsed)
+			return nil
+		}, nil
+	case `String`, `WString`, `V_String`, `V_WString`:
+		return func(writer yx.Writer, index int, value string) error {
+			return yximport.WriteString(writer, index, field, value)
+		}, nil
+	case `Date`:
+		return in.timeConverter(in.options.DateFormat), nil
+	case `DateTime`:
+		return in.timeConverter(in.options.DateTimeFormat), nil
+	case `Blob`:
+		return func(writer yx.Writer, index int, value string) error {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return err
+			}
+			writer.WriteBlobWithIndex(index, decoded)
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf(`field '%v' of type %v cannot be imported from csv`, field.Name, field.Type)
+	}
+}
+
+func (in *importer) timeConverter(layout string) converter {
+	return func(writer yx.Writer, index int, value string) error {
+		parsed, err := time.Parse(layout, value)
+		if err != nil {
+			return err
+		}
+		writer.WriteTimeWithIndex(index, parsed)
+		return nil
+	}
+}
+
+// maxStringSize is the largest String field inferred; longer or non-ASCII values are imported as V_WString.
+const maxStringSize = 255
+
+// maxDecimalDigits is the largest number of digits inferred as FixedDecimal. FixedDecimal values are written from a
+// float64, which holds up to 15 significant digits exactly, so larger values are imported as Double.
+const maxDecimalDigits = 15
+
+// inference tracks the types that hold every non-null value of a column seen so far.
+type inference struct {
+	values     int
+	notBool    bool
+	notInt     bool
+	notDecimal bool
+	notDouble  bool
+	notDate    bool
+	notTime    bool
+	wide       bool
+
+	min, max  int64
+	intDigits int
+	scale     int
+	negative  bool
+	length    int
+}
+
+func (i *inference) observe(value string, options ImportOptions) {
+	i.values++
+	if !i.notBool && !strings.EqualFold(value, `true`) && !strings.EqualFold(value, `false`) {
+		i.notBool = true
+	}
+	number := isNumber(value)
+	if !i.notInt {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || !number {
+			i.notInt = true
+		} else if i.values == 1 {
+			i.min, i.max = parsed, parsed
+		} else {
+			i.min, i.max = min(i.min, parsed), max(i.max, parsed)
+		}
+	}
+	if !i.notDecimal {
+		i.observeDecimal(value, number)
+	}
+	if !i.notDouble {
+		if _, err := strconv.ParseFloat(value, 64); err != nil || !number {
+			i.notDouble = true
+		}
+	}
+	if !i.notDate {
+		if _, err := time.Parse(options.DateFormat, value); err != nil {
+			i.notDate = true
+		}
+	}
+	if !i.notTime {
+		if _, err := time.Parse(options.DateTimeFormat, value); err != nil {
+			i.notTime = true
+		}
+	}
+	for _, char := range value {
+		if char > 0x7f {
+			i.wide = true
+			break
+		}
+	}
+	i.length = max(i.length, yximport.StringLength(value, `V_WString`))
+}
+
+// observeDecimal records the digits of value if it is written as a plain decimal, such as -12.50.
+func (i *inference) observeDecimal(value string, number bool) {
+	digits := strings.TrimPrefix(value, `-`)
+	whole, fraction, _ := strings.Cut(digits, `.`)
+	if !number || whole == `` || !isDigits(whole) || !isDigits(fraction) || strings.HasSuffix(digits, `.`) {
+		i.notDecimal = true
+		return
+	}
+	i.negative = i.negative || digits != value
+	i.intDigits = max(i.intDigits, len(whole))
+	i.scale = max(i.scale, len(fraction))
+	if i.intDigits+i.scale > maxDecimalDigits {
+		i.notDecimal = true
+	}
+}
+
+// isNumber reports whether value looks like a number rather than an identifier: it contains a digit and has no
+// leading zeros, which are kept in codes such as zip codes, and is not written in hexadecimal.
+func isNumber(value string) bool {
+	digits := strings.TrimLeft(value, `+-`)
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return false
+	}
+	return strings.ContainsAny(digits, `0123456789`)
+}
+
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// field returns the narrowest field that holds every value observed.
+func (i *inference) field(name string) metafield.MetaInfoField {
+	field := metafield.MetaInfoField{Name: name}
+	switch {
+	case i.values == 0:
+		field.Type, field.Size = `V_WString`, 1
+	case !i.notBool:
+		field.Type = `Bool`
+	case !i.notInt && i.min >= math.MinInt16 && i.max <= math.MaxInt16:
+		field.Type = `Int16`
+	case !i.notInt && i.min >= math.MinInt32 && i.max <= math.MaxInt32:
+		field.Type = `Int32`
+	case !i.notInt:
+		field.Type = `Int64`
+	case !i.notDecimal:
+		field.Type, field.Scale = `FixedDecimal`, i.scale
+		// the size counts the decimal point and the sign as well as the digits
+		field.Size = i.intDigits + i.scale + 1
+		if i.negative {
+			field.Size++
+		}
+	case !i.notDouble:
+		field.Type = `Double`
+	case !i.notDate:
+		field.Type = `Date`
+	case !i.notTime:
+		field.Type = `DateTime`
+	case !i.wide && i.length <= maxStringSize:
+		field.Type, field.Size = `String`, i.length
+	default:
+		field.Type, field.Size = `V_WString`, i.length
+	}
+	return field
+}
@@ -0,0 +1,237 @@
+package csvconv_test
+
+import (
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/csvconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const vendorCsv = `Small,Medium,Large,Amount,Ratio,Flag,Day,Moment,Zip,Code,Note,Empty
+1,40000,5000000000,-12.50,1e-3,true,2024-02-29,2024-02-29 13:45:30,01234,AB,"Crème, brûlée",
+-2,,7,3.125,2.5,FALSE,,,99501,ABCDE,"two
+lines",
+`
+
+func TestInferFields(t *testing.T) {
+	fields, err := csvconv.InferFields(strings.NewReader(vendorCsv), csvconv.ImportOptions{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	expected := []metafield.MetaInfoField{
+		{Name: `Small`, Type: `Int16`},
+		{Name: `Medium`, Type: `Int32`},
+		{Name: `Large`, Type: `Int64`},
+		{Name: `Amount`, Type: `FixedDecimal`, Size: 7, Scale: 3},
+		{Name: `Ratio`, Type: `Double`},
+		{Name: `Flag`, Type: `Bool`},
+		{Name: `Day`, Type: `Date`},
+		{Name: `Moment`, Type: `DateTime`},
+		{Name: `Zip`, Type: `String`, Size: 5},
+		{Name: `Code`, Type: `String`, Size: 5},
+		{Name: `Note`, Type: `V_WString`, Size: 13},
+		{Name: `Empty`, Type: `V_WString`, Size: 1},
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, fields)
+	}
+}
+
+func TestImport(t *testing.T) {
+	reader, result := importCsv(t, vendorCsv, csvconv.ImportOptions{})
+	if len(result.Errors) != 0 {
+		t.Fatalf(`expected no errors but got %v`, result.Errors)
+	}
+	if result.Records != 2 || reader.NumRecords() != 2 {
+		t.Fatalf(`expected 2 records but got %v`, result.Records)
+	}
+	reader.Next()
+	expected := map[string]any{
+		`Small`:  int64(1),
+		`Large`:  int64(5000000000),
+		`Ratio`:  0.001,
+		`Flag`:   true,
+		`Day`:    time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		`Moment`: time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC),
+		`Zip`:    `01234`,
+		`Note`:   `Crème, brûlée`,
+	}
+	checkValues(t, reader, expected)
+	if amount, _ := reader.ReadDecimalWithName(`Amount`); amount.String() != `-12.500` {
+		t.Fatalf(`expected -12.500 but got %v`, amount.String())
+	}
+	if _, isNull := reader.ReadValueWithName(`Empty`); !isNull {
+		t.Fatalf(`expected Empty to be null`)
+	}
+	reader.Next()
+	checkValues(t, reader, map[string]any{`Flag`: false, `Note`: "two\nlines"})
+	for _, name := range []string{`Medium`, `Day`, `Moment`} {
+		if _, isNull := reader.ReadValueWithName(name); !isNull {
+			t.Fatalf(`expected %v to be null`, name)
+		}
+	}
+}
+
+func TestImportOptions(t *testing.T) {
+	data := "1\t03/04/2024\tNULL\n2\tNULL\tx\n"
+	options := csvconv.ImportOptions{
+		Delimiter:  '\t',
+		Null:       `NULL`,
+		DateFormat: `01/02/2006`,
+		NoHeader:   true,
+		Fields:     []metafield.MetaInfoField{{Name: `Field_1`, Type: `Byte`}},
+	}
+	reader, result := importCsv(t, data, options)
+	expected := []metafield.MetaInfoField{
+		{Name: `Field_1`, Type: `Byte`},
+		{Name: `Field_2`, Type: `Date`},
+		{Name: `Field_3`, Type: `String`, Size: 1},
+	}
+	if !reflect.DeepEqual(result.Fields, expected) {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, result.Fields)
+	}
+	reader.Next()
+	checkValues(t, reader, map[string]any{`Field_1`: byte(1), `Field_2`: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)})
+	if _, isNull := reader.ReadValueWithName(`Field_3`); !isNull {
+		t.Fatalf(`expected Field_3 to be null`)
+	}
+}
+
+func TestImportNegativeIntegers(t *testing.T) {
+	data := "Small,Medium\n-5,-70000\n-32768,-2147483648\n32767,2147483647\n"
+	reader, result := importCsv(t, data, csvconv.ImportOptions{})
+	if result.Fields[0].Type != `Int16` || result.Fields[1].Type != `Int32` {
+		t.Fatalf(`expected Int16 and Int32 fields but got %v`, result.Fields)
+	}
+	reader.Next()
+	checkValues(t, reader, map[string]any{`Small`: int64(-5), `Medium`: int64(-70000)})
+	var out strings.Builder
+	err := csvconv.Convert(&out, reader, csvconv.Options{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	expected := "Small,Medium\n-32768,-2147483648\n32767,2147483647\n"
+	if out.String() != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, out.String())
+	}
+}
+
+func TestImportRowErrors(t *testing.T) {
+	data := "Id,Name\n1,a\n2,b,extra\n3,c\"d\nx,d\n70000,abcdef\n"
+	options := csvconv.ImportOptions{SampleSize: 2}
+	reader, result := importCsv(t, data, options)
+	if result.Records != 4 {
+		t.Fatalf(`expected 4 records but got %v`, result.Records)
+	}
+	expected := []string{
+		`record 2 (line 3): expected 2 values but got 3`,
+		`record 3 (line 4): bare " in non-quoted-field`,
+		`record 4 (line 5): field 'Id' value 'x': strconv.ParseInt: parsing "x": invalid syntax`,
+		`record 5 (line 6): field 'Id' value '70000': strconv.ParseInt: parsing "70000": value out of range`,
+		`record 5 (line 6): field 'Name' value 'abcdef': value of length 6 was truncated to the field size of 1`,
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf(`expected %v errors but got %v`, len(expected), result.Errors)
+	}
+	for index, err := range result.Errors {
+		if err.Error() != expected[index] {
+			t.Fatalf("expected error %v to be\n%v\nbut got\n%v", index, expected[index], err.Error())
+		}
+	}
+	var ids []any
+	for reader.Next() {
+		id, _ := reader.ReadValueWithName(`Id`)
+		ids = append(ids, id)
+	}
+	if !reflect.DeepEqual(ids, []any{int64(1), int64(2), nil, nil}) {
+		t.Fatalf(`expected ids 1, 2, null, null but got %v`, ids)
+	}
+}
+
+func TestImportDecimalsThatDoNotFit(t *testing.T) {
+	options := csvconv.ImportOptions{Fields: []metafield.MetaInfoField{{Name: `Amount`, Type: `FixedDecimal`, Size: 5, Scale: 2}}}
+	reader, result := importCsv(t, "Amount\n12.34\n12345.67\nNaN\n", options)
+	if result.Records != 3 {
+		t.Fatalf(`expected 3 records but got %v`, result.Records)
+	}
+	expected := []string{
+		`record 2 (line 3): field 'Amount' value '12345.67': 12345.67 does not fit in a FixedDecimal of size 5`,
+		`record 3 (line 4): field 'Amount' value 'NaN': NaN cannot be written to a FixedDecimal`,
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf(`expected %v errors but got %v`, len(expected), result.Errors)
+	}
+	for index, err := range result.Errors {
+		if err.Error() != expected[index] {
+			t.Fatalf("expected error %v to be\n%v\nbut got\n%v", index, expected[index], err.Error())
+		}
+	}
+	var amounts []any
+	for reader.Next() {
+		amount, _ := reader.ReadValueWithName(`Amount`)
+		amounts = append(amounts, amount)
+	}
+	if len(amounts) != 3 || amounts[1] != nil || amounts[2] != nil {
+		t.Fatalf(`expected the values that do not fit to be null but got %v`, amounts)
+	}
+}
+
+func TestImportUnknownField(t *testing.T) {
+	options := csvconv.ImportOptions{Fields: []metafield.MetaInfoField{{Name: `Missing`, Type: `Int32`}}}
+	_, err := csvconv.InferFields(strings.NewReader(vendorCsv), options)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, `out.csv`)
+	err := csvconv.ConvertFile(`../test_files/LotsOfRecords.yxdb`, csvPath, csvconv.Options{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	// the whole file is sampled, so the field is wide enough for every record
+	options := csvconv.ImportOptions{SampleSize: 100000}
+	result, err := csvconv.ImportFile(csvPath, filepath.Join(dir, `in.yxdb`), options)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if result.Records != 100000 || len(result.Errors) != 0 || result.Fields[0].Type != `Int32` {
+		t.Fatalf(`expected 100000 records in an Int32 field but got %v records in %v`, result.Records, result.Fields)
+	}
+}
+
+func importCsv(t *testing.T, data string, options csvconv.ImportOptions) (yx.Reader, *csvconv.ImportResult) {
+	path := filepath.Join(t.TempDir(), `import.yxdb`)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	result, err := csvconv.Import(strings.NewReader(data), file, options)
+	_ = file.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	reader, err := yx.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	t.Cleanup(func() { _ = reader.Close() })
+	return reader, result
+}
+
+func checkValues(t *testing.T, reader yx.Reader, expected map[string]any) {
+	for name, value := range expected {
+		actual, isNull := reader.ReadValueWithName(name)
+		if isNull || !reflect.DeepEqual(actual, value) {
+			t.Fatalf(`expected %v to be %v but got %v`, name, value, actual)
+		}
+	}
+}
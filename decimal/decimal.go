@@ -0,0 +1,124 @@
+package decimal
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is the exact value of a FixedDecimal field.
+//
+// Alteryx stores FixedDecimal values as text, so Decimal keeps that text as-is. Use Rat or Unscaled for arithmetic
+// that must match the totals calculated in Alteryx.
+type Decimal struct {
+	// Text is the value exactly as stored in the file, such as "-1234.500000".
+	Text string
+
+	// Scale is the number of decimal places declared for the field in the MetaInfo XML.
+	Scale int
+}
+
+// Parse validates text as a decimal number and returns it as a Decimal with the specified scale.
+func Parse(text string, scale int) (Decimal, error) {
+	if _, _, ok := split(text); !ok {
+		return Decimal{}, invalid(text)
+	}
+	return Decimal{Text: text, Scale: scale}, nil
+}
+
+// String returns the value exactly as stored in the file.
+func (d Decimal) String() string {
+	return d.Text
+}
+
+// MarshalJSON writes the decimal as a JSON number with the same digits as the value stored in the file. Leading
+// zeros, which JSON does not allow, are removed.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	whole, fraction, ok := split(d.Text)
+	if !ok {
+		return nil, invalid(d.Text)
+	}
+	number := strings.TrimLeft(whole, `0`)
+	if number == `` {
+		number = `0`
+	}
+	if fraction != `` {
+		number += `.` + fraction
+	}
+	if strings.HasPrefix(d.Text, `-`) {
+		number = `-` + number
+	}
+	return []byte(number), nil
+}
+
+// Value implements driver.Valuer so that decimals can be passed directly to database/sql. The value is the exact
+// text of the decimal, which SQL drivers convert to their DECIMAL type without loss.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.Text, nil
+}
+
+// Rat returns the exact value of the decimal, or an error if Text is not a valid decimal.
+func (d Decimal) Rat() (*big.Rat, error) {
+	if _, _, ok := split(d.Text); !ok {
+		return nil, invalid(d.Text)
+	}
+	value, _ := new(big.Rat).SetString(d.Text)
+	return value, nil
+}
+
+// Unscaled returns the value of the decimal multiplied by 10^Scale, which is the representation used by SQL, Arrow,
+// and Parquet decimals. Digits beyond Scale are truncated. It returns an error if Text is not a valid decimal.
+func (d Decimal) Unscaled() (*big.Int, error) {
+	whole, fraction, ok := split(d.Text)
+	if !ok {
+		return nil, invalid(d.Text)
+	}
+	if len(fraction) > d.Scale {
+		fraction = fraction[:d.Scale]
+	} else {
+		fraction += strings.Repeat(`0`, d.Scale-len(fraction))
+	}
+	value, _ := new(big.Int).SetString(whole+fraction, 10)
+	if strings.HasPrefix(d.Text, `-`) {
+		value.Neg(value)
+	}
+	return value, nil
+}
+
+// Float64 returns the nearest float64 to the decimal, as returned by the ReadFloat64Xxx methods.
+func (d Decimal) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.Text, 64)
+	return value
+}
+
+// split separates the digits of text on either side of the decimal point, dropping the sign. A missing whole part is
+// returned as "0".
+func split(text string) (whole string, fraction string, ok bool) {
+	text = strings.TrimPrefix(strings.TrimPrefix(text, `-`), `+`)
+	whole, fraction, _ = strings.Cut(text, `.`)
+	if whole == `` && fraction == `` {
+		return ``, ``, false
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return ``, ``, false
+	}
+	if whole == `` {
+		whole = `0`
+	}
+	return whole, fraction, true
+}
+
+func invalid(text string) error {
+	return fmt.Errorf(`'%v' is not a valid decimal`, text)
+}
+
+func isDigits(text string) bool {
+	for _, char := range text {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"math/big"
+	"testing"
+)
+
+func TestUnscaled(t *testing.T) {
+	cases := []struct {
+		text     string
+		scale    int
+		expected string
+	}{
+		{`123.450000`, 6, `123450000`},
+		{`-0.5`, 2, `-50`},
+		{`12345678901234567890.123456`, 6, `12345678901234567890123456`},
+		{`7`, 3, `7000`},
+		{`1.2345`, 2, `123`},
+		{`.25`, 2, `25`},
+	}
+	for _, c := range cases {
+		d := decimal.Decimal{Text: c.text, Scale: c.scale}
+		actual, err := d.Unscaled()
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		if actual.String() != c.expected {
+			t.Fatalf(`expected %v for %v but got %v`, c.expected, c.text, actual)
+		}
+	}
+}
+
+func TestInvalidText(t *testing.T) {
+	d := decimal.Decimal{Text: `1/3`, Scale: 2}
+	if _, err := d.Rat(); err == nil {
+		t.Fatalf(`expected an error from Rat but got none`)
+	}
+	if _, err := d.Unscaled(); err == nil {
+		t.Fatalf(`expected an error from Unscaled but got none`)
+	}
+}
+
+func TestRatIsExact(t *testing.T) {
+	d := decimal.Decimal{Text: `9007199254740993.000001`, Scale: 6}
+	expected, _ := new(big.Rat).SetString(`9007199254740993000001/1000000`)
+	actual, err := d.Rat()
+	if err != nil || actual.Cmp(expected) != 0 {
+		t.Fatalf(`expected %v but got %v and %v`, expected, actual, err)
+	}
+	if d.String() != `9007199254740993.000001` {
+		t.Fatalf(`expected original text but got %v`, d.String())
+	}
+	if d.Float64() != 9007199254740994 {
+		t.Fatalf(`expected 9007199254740994 but got %v`, d.Float64())
+	}
+}
+
+func TestParse(t *testing.T) {
+	d, err := decimal.Parse(`-12.5`, 2)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if d.Text != `-12.5` || d.Scale != 2 {
+		t.Fatalf(`expected -12.5 with scale 2 but got %v with scale %v`, d.Text, d.Scale)
+	}
+	for _, text := range []string{``, `.`, `1.2.3`, `abc`, `1e5`} {
+		if _, err = decimal.Parse(text, 2); err == nil {
+			t.Fatalf(`expected an error for '%v' but got none`, text)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	values := []decimal.Decimal{{Text: `-0012.500`, Scale: 3}, {Text: `.5`, Scale: 1}, {Text: `42`, Scale: 0}}
+	actual, err := json.Marshal(values)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if string(actual) != `[-12.500,0.5,42]` {
+		t.Fatalf(`expected [-12.500,0.5,42] but got %v`, string(actual))
+	}
+}
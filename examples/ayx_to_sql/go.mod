@@ -1,6 +1,6 @@
 module ayx_to_sql
 
-go 1.21
+go 1.23
 
 require (
 	github.com/denisenkom/go-mssqldb v0.12.3 // indirect
@@ -9,3 +9,5 @@ require (
 	github.com/tlarsendataguy-yxdb/yxdb-go v0.0.0-20231203014200-4f2c373ee42e // indirect
 	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d // indirect
 )
+
+replace github.com/tlarsendataguy-yxdb/yxdb-go => ../..
@@ -104,22 +104,57 @@ func generateCreateTable(r yxdb.Reader, tableName string) string {
 }
 
 func ayxTypeToSqlType(field yxrecord.YxdbField) string {
-	switch field.Type {
-	case yxrecord.Int64, yxrecord.Byte:
+	switch field.FieldType {
+	case yxrecord.TypeByte:
+		return `TINYINT`
+	case yxrecord.TypeInt16:
+		return `SMALLINT`
+	case yxrecord.TypeInt32:
 		return `INT`
-	case yxrecord.Float64:
+	case yxrecord.TypeInt64:
+		return `BIGINT`
+	case yxrecord.TypeFloat:
+		return `REAL`
+	case yxrecord.TypeDouble:
 		return `FLOAT(53)`
-	case yxrecord.Boolean:
+	case yxrecord.TypeFixedDecimal:
+		return fixedDecimalToSqlType(field)
+	case yxrecord.TypeString:
+		return fmt.Sprintf(`VARCHAR(%v)`, sqlStringLength(field.Size, 8000))
+	case yxrecord.TypeWString:
+		return fmt.Sprintf(`NVARCHAR(%v)`, sqlStringLength(field.Size, 4000))
+	case yxrecord.TypeV_String:
+		return `VARCHAR(MAX)`
+	case yxrecord.TypeBool:
 		return `BIT`
-	case yxrecord.Date:
+	case yxrecord.TypeDate:
+		return `DATE`
+	case yxrecord.TypeDateTime:
 		return `DATETIME2`
-	case yxrecord.Blob:
+	case yxrecord.TypeBlob, yxrecord.TypeSpatialObj:
 		return `VARBINARY(MAX)`
 	default:
 		return `NVARCHAR(MAX)`
 	}
 }
 
+// fixedDecimalToSqlType uses the size of a FixedDecimal field as the precision of a SQL Server DECIMAL. The size
+// counts the decimal point and sign, so the precision is never too small. Fields too large for a DECIMAL are stored
+// as FLOAT(53).
+func fixedDecimalToSqlType(field yxrecord.YxdbField) string {
+	if field.Size > 38 {
+		return `FLOAT(53)`
+	}
+	return fmt.Sprintf(`DECIMAL(%v,%v)`, field.Size, field.Scale)
+}
+
+func sqlStringLength(size int, maxSize int) string {
+	if size > maxSize || size < 1 {
+		return `MAX`
+	}
+	return fmt.Sprint(size)
+}
+
 func InsertRows(r yxdb.Reader, db *sql.DB, tableName string) error {
 	fields := r.ListFields()
 	columns := make([]string, len(fields))
@@ -134,11 +169,9 @@ func InsertRows(r yxdb.Reader, db *sql.DB, tableName string) error {
 	}
 	defer func() { _ = stmt.Close() }()
 
-	values := make([]any, len(fields))
 	rowCount := 0
 	for r.Next() {
-		extractRecordInto(r, values)
-		_, err = stmt.Exec(values...)
+		_, err = stmt.Exec(r.Values()...)
 		if err != nil {
 			return err
 		}
@@ -154,32 +187,3 @@ func InsertRows(r yxdb.Reader, db *sql.DB, tableName string) error {
 	fmt.Printf("INFO: Finished processing %v records\n", rowCount)
 	return nil
 }
-
-func extractRecordInto(r yxdb.Reader, values []any) {
-	fields := r.ListFields()
-	var value any
-	var isNull bool
-	for index, field := range fields {
-		switch field.Type {
-		case yxrecord.Int64:
-			value, isNull = r.ReadInt64WithIndex(index)
-		case yxrecord.Date:
-			value, isNull = r.ReadTimeWithIndex(index)
-		case yxrecord.Float64:
-			value, isNull = r.ReadFloat64WithIndex(index)
-		case yxrecord.Blob:
-			isNull = false
-			value = r.ReadBlobWithIndex(index)
-		case yxrecord.Byte:
-			value, isNull = r.ReadByteWithIndex(index)
-		case yxrecord.Boolean:
-			value, isNull = r.ReadBoolWithIndex(index)
-		default:
-			value, isNull = r.ReadStringWithIndex(index)
-		}
-		if isNull {
-			value = nil
-		}
-		values[index] = value
-	}
-}
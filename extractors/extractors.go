@@ -2,6 +2,7 @@ package extractors
 
 import (
 	"encoding/binary"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
 	"math"
 	"reflect"
 	"strconv"
@@ -17,6 +18,7 @@ type BoolExtractor func([]byte) (bool, bool)
 type ByteExtractor func([]byte) (byte, bool)
 type Int64Extractor func([]byte) (int64, bool)
 type Float64Extractor func([]byte) (float64, bool)
+type DecimalExtractor func([]byte) (decimal.Decimal, bool)
 type TimeExtractor func([]byte) (time.Time, bool)
 type StringExtractor func([]byte) (string, bool)
 type BlobExtractor func([]byte) []byte
@@ -45,7 +47,7 @@ func NewInt16Extractor(start int) Int64Extractor {
 		if buffer[start+2] == 1 {
 			return 0, true
 		}
-		return int64(binary.LittleEndian.Uint16(buffer[start : start+2])), false
+		return int64(int16(binary.LittleEndian.Uint16(buffer[start : start+2]))), false
 	}
 }
 
@@ -54,7 +56,7 @@ func NewInt32Extractor(start int) Int64Extractor {
 		if buffer[start+4] == 1 {
 			return 0, true
 		}
-		return int64(binary.LittleEndian.Uint32(buffer[start : start+4])), false
+		return int64(int32(binary.LittleEndian.Uint32(buffer[start : start+4]))), false
 	}
 }
 
@@ -78,6 +80,15 @@ func NewFixedDecimalExtractor(start int, fieldLength int) Float64Extractor {
 	}
 }
 
+func NewDecimalExtractor(start int, fieldLength int, scale int) DecimalExtractor {
+	return func(buffer []byte) (decimal.Decimal, bool) {
+		if buffer[start+fieldLength] == 1 {
+			return decimal.Decimal{}, true
+		}
+		return decimal.Decimal{Text: getString(buffer, start, fieldLength, 1), Scale: scale}, false
+	}
+}
+
 func NewFloatExtractor(start int) Float64Extractor {
 	return func(buffer []byte) (float64, bool) {
 		if buffer[start+4] == 1 {
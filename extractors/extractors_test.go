@@ -1,6 +1,7 @@
 package extractors_test
 
 import (
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
 	"github.com/tlarsendataguy-yxdb/yxdb-go/extractors"
 	"math"
 	"reflect"
@@ -15,6 +16,12 @@ func TestExtractInt16(t *testing.T) {
 	checkNotNull(t, result, isNull, int64(10))
 }
 
+func TestExtractNegativeInt16(t *testing.T) {
+	extract := extractors.NewInt16Extractor(0)
+	result, isNull := extract([]byte{251, 255, 0})
+	checkNotNull(t, result, isNull, int64(-5))
+}
+
 func TestExtractNullInt16(t *testing.T) {
 	extract := extractors.NewInt16Extractor(2)
 	result, isNull := extract([]byte{0, 0, 10, 0, 1, 0})
@@ -27,6 +34,12 @@ func TestExtractInt32(t *testing.T) {
 	checkNotNull(t, result, isNull, int64(10))
 }
 
+func TestExtractNegativeInt32(t *testing.T) {
+	extract := extractors.NewInt32Extractor(0)
+	result, isNull := extract([]byte{249, 255, 255, 255, 0})
+	checkNotNull(t, result, isNull, int64(-7))
+}
+
 func TestExtractNullInt32(t *testing.T) {
 	extract := extractors.NewInt32Extractor(3)
 	result, isNull := extract([]byte{0, 0, 0, 10, 0, 0, 0, 1})
@@ -163,6 +176,18 @@ func TestExtractNullFixedDecimal(t *testing.T) {
 	checkNull(t, result, isNull, 0.0)
 }
 
+func TestExtractDecimal(t *testing.T) {
+	extract := extractors.NewDecimalExtractor(2, 10, 2)
+	result, isNull := extract([]byte{0, 0, 49, 50, 51, 46, 52, 53, 0, 43, 67, 110, 0})
+	checkNotNull(t, result, isNull, decimal.Decimal{Text: `123.45`, Scale: 2})
+}
+
+func TestExtractNullDecimal(t *testing.T) {
+	extract := extractors.NewDecimalExtractor(2, 10, 2)
+	result, isNull := extract([]byte{0, 0, 49, 50, 51, 46, 52, 53, 0, 43, 67, 110, 1})
+	checkNull(t, result, isNull, decimal.Decimal{})
+}
+
 func TestExtractWString(t *testing.T) {
 	extract := extractors.NewWStringExtractor(2, 15)
 	result, isNull := extract([]byte{0, 0, 104, 0, 101, 0, 108, 0, 108, 0, 111, 0, 32, 0, 119, 0, 111, 0, 114, 0, 108, 0, 100, 0, 0, 0, 12, 0, 44, 0, 55, 0, 0})
@@ -0,0 +1,347 @@
+package yxdb
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"time"
+)
+
+// An Operator is the comparison made by a Predicate created with Compare.
+type Operator int
+
+const (
+	Equal Operator = iota
+	NotEqual
+	Less
+	LessOrEqual
+	Greater
+	GreaterOrEqual
+)
+
+var operatorSymbols = []string{`==`, `!=`, `<`, `<=`, `>`, `>=`}
+
+// String returns the symbol of the operator, such as "<=".
+func (o Operator) String() string {
+	if o < 0 || int(o) >= len(operatorSymbols) {
+		return fmt.Sprintf(`Operator(%d)`, int(o))
+	}
+	return operatorSymbols[o]
+}
+
+// holds reports whether the operator is satisfied by the result of comparing a field with a value, where result is
+// negative, zero, or positive as in cmp.Compare.
+func (o Operator) holds(result int) bool {
+	switch o {
+	case Equal:
+		return result == 0
+	case NotEqual:
+		return result != 0
+	case Less:
+		return result < 0
+	case LessOrEqual:
+		return result <= 0
+	case Greater:
+		return result > 0
+	default:
+		return result >= 0
+	}
+}
+
+// A Predicate is a condition on the fields of a record, built with Compare, IsNull, And, Or, and Not and passed to
+// the reader with WithPredicate.
+//
+// Predicates refer to fields by name and are checked against the fields of the file when the Reader is created.
+// They are evaluated directly on the raw bytes of each record, without creating a Record or boxing any values.
+type Predicate struct {
+	compile func(record *yxrecord.YxdbRecord) (matcher, error)
+}
+
+// matcher reports whether the record in buffer satisfies a Predicate.
+type matcher func(buffer []byte) bool
+
+// Compare creates a Predicate that compares the named field with value using op. A null field never satisfies a
+// comparison, including NotEqual; use IsNull to test for nulls.
+//
+// value must suit the type of the field:
+//   - Byte and integer fields accept any Go integer, or a float32 or float64 to compare the field as a float64.
+//   - Float, Double, and FixedDecimal fields accept any Go integer or float and decimal.Decimal. They are compared as
+//     float64 values.
+//   - String fields accept a string and are compared byte by byte.
+//   - Date and DateTime fields accept a time.Time.
+//   - Bool fields accept a bool; false is less than true.
+//
+// Blob and SpatialObj fields cannot be compared. If the field does not exist or value is not suitable, creating the
+// Reader fails with an error wrapping ErrFieldNotFound or ErrTypeMismatch.
+func Compare(name string, op Operator, value any) Predicate {
+	return Predicate{compile: func(record *yxrecord.YxdbRecord) (matcher, error) {
+		index, err := record.IndexOf(name)
+		if err != nil {
+			return nil, err
+		}
+		field := record.Fields[index]
+		switch field.Type {
+		case yxrecord.Byte:
+			if compareValue, ok := toInt64(value); ok {
+				return compareField(func(buffer []byte) (int64, bool) {
+					fieldValue, isNull := record.ExtractByteWithIndex(index, buffer)
+					return int64(fieldValue), isNull
+				}, op, compareValue, cmp.Compare[int64]), nil
+			}
+			if compareValue, ok := toFloat64(value); ok {
+				return compareField(func(buffer []byte) (float64, bool) {
+					fieldValue, isNull := record.ExtractByteWithIndex(index, buffer)
+					return float64(fieldValue), isNull
+				}, op, compareValue, cmp.Compare[float64]), nil
+			}
+		case yxrecord.Int64:
+			if compareValue, ok := toInt64(value); ok {
+				return compareField(func(buffer []byte) (int64, bool) {
+					return record.ExtractInt64WithIndex(index, buffer)
+				}, op, compareValue, cmp.Compare[int64]), nil
+			}
+			if compareValue, ok := toFloat64(value); ok {
+				return compareField(func(buffer []byte) (float64, bool) {
+					fieldValue, isNull := record.ExtractInt64WithIndex(index, buffer)
+					return float64(fieldValue), isNull
+				}, op, compareValue, cmp.Compare[float64]), nil
+			}
+		case yxrecord.Float64:
+			if compareValue, ok := toFloat64(value); ok {
+				return compareField(func(buffer []byte) (float64, bool) {
+					return record.ExtractFloat64WithIndex(index, buffer)
+				}, op, compareValue, cmp.Compare[float64]), nil
+			}
+		case yxrecord.String:
+			if compareValue, ok := value.(string); ok {
+				return compareField(func(buffer []byte) (string, bool) {
+					return record.ExtractStringWithIndex(index, buffer)
+				}, op, compareValue, cmp.Compare[string]), nil
+			}
+		case yxrecord.Date:
+			if compareValue, ok := value.(time.Time); ok {
+				return compareField(func(buffer []byte) (time.Time, bool) {
+					return record.ExtractTimeWithIndex(index, buffer)
+				}, op, compareValue, time.Time.Compare), nil
+			}
+		case yxrecord.Boolean:
+			if compareValue, ok := value.(bool); ok {
+				return compareField(func(buffer []byte) (bool, bool) {
+					return record.ExtractBoolWithIndex(index, buffer)
+				}, op, compareValue, compareBool), nil
+			}
+		}
+		return nil, &filterTypeError{field: field, value: value}
+	}}
+}
+
+// IsNull creates a Predicate that is satisfied when the named field is null.
+func IsNull(name string) Predicate {
+	return Predicate{compile: func(record *yxrecord.YxdbRecord) (matcher, error) {
+		index, err := record.IndexOf(name)
+		if err != nil {
+			return nil, err
+		}
+		switch record.Fields[index].Type {
+		case yxrecord.Byte:
+			return isNullField(record.ExtractByteWithIndex, index), nil
+		case yxrecord.Boolean:
+			return isNullField(record.ExtractBoolWithIndex, index), nil
+		case yxrecord.Int64:
+			return isNullField(record.ExtractInt64WithIndex, index), nil
+		case yxrecord.Float64:
+			return isNullField(record.ExtractFloat64WithIndex, index), nil
+		case yxrecord.String:
+			return isNullField(record.ExtractStringWithIndex, index), nil
+		case yxrecord.Date:
+			return isNullField(record.ExtractTimeWithIndex, index), nil
+		default:
+			return func(buffer []byte) bool {
+				return record.ExtractBlobWithIndex(index, buffer) == nil
+			}, nil
+		}
+	}}
+}
+
+// And creates a Predicate that is satisfied when all of predicates are satisfied. Predicates are evaluated in order
+// and evaluation stops at the first one that is not satisfied. And with no predicates is always satisfied.
+func And(predicates ...Predicate) Predicate {
+	return Predicate{compile: func(record *yxrecord.YxdbRecord) (matcher, error) {
+		matchers, err := compileAll(predicates, record)
+		if err != nil {
+			return nil, err
+		}
+		return func(buffer []byte) bool {
+			for _, match := range matchers {
+				if !match(buffer) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}}
+}
+
+// Or creates a Predicate that is satisfied when any of predicates is satisfied. Predicates are evaluated in order
+// and evaluation stops at the first one that is satisfied. Or with no predicates is never satisfied.
+func Or(predicates ...Predicate) Predicate {
+	return Predicate{compile: func(record *yxrecord.YxdbRecord) (matcher, error) {
+		matchers, err := compileAll(predicates, record)
+		if err != nil {
+			return nil, err
+		}
+		return func(buffer []byte) bool {
+			for _, match := range matchers {
+				if match(buffer) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}}
+}
+
+// Not creates a Predicate that is satisfied when predicate is not satisfied.
+func Not(predicate Predicate) Predicate {
+	return Predicate{compile: func(record *yxrecord.YxdbRecord) (matcher, error) {
+		match, err := predicate.matcher(record)
+		if err != nil {
+			return nil, err
+		}
+		return func(buffer []byte) bool {
+			return !match(buffer)
+		}, nil
+	}}
+}
+
+// matcher compiles the predicate against the fields of record. The zero Predicate is always satisfied.
+func (p Predicate) matcher(record *yxrecord.YxdbRecord) (matcher, error) {
+	if p.compile == nil {
+		return func([]byte) bool { return true }, nil
+	}
+	return p.compile(record)
+}
+
+func compileAll(predicates []Predicate, record *yxrecord.YxdbRecord) ([]matcher, error) {
+	matchers := make([]matcher, len(predicates))
+	for index, predicate := range predicates {
+		match, err := predicate.matcher(record)
+		if err != nil {
+			return nil, err
+		}
+		matchers[index] = match
+	}
+	return matchers, nil
+}
+
+// compileFilter combines the predicates and filter functions of the options into a single matcher, or returns nil
+// if there is nothing to filter. Filter functions are called with records of the full, unprojected record.
+func (r *r) compileFilter(record *yxrecord.YxdbRecord) (matcher, error) {
+	if len(r.options.predicates) == 0 && len(r.options.filters) == 0 {
+		return nil, nil
+	}
+	matchers, err := compileAll(r.options.predicates, record)
+	if err != nil {
+		return nil, err
+	}
+	for _, filter := range r.options.filters {
+		matchers = append(matchers, func(buffer []byte) bool {
+			return filter(Record{record: record, buffer: buffer, plans: &r.scanPlans})
+		})
+	}
+	if len(matchers) == 1 {
+		return matchers[0], nil
+	}
+	return func(buffer []byte) bool {
+		for _, match := range matchers {
+			if !match(buffer) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func compareField[T any](extract func([]byte) (T, bool), op Operator, value T, compare func(T, T) int) matcher {
+	return func(buffer []byte) bool {
+		fieldValue, isNull := extract(buffer)
+		return !isNull && op.holds(compare(fieldValue, value))
+	}
+}
+
+func isNullField[T any](extract func(int, []byte) (T, bool), index int) matcher {
+	return func(buffer []byte) bool {
+		_, isNull := extract(index, buffer)
+		return isNull
+	}
+}
+
+func compareBool(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint:
+		if uint64(v) <= uint64(1<<63-1) {
+			return int64(v), true
+		}
+	case uint64:
+		if v <= 1<<63-1 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat64(value any) (float64, bool) {
+	if intValue, ok := toInt64(value); ok {
+		return float64(intValue), true
+	}
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case decimal.Decimal:
+		return v.Float64(), true
+	}
+	return 0, false
+}
+
+type filterTypeError struct {
+	field yxrecord.YxdbField
+	value any
+}
+
+func (f *filterTypeError) Error() string {
+	return fmt.Sprintf(`%v field '%v' cannot be compared with a %T`, f.field.FieldType, f.field.Name, f.value)
+}
+
+func (f *filterTypeError) Unwrap() error {
+	return ErrTypeMismatch
+}
@@ -0,0 +1,202 @@
+package yxdb_test
+
+import (
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPredicateSkipsRecords(t *testing.T) {
+	predicate := yx.And(yx.Compare(`RowCount`, yx.Greater, 10), yx.Compare(`RowCount`, yx.LessOrEqual, int16(20)))
+	yxdb, err := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithPredicate(predicate))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	expected := int64(11)
+	for yxdb.Next() {
+		value, _ := yxdb.ReadInt64WithIndex(0)
+		if value != expected {
+			t.Fatalf(`expected %v but got %v`, expected, value)
+		}
+		expected++
+	}
+	if err = yxdb.Err(); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if expected != 21 {
+		t.Fatalf(`expected to read up to 20 but read up to %v`, expected-1)
+	}
+}
+
+func TestOrAndNotPredicates(t *testing.T) {
+	predicate := yx.Or(
+		yx.Compare(`RowCount`, yx.Less, 3.5),
+		yx.Not(yx.Compare(`RowCount`, yx.NotEqual, 100000)),
+	)
+	yxdb, err := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithPredicate(predicate))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	var values []int64
+	for record, err := range yxdb.Records() {
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		value, _ := record.ReadInt64WithIndex(0)
+		values = append(values, value)
+	}
+	if len(values) != 4 || values[0] != 1 || values[2] != 3 || values[3] != 100000 {
+		t.Fatalf(`expected [1 2 3 100000] but got %v`, values)
+	}
+}
+
+func TestFilterFunction(t *testing.T) {
+	even := yx.WithFilter(func(record yx.Record) bool {
+		value, _ := record.ReadInt64WithIndex(0)
+		return value%2 == 0
+	})
+	yxdb, err := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), even, yx.WithPredicate(yx.Compare(`RowCount`, yx.Greater, 99990)))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	count := 0
+	for yxdb.Next() {
+		count++
+	}
+	if count != 5 {
+		t.Fatalf(`expected 5 records but got %v`, count)
+	}
+	if yxdb.NumRecords() != 100000 {
+		t.Fatalf(`expected 100000 but got %v`, yxdb.NumRecords())
+	}
+}
+
+func TestCompareFieldTypes(t *testing.T) {
+	path := writeValuesAndNulls(t)
+	predicates := []yx.Predicate{
+		yx.Compare(`ByteField`, yx.Equal, 1),
+		yx.Compare(`BoolField`, yx.Equal, true),
+		yx.Compare(`Int64Field`, yx.GreaterOrEqual, uint8(64)),
+		yx.Compare(`FixedDecimalField`, yx.Greater, 123.4),
+		yx.Compare(`DoubleField`, yx.Less, float32(1)),
+		yx.Compare(`V_WStringShortField`, yx.Equal, `XZY`),
+		yx.Compare(`StringField`, yx.Less, `B`),
+		yx.Compare(`DateTimeField`, yx.Greater, time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC)),
+		yx.Not(yx.IsNull(`BlobField`)),
+	}
+	for index, predicate := range predicates {
+		if count := countMatches(t, path, predicate); count != 1 {
+			t.Fatalf(`expected 1 match for predicate %v but got %v`, index, count)
+		}
+	}
+}
+
+func TestNullsNeverSatisfyComparisons(t *testing.T) {
+	path := writeValuesAndNulls(t)
+	if count := countMatches(t, path, yx.Compare(`Int32Field`, yx.NotEqual, 0)); count != 1 {
+		t.Fatalf(`expected 1 match but got %v`, count)
+	}
+	if count := countMatches(t, path, yx.IsNull(`Int32Field`)); count != 1 {
+		t.Fatalf(`expected 1 match but got %v`, count)
+	}
+	if count := countMatches(t, path, yx.IsNull(`SpatialField`)); count != 1 {
+		t.Fatalf(`expected 1 match but got %v`, count)
+	}
+	if count := countMatches(t, path, yx.And()); count != 2 {
+		t.Fatalf(`expected 2 matches but got %v`, count)
+	}
+	if count := countMatches(t, path, yx.Or()); count != 0 {
+		t.Fatalf(`expected 0 matches but got %v`, count)
+	}
+}
+
+func TestPredicateOnUnprojectedField(t *testing.T) {
+	yxdb, err := yx.ReadFile(writeValuesAndNulls(t), yx.WithColumns(`StringField`), yx.WithPredicate(yx.IsNull(`ByteField`)))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	if !yxdb.Next() {
+		t.Fatalf(`expected a record but got none`)
+	}
+	checkField(t, ``, true, func() (interface{}, bool) { return yxdb.ReadStringWithIndex(0) })
+	if yxdb.Next() {
+		t.Fatalf(`expected no more records`)
+	}
+}
+
+func TestReadRecordAtIgnoresPredicate(t *testing.T) {
+	yxdb, err := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithPredicate(yx.Compare(`RowCount`, yx.Equal, 50)))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	if err = yxdb.ReadRecordAt(9); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	checkField(t, int64(10), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+	yxdb.Next()
+	checkField(t, int64(50), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+}
+
+func TestParallelScanWithPredicate(t *testing.T) {
+	var count atomic.Int64
+	err := yx.ParallelScan(getPath(`LotsOfRecords.yxdb`), 2, func(yx.Record) error {
+		count.Add(1)
+		return nil
+	}, yx.WithPredicate(yx.Compare(`RowCount`, yx.Greater, 65530)))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if count.Load() != 34470 {
+		t.Fatalf(`expected 34470 records but got %v`, count.Load())
+	}
+}
+
+func TestInvalidPredicates(t *testing.T) {
+	_, err := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithPredicate(yx.IsNull(`Missing`)))
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+	_, err = yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithPredicate(yx.Or(yx.Compare(`RowCount`, yx.Equal, `1`))))
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected ErrTypeMismatch but got %v`, err)
+	}
+	expected := `Int32 field 'RowCount' cannot be compared with a string`
+	if err.Error() != expected {
+		t.Fatalf(`expected '%v' but got '%v'`, expected, err.Error())
+	}
+	_, err = yx.ReadFile(writeValuesAndNulls(t), yx.WithPredicate(yx.Compare(`BlobField`, yx.Equal, []byte{1, 2})))
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected ErrTypeMismatch but got %v`, err)
+	}
+}
+
+func TestOperatorString(t *testing.T) {
+	if yx.LessOrEqual.String() != `<=` || yx.Operator(10).String() != `Operator(10)` {
+		t.Fatalf(`unexpected operator strings %v and %v`, yx.LessOrEqual, yx.Operator(10))
+	}
+}
+
+func countMatches(t *testing.T, path string, predicate yx.Predicate) int {
+	yxdb, err := yx.ReadFile(path, yx.WithPredicate(predicate))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+	count := 0
+	for yxdb.Next() {
+		count++
+	}
+	return count
+}
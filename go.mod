@@ -1,3 +1,3 @@
 module github.com/tlarsendataguy-yxdb/yxdb-go
 
-go 1.18
+go 1.23
@@ -0,0 +1,77 @@
+package yxdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+const headerSize = 512
+
+// Header contains the values stored in the 512-byte header at the start of a .yxdb file.
+type Header struct {
+	// Description is the free-text description at the start of the file, such as
+	// "Alteryx Database File  (C) 2020 Alteryx".
+	Description string
+
+	// FileId identifies the version of the file format. Files with a spatial index use a different ID than files
+	// without one.
+	FileId uint32
+
+	// CreationDate is the time the file was created, in UTC and truncated to the second.
+	CreationDate time.Time
+
+	// Flags1 and Flags2 are flag fields whose meaning is not documented by Alteryx.
+	Flags1 uint32
+	Flags2 uint32
+
+	// MetaInfoLength is the length of the MetaInfo XML in UTF-16 characters, including the terminating null character.
+	MetaInfoLength uint32
+
+	// SpatialIndexPos is the file position of the spatial index, or 0 if the file does not have one.
+	SpatialIndexPos int64
+
+	// RecordBlockIndexPos is the file position of the record block index.
+	RecordBlockIndexPos int64
+
+	// NumRecords is the number of records in the file.
+	NumRecords int64
+
+	// CompressionVersion is the version of the compression used for record blocks.
+	CompressionVersion uint32
+}
+
+// HasSpatialIndex returns true if the file contains a spatial index.
+func (h Header) HasSpatialIndex() bool {
+	return h.SpatialIndexPos != 0
+}
+
+func parseHeader(header []byte) Header {
+	return Header{
+		Description:         string(bytes.TrimRight(header[0:64], "\x00")),
+		FileId:              binary.LittleEndian.Uint32(header[64:68]),
+		CreationDate:        time.Unix(int64(binary.LittleEndian.Uint32(header[68:72])), 0).UTC(),
+		Flags1:              binary.LittleEndian.Uint32(header[72:76]),
+		Flags2:              binary.LittleEndian.Uint32(header[76:80]),
+		MetaInfoLength:      binary.LittleEndian.Uint32(header[80:84]),
+		SpatialIndexPos:     int64(binary.LittleEndian.Uint64(header[88:96])),
+		RecordBlockIndexPos: int64(binary.LittleEndian.Uint64(header[96:104])),
+		NumRecords:          int64(binary.LittleEndian.Uint64(header[104:112])),
+		CompressionVersion:  binary.LittleEndian.Uint32(header[112:116]),
+	}
+}
+
+func (h Header) toBytes() []byte {
+	header := make([]byte, headerSize)
+	copy(header[0:64], h.Description)
+	binary.LittleEndian.PutUint32(header[64:68], h.FileId)
+	binary.LittleEndian.PutUint32(header[68:72], uint32(h.CreationDate.Unix()))
+	binary.LittleEndian.PutUint32(header[72:76], h.Flags1)
+	binary.LittleEndian.PutUint32(header[76:80], h.Flags2)
+	binary.LittleEndian.PutUint32(header[80:84], h.MetaInfoLength)
+	binary.LittleEndian.PutUint64(header[88:96], uint64(h.SpatialIndexPos))
+	binary.LittleEndian.PutUint64(header[96:104], uint64(h.RecordBlockIndexPos))
+	binary.LittleEndian.PutUint64(header[104:112], uint64(h.NumRecords))
+	binary.LittleEndian.PutUint32(header[112:116], h.CompressionVersion)
+	return header
+}
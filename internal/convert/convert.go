@@ -0,0 +1,56 @@
+// Package convert holds the code shared by the converter packages that write .yxdb files in other formats.
+package convert
+
+import (
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"io"
+	"os"
+)
+
+// ErrWriterClosed is returned by the Write methods of the converter Writers after Close.
+var ErrWriterClosed = errors.New(`the writer is closed`)
+
+// A BatchWriter writes batches of records read with yxdb.Reader.NextBatch.
+type BatchWriter interface {
+	Write(batch *yx.Batch) error
+	Close() error
+}
+
+// File converts the .yxdb file at yxdbPath to a file at path with convert, replacing the file if it exists.
+func File(yxdbPath string, path string, convert func(io.Writer, yx.Reader) error) error {
+	reader, err := yx.ReadFile(yxdbPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = convert(file, reader)
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Copy writes the remaining records of reader to writer and closes writer. BatchSize is called before each batch is
+// read and returns the number of records to read.
+func Copy(writer BatchWriter, reader yx.Reader, batchSize func() int) error {
+	for {
+		batch, err := reader.NextBatch(batchSize())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		err = writer.Write(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
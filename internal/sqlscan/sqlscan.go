@@ -0,0 +1,127 @@
+// Package sqlscan splits SQL statements into tokens for the SQL parsers of the module.
+package sqlscan
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Kind is the kind of a Token.
+type Kind int
+
+const (
+	// EOF marks the end of the statement.
+	EOF Kind = iota
+	// Ident is an unquoted name or keyword.
+	Ident
+	// QuotedIdent is a name quoted with double quotes, brackets, or backticks, without the quotes.
+	QuotedIdent
+	// String is a string literal quoted with single quotes, without the quotes.
+	String
+	// Number is an unsigned integer or decimal number.
+	Number
+	// Symbol is an operator or punctuation.
+	Symbol
+)
+
+// A Token is a lexical element of a SQL statement.
+type Token struct {
+	Kind Kind
+	Text string
+
+	// Pos is the zero-based position of the token in the statement, in characters.
+	Pos int
+}
+
+// Is reports whether the token is the keyword or symbol text, ignoring case.
+func (t Token) Is(text string) bool {
+	return (t.Kind == Ident || t.Kind == Symbol) && strings.EqualFold(t.Text, text)
+}
+
+var symbols = map[string]bool{
+	`=`: true, `<>`: true, `!=`: true, `<`: true, `<=`: true, `>`: true, `>=`: true,
+	`(`: true, `)`: true, `,`: true, `*`: true, `?`: true, `;`: true, `-`: true, `+`: true, `/`: true, `%`: true,
+	`.`: true, `||`: true,
+}
+
+// Tokenize splits sql into tokens, ending with an EOF token. Whitespace and -- comments are skipped.
+func Tokenize(sql string) ([]Token, error) {
+	var tokens []Token
+	runes := []rune(sql)
+	for pos := 0; pos < len(runes); {
+		char := runes[pos]
+		switch {
+		case unicode.IsSpace(char):
+			pos++
+		case char == '-' && pos+1 < len(runes) && runes[pos+1] == '-':
+			for pos < len(runes) && runes[pos] != '\n' {
+				pos++
+			}
+		case char == '_' || unicode.IsLetter(char):
+			start := pos
+			for pos < len(runes) && (runes[pos] == '_' || unicode.IsLetter(runes[pos]) || unicode.IsDigit(runes[pos])) {
+				pos++
+			}
+			tokens = append(tokens, Token{Kind: Ident, Text: string(runes[start:pos]), Pos: start})
+		case unicode.IsDigit(char) || (char == '.' && pos+1 < len(runes) && unicode.IsDigit(runes[pos+1])):
+			start := pos
+			for pos < len(runes) && (unicode.IsDigit(runes[pos]) || runes[pos] == '.') {
+				pos++
+			}
+			if pos < len(runes) && (runes[pos] == 'e' || runes[pos] == 'E') {
+				pos++
+				if pos < len(runes) && (runes[pos] == '+' || runes[pos] == '-') {
+					pos++
+				}
+				for pos < len(runes) && unicode.IsDigit(runes[pos]) {
+					pos++
+				}
+			}
+			tokens = append(tokens, Token{Kind: Number, Text: string(runes[start:pos]), Pos: start})
+		case char == '\'' || char == '"' || char == '`' || char == '[':
+			closing := char
+			kind := QuotedIdent
+			if char == '[' {
+				closing = ']'
+			} else if char == '\'' {
+				kind = String
+			}
+			text, end, err := readQuoted(runes, pos, closing)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Kind: kind, Text: text, Pos: pos})
+			pos = end
+		default:
+			symbol := string(char)
+			if pos+1 < len(runes) && symbols[string(runes[pos:pos+2])] {
+				symbol = string(runes[pos : pos+2])
+			}
+			if !symbols[symbol] {
+				return nil, fmt.Errorf(`unexpected character '%v' at position %v`, symbol, pos+1)
+			}
+			tokens = append(tokens, Token{Kind: Symbol, Text: symbol, Pos: pos})
+			pos += len(symbol)
+		}
+	}
+	return append(tokens, Token{Kind: EOF, Pos: len(runes)}), nil
+}
+
+// readQuoted reads the quoted text starting at runes[start], where a doubled closing character stands for itself.
+func readQuoted(runes []rune, start int, closing rune) (string, int, error) {
+	var text strings.Builder
+	for pos := start + 1; pos < len(runes); pos++ {
+		if runes[pos] != closing {
+			text.WriteRune(runes[pos])
+			continue
+		}
+		if pos+1 < len(runes) && runes[pos+1] == closing {
+			text.WriteRune(closing)
+			pos++
+			continue
+		}
+		return text.String(), pos + 1, nil
+	}
+	return ``, 0, fmt.Errorf(`unterminated %v at position %v`, string(runes[start]), start+1)
+}
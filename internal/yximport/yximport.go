@@ -0,0 +1,197 @@
+// Package yximport holds the code shared by the packages that import other formats as .yxdb files: the result and
+// errors of an import, the sample of records read to infer the fields, and the conversion of values.
+package yximport
+
+import (
+	"errors"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"unicode/utf16"
+)
+
+// DefaultSampleSize is the number of records used to infer fields when the sample size of an import is not set.
+const DefaultSampleSize = 10000
+
+// Result describes the .yxdb file written by an import.
+type Result struct {
+	Fields  []metafield.MetaInfoField
+	Records int64
+
+	// Errors lists the problems found in individual records, in the order they were found. Records with errors in
+	// their values are still written.
+	Errors []*RowError
+}
+
+// A RowError is a problem with one record of an imported file. Values that cannot be converted to the type of their
+// field are written as null, and String and WString values longer than their field are truncated. Records that cannot
+// be parsed are skipped.
+type RowError struct {
+	// Record is the number of the record, starting at 1 for the first record after the header. It is 0 for formats
+	// with one record per line, such as JSON Lines.
+	Record int
+
+	// Line is the line on which the record starts.
+	Line int
+
+	// Field and Value are the field and value that could not be converted: the text of a CSV value, or the JSON of a
+	// JSON Lines value. Both are empty for errors that affect the whole record.
+	Field string
+	Value string
+
+	Err error
+}
+
+func (e *RowError) Error() string {
+	location := fmt.Sprintf(`line %v`, e.Line)
+	if e.Record > 0 {
+		location = fmt.Sprintf(`record %v (line %v)`, e.Record, e.Line)
+	}
+	if e.Field == `` {
+		return fmt.Sprintf(`%v: %v`, location, e.Err.Error())
+	}
+	return fmt.Sprintf(`%v: field '%v' value '%v': %v`, location, e.Field, e.Value, e.Err.Error())
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// File imports the file at path to a .yxdb file at yxdbPath, replacing it if it exists.
+func File(path string, yxdbPath string, importFile func(io.Reader, io.WriteSeeker) (*Result, error)) (*Result, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	out, err := os.Create(yxdbPath)
+	if err != nil {
+		return nil, err
+	}
+	result, err := importFile(in, out)
+	closeErr := out.Close()
+	if err != nil {
+		return result, err
+	}
+	return result, closeErr
+}
+
+// A Sampler reads the records of an imported file, holding back the sample used to infer the fields until they are
+// written.
+type Sampler[R any] struct {
+	read   func() (R, error)
+	sample []R
+}
+
+// Sample reads up to size records with read, passing each to observe, and returns a Sampler that returns them again
+// before the rest of the records. Read returns io.EOF after the last record.
+func Sample[R any](size int, read func() (R, error), observe func(R)) (*Sampler[R], error) {
+	s := &Sampler[R]{read: read}
+	for len(s.sample) < size {
+		record, err := read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		observe(record)
+		s.sample = append(s.sample, record)
+	}
+	return s, nil
+}
+
+// Next returns the next record, starting with the sample, or io.EOF after the last record.
+func (s *Sampler[R]) Next() (R, error) {
+	if len(s.sample) > 0 {
+		next := s.sample[0]
+		s.sample = s.sample[1:]
+		return next, nil
+	}
+	return s.read()
+}
+
+// Write writes the records of sampler to w as a .yxdb file with fields. Set sets the values of a record on the
+// writer and returns the problems found in the record and whether it is written; records are skipped if it returns
+// false.
+func Write[R any](w io.WriteSeeker, fields []metafield.MetaInfoField, sampler *Sampler[R], set func(yx.Writer, R) ([]*RowError, bool)) (*Result, error) {
+	result := &Result{Fields: fields}
+	writer, err := yx.WriteStream(w, fields)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		record, err := sampler.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+		rowErrors, ok := set(writer, record)
+		result.Errors = append(result.Errors, rowErrors...)
+		if !ok {
+			continue
+		}
+		err = writer.WriteRecord()
+		if err != nil {
+			return result, err
+		}
+		result.Records++
+	}
+	return result, writer.Close()
+}
+
+// A Converter writes a value to the field at index, returning an error if the value cannot be converted.
+type Converter[V any] func(writer yx.Writer, index int, value V) error
+
+// IntConverter returns the Converter of integer fields with bitSize bits.
+func IntConverter[V ~string | ~[]byte](bitSize int) Converter[V] {
+	return func(writer yx.Writer, index int, value V) error {
+		parsed, err := strconv.ParseInt(string(value), 10, bitSize)
+		if err != nil {
+			return err
+		}
+		writer.WriteInt64WithIndex(index, parsed)
+		return nil
+	}
+}
+
+// WriteDecimal sets the FixedDecimal field at index. Values that do not fit in the field are returned as an error
+// and not set, so the record is written with a null rather than failing the yxdb.Writer.
+func WriteDecimal(writer yx.Writer, index int, field metafield.MetaInfoField, value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf(`%v cannot be written to a FixedDecimal`, value)
+	}
+	if formatted := strconv.FormatFloat(value, 'f', field.Scale, 64); len(formatted) > field.Size {
+		return fmt.Errorf(`%v does not fit in a FixedDecimal of size %v`, formatted, field.Size)
+	}
+	writer.WriteFloat64WithIndex(index, value)
+	return nil
+}
+
+// WriteString sets the string field at index, returning an error if the value is longer than a String or WString
+// field and was truncated.
+func WriteString(writer yx.Writer, index int, field metafield.MetaInfoField, value string) error {
+	writer.WriteStringWithIndex(index, value)
+	if field.Type != `String` && field.Type != `WString` {
+		return nil
+	}
+	if length := StringLength(value, field.Type); length > field.Size {
+		return fmt.Errorf(`value of length %v was truncated to the field size of %v`, length, field.Size)
+	}
+	return nil
+}
+
+// StringLength returns the length of value in the units of the size of a field: bytes for String and V_String and
+// UTF-16 code units for the wide types.
+func StringLength(value string, fieldType string) int {
+	if fieldType == `String` || fieldType == `V_String` {
+		return len(value)
+	}
+	return len(utf16.Encode([]rune(value)))
+}
@@ -0,0 +1,439 @@
+package jsonlconv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/yximport"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// DefaultSampleSize is the number of lines used to infer fields when ImportOptions.SampleSize is not set.
+const DefaultSampleSize = yximport.DefaultSampleSize
+
+// dateTimeLayouts are the layouts accepted for DateTime values. Values with a time zone are converted to UTC.
+var dateTimeLayouts = []string{`2006-01-02T15:04:05.999999999`, time.RFC3339Nano, `2006-01-02 15:04:05`}
+
+// geometryTypes are the GeoJSON geometry types stored in SpatialObj fields.
+var geometryTypes = map[string]bool{
+	`Point`:           true,
+	`MultiPoint`:      true,
+	`LineString`:      true,
+	`MultiLineString`: true,
+	`Polygon`:         true,
+	`MultiPolygon`:    true,
+}
+
+// ImportOptions configures Import, ImportFile, and InferFields.
+type ImportOptions struct {
+	// Fields is the list of fields to write. Members of the JSON objects that are not in the list are ignored. If
+	// Fields is empty, the fields are inferred from the members of the first SampleSize lines, in the order they are
+	// first seen.
+	Fields []metafield.MetaInfoField
+
+	// SampleSize is the number of lines read to infer the fields. Values below 1 use DefaultSampleSize.
+	SampleSize int
+}
+
+// ImportResult describes the .yxdb file written by Import.
+type ImportResult = yximport.Result
+
+// A RowError is a problem with one line of JSON. Values that cannot be converted to the type of their field are
+// written as null, and String and WString values longer than their field are truncated. Lines that are not JSON
+// objects are skipped. Record is always 0; Line identifies the record.
+type RowError = yximport.RowError
+
+// ImportFile converts the JSON Lines file at jsonlPath to a .yxdb file at yxdbPath, replacing it if it exists.
+func ImportFile(jsonlPath string, yxdbPath string, options ImportOptions) (*ImportResult, error) {
+	return yximport.File(jsonlPath, yxdbPath, func(r io.Reader, w io.WriteSeeker) (*ImportResult, error) {
+		return Import(r, w, options)
+	})
+}
+
+// Import reads JSON Lines from r and writes them to w as a .yxdb file. Blank lines are skipped.
+//
+// Problems with individual lines are listed in the result rather than stopping the import; the returned error is
+// only set when the file cannot be read or written.
+func Import(r io.Reader, w io.WriteSeeker, options ImportOptions) (*ImportResult, error) {
+	in, err := newImporter(r, options)
+	if err != nil {
+		return nil, err
+	}
+	converters := make([]converter, len(in.fields))
+	indexes := make(map[string]int, len(in.fields))
+	for index, field := range in.fields {
+		converters[index], err = newConverter(field)
+		if err != nil {
+			return nil, err
+		}
+		indexes[field.Name] = index
+	}
+	return yximport.Write(w, in.fields, in.sampler, func(writer yx.Writer, next line) ([]*RowError, bool) {
+		if next.err != nil {
+			return []*RowError{next.err}, false
+		}
+		var rowErrors []*RowError
+		for _, m := range next.members {
+			index, ok := indexes[m.name]
+			if !ok || isNull(m.value) {
+				continue
+			}
+			err := converters[index](writer, index, m.value)
+			if err != nil {
+				rowErrors = append(rowErrors, &RowError{
+					Line:  next.number,
+					Field: m.name,
+					Value: string(m.value),
+					Err:   err,
+				})
+			}
+		}
+		return rowErrors, true
+	})
+}
+
+// InferFields reads the sample of JSON Lines from r and returns the fields Import would write.
+func InferFields(r io.Reader, options ImportOptions) ([]metafield.MetaInfoField, error) {
+	in, err := newImporter(r, options)
+	if err != nil {
+		return nil, err
+	}
+	return in.fields, nil
+}
+
+// importer reads the lines of JSON, holding back the sample used to infer the fields until they are written.
+type importer struct {
+	reader  *bufio.Reader
+	fields  []metafield.MetaInfoField
+	sampler *yximport.Sampler[line]
+	lines   int
+}
+
+// line is one JSON object. Err is set for lines that are not JSON objects.
+type line struct {
+	number  int
+	members []member
+	err     *RowError
+}
+
+type member struct {
+	name  string
+	value json.RawMessage
+}
+
+// converter writes a JSON value to the field at index, returning an error if the value cannot be converted.
+type converter = yximport.Converter[json.RawMessage]
+
+func newImporter(r io.Reader, options ImportOptions) (*importer, error) {
+	if options.SampleSize < 1 {
+		options.SampleSize = DefaultSampleSize
+	}
+	in := &importer{reader: bufio.NewReader(r), fields: options.Fields}
+	sampleSize := options.SampleSize
+	if len(in.fields) > 0 {
+		// the fields are known, so no lines are held back
+		sampleSize = 0
+	}
+
+	var names []string
+	inferences := map[string]*inference{}
+	sampler, err := yximport.Sample(sampleSize, in.read, func(next line) {
+		for _, m := range next.members {
+			i, ok := inferences[m.name]
+			if !ok {
+				i = &inference{}
+				inferences[m.name] = i
+				names = append(names, m.name)
+			}
+			i.observe(m.value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	in.sampler = sampler
+	if len(in.fields) > 0 {
+		return in, nil
+	}
+	if len(names) == 0 {
+		return nil, errors.New(`the json lines have no members`)
+	}
+	for _, name := range names {
+		in.fields = append(in.fields, inferences[name].field(name))
+	}
+	return in, nil
+}
+
+// read reads the next line that is not blank.
+func (in *importer) read() (line, error) {
+	for {
+		text, err := in.reader.ReadBytes('\n')
+		if len(text) == 0 && err != nil {
+			return line{}, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return line{}, err
+		}
+		in.lines++
+		text = bytes.TrimSpace(text)
+		if len(text) == 0 {
+			continue
+		}
+		next := line{number: in.lines}
+		next.members, err = parseObject(text)
+		if err != nil {
+			next.err = &RowError{Line: in.lines, Err: err}
+		}
+		return next, nil
+	}
+}
+
+// parseObject returns the members of the JSON object in text, in order.
+func parseObject(text []byte) ([]member, error) {
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, errors.New(`the line is not a JSON object`)
+	}
+	var members []member
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var m member
+		m.name = token.(string)
+		err = decoder.Decode(&m.value)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	_, err = decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New(`the line has data after the JSON object`)
+	}
+	return members, nil
+}
+
+func isNull(value json.RawMessage) bool {
+	return string(value) == `null`
+}
+
+func newConverter(field metafield.MetaInfoField) (converter, error) {
+	switch field.Type {
+	case `Bool`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			var parsed bool
+			err := json.Unmarshal(value, &parsed)
+			if err != nil {
+				return err
+			}
+			writer.WriteBoolWithIndex(index, parsed)
+			return nil
+		}, nil
+	case `Byte`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			parsed, err := strconv.ParseUint(string(value), 10, 8)
+			if err != nil {
+				return err
+			}
+			writer.WriteByteWithIndex(index, byte(parsed))
+			return nil
+		}, nil
+	case `Int16`:
+		return yximport.IntConverter[json.RawMessage](16), nil
+	case `Int32`:
+		return yximport.IntConverter[json.RawMessage](32), nil
+	case `Int64`:
+		return yximport.IntConverter[json.RawMessage](64), nil
+	case `FixedDecimal`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			parsed, err := strconv.ParseFloat(string(bytes.Trim(value, `"`)), 64)
+			if err != nil {
+				return err
+			}
+			return yximport.WriteDecimal(writer, index, field, parsed)
+		}, nil
+	case `Float`, `Double`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			parsed, err := strconv.ParseFloat(string(value), 64)
+			if err != nil {
+				return err
+			}
+			writer.WriteFloat64WithIndex(index, parsed)
+			return nil
+		}, nil
+	case `String`, `WString`, `V_String`, `V_WString`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			return yximport.WriteString(writer, index, field, stringValue(value))
+		}, nil
+	case `Date`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			var text string
+			err := json.Unmarshal(value, &text)
+			if err != nil {
+				return err
+			}
+			parsed, err := time.Parse(dateLayout, text)
+			if err != nil {
+				return err
+			}
+			writer.WriteTimeWithIndex(index, parsed)
+			return nil
+		}, nil
+	case `DateTime`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			var text string
+			err := json.Unmarshal(value, &text)
+			if err != nil {
+				return err
+			}
+			parsed, ok := parseDateTime(text)
+			if !ok {
+				return fmt.Errorf(`'%v' is not an ISO 8601 date time`, text)
+			}
+			writer.WriteTimeWithIndex(index, parsed)
+			return nil
+		}, nil
+	case `Blob`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			var parsed []byte
+			err := json.Unmarshal(value, &parsed)
+			if err != nil {
+				return err
+			}
+			writer.WriteBlobWithIndex(index, parsed)
+			return nil
+		}, nil
+	case `SpatialObj`:
+		return func(writer yx.Writer, index int, value json.RawMessage) error {
+			parsed, err := spatial.FromGeoJSON(stringValue(value))
+			if err != nil {
+				return err
+			}
+			writer.WriteBlobWithIndex(index, parsed)
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf(`field '%v' has unsupported type %v`, field.Name, field.Type)
+	}
+}
+
+// stringValue returns the text of a JSON string, or the JSON itself for other values so that numbers, objects, and
+// arrays can be kept in string fields.
+func stringValue(value json.RawMessage) string {
+	var text string
+	if json.Unmarshal(value, &text) == nil {
+		return text
+	}
+	return string(value)
+}
+
+func parseDateTime(text string) (time.Time, bool) {
+	for _, layout := range dateTimeLayouts {
+		parsed, err := time.Parse(layout, text)
+		if err == nil {
+			return parsed.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inference tracks the kinds of the non-null values of a member seen so far.
+type inference struct {
+	values     int
+	bools      int
+	ints       int
+	numbers    int
+	dates      int
+	dateTimes  int
+	geometries int
+	min, max   int64
+	length     int
+}
+
+func (i *inference) observe(value json.RawMessage) {
+	if isNull(value) {
+		return
+	}
+	i.values++
+	i.length = max(i.length, yximport.StringLength(stringValue(value), `V_WString`))
+	switch value[0] {
+	case 't', 'f':
+		i.bools++
+	case '"':
+		var text string
+		_ = json.Unmarshal(value, &text)
+		if _, err := time.Parse(dateLayout, text); err == nil {
+			i.dates++
+		} else if _, ok := parseDateTime(text); ok {
+			i.dateTimes++
+		}
+	case '{':
+		var geometry struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(value, &geometry) == nil && geometryTypes[geometry.Type] {
+			i.geometries++
+		}
+	case '[':
+	default:
+		i.numbers++
+		parsed, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return
+		}
+		if i.ints == 0 {
+			i.min, i.max = parsed, parsed
+		}
+		i.ints++
+		i.min, i.max = min(i.min, parsed), max(i.max, parsed)
+	}
+}
+
+// field returns the narrowest field that holds every value observed. Members with values of different kinds, such as
+// numbers and strings, are kept as V_WString.
+func (i *inference) field(name string) metafield.MetaInfoField {
+	field := metafield.MetaInfoField{Name: name}
+	switch i.values {
+	case 0:
+		field.Type, field.Size = `V_WString`, 1
+	case i.bools:
+		field.Type = `Bool`
+	case i.ints:
+		switch {
+		case i.min >= math.MinInt16 && i.max <= math.MaxInt16:
+			field.Type = `Int16`
+		case i.min >= math.MinInt32 && i.max <= math.MaxInt32:
+			field.Type = `Int32`
+		default:
+			field.Type = `Int64`
+		}
+	case i.numbers:
+		field.Type = `Double`
+	case i.dates:
+		field.Type = `Date`
+	case i.dateTimes:
+		field.Type = `DateTime`
+	case i.geometries:
+		field.Type = `SpatialObj`
+	default:
+		field.Type, field.Size = `V_WString`, i.length
+	}
+	return field
+}
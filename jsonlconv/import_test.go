@@ -0,0 +1,168 @@
+package jsonlconv_test
+
+import (
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/jsonlconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const logLines = `{"id":1,"ok":true,"score":0.5,"big":5000000000,"day":"2024-02-29","at":"2024-02-29T13:45:30Z","where":` + point + `,"tags":["a","b"],"msg":"héllo"}
+
+{"id":2,"ok":false,"score":2,"big":7,"day":null,"at":"2024-02-29T08:45:30-05:00","mixed":1,"msg":"x"}
+{"id":3,"mixed":"one"}
+`
+
+func TestInferFields(t *testing.T) {
+	fields, err := jsonlconv.InferFields(strings.NewReader(logLines), jsonlconv.ImportOptions{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	expected := []metafield.MetaInfoField{
+		{Name: `id`, Type: `Int16`},
+		{Name: `ok`, Type: `Bool`},
+		{Name: `score`, Type: `Double`},
+		{Name: `big`, Type: `Int64`},
+		{Name: `day`, Type: `Date`},
+		{Name: `at`, Type: `DateTime`},
+		{Name: `where`, Type: `SpatialObj`},
+		{Name: `tags`, Type: `V_WString`, Size: 9},
+		{Name: `msg`, Type: `V_WString`, Size: 5},
+		{Name: `mixed`, Type: `V_WString`, Size: 3},
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, fields)
+	}
+}
+
+func TestImport(t *testing.T) {
+	reader, result := importLines(t, logLines, jsonlconv.ImportOptions{})
+	if len(result.Errors) != 0 || result.Records != 3 {
+		t.Fatalf(`expected 3 records and no errors but got %v records and %v`, result.Records, result.Errors)
+	}
+	reader.Next()
+	moment := time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC)
+	checkValues(t, reader, map[string]any{
+		`id`:    int64(1),
+		`ok`:    true,
+		`score`: 0.5,
+		`day`:   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		`at`:    moment,
+		`tags`:  `["a","b"]`,
+		`msg`:   `héllo`,
+	})
+	if geo, _ := spatial.ToGeoJSON(reader.ReadBlobWithName(`where`)); geo != point {
+		t.Fatalf(`expected %v but got %v`, point, geo)
+	}
+	reader.Next()
+	// the time zone is converted to UTC
+	checkValues(t, reader, map[string]any{`at`: moment, `mixed`: `1`})
+	if _, isNull := reader.ReadValueWithName(`day`); !isNull {
+		t.Fatalf(`expected day to be null`)
+	}
+	reader.Next()
+	checkValues(t, reader, map[string]any{`id`: int64(3), `mixed`: `one`})
+	if _, isNull := reader.ReadValueWithName(`ok`); !isNull {
+		t.Fatalf(`expected missing members to be null`)
+	}
+}
+
+func TestImportFields(t *testing.T) {
+	data := "{\"id\":1,\"name\":\"abc\",\"extra\":true}\n[1]\n{\"id\":1.5,\"name\":\"abcdef\"}\n{\"id\":\"3\"\n"
+	options := jsonlconv.ImportOptions{Fields: []metafield.MetaInfoField{
+		{Name: `id`, Type: `Int32`},
+		{Name: `name`, Type: `String`, Size: 3},
+	}}
+	reader, result := importLines(t, data, options)
+	if result.Records != 2 || !reflect.DeepEqual(result.Fields, options.Fields) {
+		t.Fatalf(`expected 2 records with the supplied fields but got %v records with %v`, result.Records, result.Fields)
+	}
+	expected := []string{
+		`line 2: the line is not a JSON object`,
+		`line 3: field 'id' value '1.5': strconv.ParseInt: parsing "1.5": invalid syntax`,
+		`line 3: field 'name' value '"abcdef"': value of length 6 was truncated to the field size of 3`,
+		`line 4: unexpected end of JSON input`,
+	}
+	if len(result.Errors) != len(expected) {
+		t.Fatalf(`expected %v errors but got %v`, len(expected), result.Errors)
+	}
+	for index, err := range result.Errors {
+		if err.Error() != expected[index] {
+			t.Fatalf("expected error %v to be\n%v\nbut got\n%v", index, expected[index], err.Error())
+		}
+	}
+	reader.Next()
+	checkValues(t, reader, map[string]any{`id`: int64(1), `name`: `abc`})
+	reader.Next()
+	checkValues(t, reader, map[string]any{`name`: `abc`})
+	if _, isNull := reader.ReadValueWithName(`id`); !isNull {
+		t.Fatalf(`expected id to be null`)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	exported := convert(t, createTestFile(t))
+	path := filepath.Join(t.TempDir(), `imported.yxdb`)
+	result, err := jsonlconv.ImportFile(writeLines(t, exported), path, jsonlconv.ImportOptions{Fields: testFields})
+	if err != nil || len(result.Errors) != 0 {
+		t.Fatalf(`expected no errors but got %v and %v`, err, result.Errors)
+	}
+	if actual := convert(t, path); actual != exported {
+		t.Fatalf("expected\n%v\nbut got\n%v", exported, actual)
+	}
+}
+
+func writeLines(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), `lines.jsonl`)
+	err := os.WriteFile(path, []byte(data), 0644)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return path
+}
+
+func TestImportNegativeIntegers(t *testing.T) {
+	data := "{\"small\":-5,\"medium\":-70000}\n{\"small\":-32768,\"medium\":-2147483648}\n"
+	reader, result := importLines(t, data, jsonlconv.ImportOptions{})
+	if result.Fields[0].Type != `Int16` || result.Fields[1].Type != `Int32` {
+		t.Fatalf(`expected Int16 and Int32 fields but got %v`, result.Fields)
+	}
+	reader.Next()
+	checkValues(t, reader, map[string]any{`small`: int64(-5), `medium`: int64(-70000)})
+	reader.Next()
+	checkValues(t, reader, map[string]any{`small`: int64(-32768), `medium`: int64(-2147483648)})
+}
+
+func importLines(t *testing.T, data string, options jsonlconv.ImportOptions) (yx.Reader, *jsonlconv.ImportResult) {
+	path := filepath.Join(t.TempDir(), `import.yxdb`)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	result, err := jsonlconv.Import(strings.NewReader(data), file, options)
+	_ = file.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	reader, err := yx.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	t.Cleanup(func() { _ = reader.Close() })
+	return reader, result
+}
+
+func checkValues(t *testing.T, reader yx.Reader, expected map[string]any) {
+	for name, value := range expected {
+		actual, isNull := reader.ReadValueWithName(name)
+		if isNull || !reflect.DeepEqual(actual, value) {
+			t.Fatalf(`expected %v to be %v but got %v`, name, value, actual)
+		}
+	}
+}
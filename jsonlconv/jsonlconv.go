@@ -0,0 +1,222 @@
+// Package jsonlconv converts .yxdb files to and from JSON Lines, also known as newline-delimited JSON.
+//
+// Every record is a JSON object on its own line, with a member for each field in field order. Values are written as:
+//
+//	Bool                                  true or false
+//	Byte, Int16, Int32, Int64             numbers
+//	FixedDecimal                          numbers with the digits stored in the file
+//	Float, Double                         numbers, or null for NaN and infinities, which JSON cannot represent
+//	String, WString, V_String, V_WString  strings
+//	Date                                  ISO 8601 date strings such as "2024-02-29"
+//	DateTime                              ISO 8601 date time strings without a time zone such as "2024-02-29T13:45:30"
+//	Blob                                  base64 strings
+//	SpatialObj                            GeoJSON geometry objects
+//
+// Null values of every type are written as null.
+package jsonlconv
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/convert"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	dateLayout     = `2006-01-02`
+	dateTimeLayout = `2006-01-02T15:04:05`
+)
+
+// batchSize is the number of records read at a time by Convert.
+const batchSize = 4096
+
+// ConvertFile converts the .yxdb file at yxdbPath to a JSON Lines file at jsonlPath, replacing it if it exists.
+func ConvertFile(yxdbPath string, jsonlPath string) error {
+	return convert.File(yxdbPath, jsonlPath, Convert)
+}
+
+// Convert writes the remaining records of reader to w as JSON Lines. The members of each object are the fields of
+// the reader.
+func Convert(w io.Writer, reader yx.Reader) error {
+	writer, err := NewWriter(w, reader.ListFields())
+	if err != nil {
+		return err
+	}
+	return convert.Copy(writer, reader, func() int { return batchSize })
+}
+
+// A Writer writes batches of records read with Reader.NextBatch as JSON Lines.
+//
+// Output is buffered, so Close must be called after the last batch. Close does not close the underlying io.Writer.
+type Writer struct {
+	out      *bufio.Writer
+	keys     [][]byte
+	encoders []encoder
+	line     []byte
+	closed   bool
+
+	// records is the number of records written, for reporting the record that cannot be encoded.
+	records int64
+}
+
+// encoder appends the JSON value at row of a column, which is not null, to dst.
+type encoder func(dst []byte, column *yx.Column, row int) ([]byte, error)
+
+// NewWriter returns a Writer for batches with fields.
+func NewWriter(w io.Writer, fields []yxrecord.YxdbField) (*Writer, error) {
+	writer := &Writer{out: bufio.NewWriter(w)}
+	for index, field := range fields {
+		encode, err := newEncoder(field)
+		if err != nil {
+			return nil, err
+		}
+		key := []byte{'{'}
+		if index > 0 {
+			key[0] = ','
+		}
+		key = appendString(key, field.Name)
+		writer.keys = append(writer.keys, append(key, ':'))
+		writer.encoders = append(writer.encoders, encode)
+	}
+	return writer, nil
+}
+
+// Write writes the records of batch. The batch must have the fields passed to NewWriter.
+func (w *Writer) Write(batch *yx.Batch) error {
+	if w.closed {
+		return convert.ErrWriterClosed
+	}
+	for row := 0; row < batch.Len; row++ {
+		line := w.line[:0]
+		for index, encode := range w.encoders {
+			line = append(line, w.keys[index]...)
+			column := &batch.Columns[index]
+			if column.IsNull(row) {
+				line = append(line, `null`...)
+				continue
+			}
+			var err error
+			line, err = encode(line, column, row)
+			if err != nil {
+				return fmt.Errorf(`error writing field '%v' of record %v: %w`, column.Field.Name, w.records+1, err)
+			}
+		}
+		if len(w.encoders) == 0 {
+			line = append(line, '{')
+		}
+		line = append(line, '}', '\n')
+		w.line = line
+		_, err := w.out.Write(line)
+		if err != nil {
+			return err
+		}
+		w.records++
+	}
+	return nil
+}
+
+// Close writes any buffered records to the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.out.Flush()
+}
+
+func newEncoder(field yxrecord.YxdbField) (encoder, error) {
+	switch field.FieldType {
+	case yxrecord.TypeBool:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			return strconv.AppendBool(dst, c.Bools[row]), nil
+		}, nil
+	case yxrecord.TypeByte:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			return strconv.AppendUint(dst, uint64(c.Bytes[row]), 10), nil
+		}, nil
+	case yxrecord.TypeInt16, yxrecord.TypeInt32, yxrecord.TypeInt64:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			return strconv.AppendInt(dst, c.Int64s[row], 10), nil
+		}, nil
+	case yxrecord.TypeFixedDecimal:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			number, err := c.Decimals[row].MarshalJSON()
+			return append(dst, number...), err
+		}, nil
+	case yxrecord.TypeFloat:
+		return floatEncoder(32), nil
+	case yxrecord.TypeDouble:
+		return floatEncoder(64), nil
+	case yxrecord.TypeString, yxrecord.TypeWString, yxrecord.TypeV_String, yxrecord.TypeV_WString:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			return appendString(dst, c.Strings[row]), nil
+		}, nil
+	case yxrecord.TypeDate:
+		return timeEncoder(dateLayout), nil
+	case yxrecord.TypeDateTime:
+		return timeEncoder(dateTimeLayout), nil
+	case yxrecord.TypeBlob:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			dst = append(dst, '"')
+			dst = base64.StdEncoding.AppendEncode(dst, c.Blobs[row])
+			return append(dst, '"'), nil
+		}, nil
+	case yxrecord.TypeSpatialObj:
+		return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+			geometry, err := spatial.ToGeoJSON(c.Blobs[row])
+			return append(dst, geometry...), err
+		}, nil
+	default:
+		return nil, fmt.Errorf(`field '%v' has unsupported type %v`, field.Name, field.FieldType)
+	}
+}
+
+func floatEncoder(bitSize int) encoder {
+	return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+		value := c.Float64s[row]
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return append(dst, `null`...), nil
+		}
+		return strconv.AppendFloat(dst, value, 'g', -1, bitSize), nil
+	}
+}
+
+func timeEncoder(layout string) encoder {
+	return func(dst []byte, c *yx.Column, row int) ([]byte, error) {
+		dst = append(dst, '"')
+		dst = c.Times[row].AppendFormat(dst, layout)
+		return append(dst, '"'), nil
+	}
+}
+
+const hexDigits = `0123456789abcdef`
+
+// appendString appends value as a JSON string. Invalid UTF-8 is replaced with U+FFFD, and U+2028 and U+2029 are
+// escaped, as encoding/json does, so that the output is also valid JavaScript.
+func appendString(dst []byte, value string) []byte {
+	dst = append(dst, '"')
+	for _, char := range value {
+		switch {
+		case char == '"' || char == '\\':
+			dst = append(dst, '\\', byte(char))
+		case char == '\n':
+			dst = append(dst, '\\', 'n')
+		case char == '\r':
+			dst = append(dst, '\\', 'r')
+		case char == '\t':
+			dst = append(dst, '\\', 't')
+		case char < 0x20 || char == '\u2028' || char == '\u2029':
+			dst = append(dst, '\\', 'u', hexDigits[char>>12&0xf], hexDigits[char>>8&0xf], hexDigits[char>>4&0xf], hexDigits[char&0xf])
+		default:
+			dst = utf8.AppendRune(dst, char)
+		}
+	}
+	return append(dst, '"')
+}
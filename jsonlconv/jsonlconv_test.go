@@ -0,0 +1,139 @@
+package jsonlconv_test
+
+import (
+	"bytes"
+	"encoding/json"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/jsonlconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const point = `{"type":"Point","coordinates":[-96.679688,37.230328]}`
+
+var testFields = []metafield.MetaInfoField{
+	{Name: `Id`, Type: `Int32`},
+	{Name: `Flag`, Type: `Bool`},
+	{Name: `Amount`, Type: `FixedDecimal`, Size: 10, Scale: 2},
+	{Name: `Ratio`, Type: `Double`},
+	{Name: `Name`, Type: `V_WString`},
+	{Name: `Date`, Type: `Date`},
+	{Name: `DateTime`, Type: `DateTime`},
+	{Name: `Data`, Type: `Blob`},
+	{Name: `Spatial`, Type: `SpatialObj`},
+}
+
+func TestConvert(t *testing.T) {
+	path := createTestFile(t)
+	expected := `{"Id":1,"Flag":true,"Amount":-12.50,"Ratio":0.1,"Name":"Smith, \"Jr\"\n\u2028<&>","Date":"2024-02-29",` +
+		`"DateTime":"2024-02-29T13:45:30","Data":"AQL/","Spatial":` + point + "}\n" +
+		`{"Id":2,"Flag":null,"Amount":null,"Ratio":null,"Name":null,"Date":null,"DateTime":null,"Data":null,"Spatial":null}` + "\n"
+	if actual := convert(t, path); actual != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, actual)
+	}
+	// every line must be valid JSON
+	for _, line := range bytes.Split([]byte(expected), []byte("\n"))[:2] {
+		if !json.Valid(line) {
+			t.Fatalf(`expected valid JSON but got %v`, string(line))
+		}
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `out.jsonl`)
+	err := jsonlconv.ConvertFile(`../test_files/LotsOfRecords.yxdb`, path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	data, _ := os.ReadFile(path)
+	if lines := bytes.Count(data, []byte("\n")); lines != 100000 {
+		t.Fatalf(`expected 100000 lines but got %v`, lines)
+	}
+	if !bytes.HasSuffix(data, []byte(`{"RowCount":100000}`+"\n")) {
+		t.Fatalf(`expected the last line to have a value of 100000`)
+	}
+}
+
+func TestErrorReportsRecordNumber(t *testing.T) {
+	field := yxrecord.YxdbField{Name: `Amount`, Type: yxrecord.Float64, FieldType: yxrecord.TypeFixedDecimal, Size: 10, Scale: 2}
+	writer, err := jsonlconv.NewWriter(&bytes.Buffer{}, []yxrecord.YxdbField{field})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	valid := &yx.Batch{Len: 2, Columns: []yx.Column{{Field: field, Nulls: []uint64{0}, Decimals: []decimal.Decimal{{Text: `1.00`, Scale: 2}, {Text: `2.00`, Scale: 2}}}}}
+	if err = writer.Write(valid); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	invalid := &yx.Batch{Len: 1, Columns: []yx.Column{{Field: field, Nulls: []uint64{0}, Decimals: []decimal.Decimal{{Text: `x`, Scale: 2}}}}}
+	err = writer.Write(invalid)
+	if err == nil || !strings.Contains(err.Error(), `of record 3:`) {
+		t.Fatalf(`expected an error for record 3 but got %v`, err)
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	writer, err := jsonlconv.NewWriter(&bytes.Buffer{}, nil)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	_ = writer.Close()
+	if err = writer.Write(&yx.Batch{}); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf(`expected a second Close to return nil but got: %v`, err.Error())
+	}
+}
+
+// createTestFile writes a record with a value in every field followed by a record of nulls.
+func createTestFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), `test.yxdb`)
+	writer, err := yx.CreateFile(path, testFields)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	shape, err := spatial.FromGeoJSON(point)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	writer.WriteInt64WithIndex(0, 1)
+	writer.WriteBoolWithIndex(1, true)
+	writer.WriteFloat64WithIndex(2, -12.5)
+	writer.WriteFloat64WithIndex(3, 0.1)
+	writer.WriteStringWithIndex(4, "Smith, \"Jr\"\n\u2028<&>")
+	writer.WriteTimeWithIndex(5, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	writer.WriteTimeWithIndex(6, time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC))
+	writer.WriteBlobWithIndex(7, []byte{1, 2, 255})
+	writer.WriteBlobWithIndex(8, shape)
+	_ = writer.WriteRecord()
+	writer.WriteInt64WithIndex(0, 2)
+	writer.WriteFloat64WithIndex(3, math.NaN())
+	_ = writer.WriteRecord()
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return path
+}
+
+func convert(t *testing.T, path string) string {
+	reader, err := yx.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	var data bytes.Buffer
+	err = jsonlconv.Convert(&data, reader)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return data.String()
+}
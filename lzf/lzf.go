@@ -1,11 +1,17 @@
 package lzf
 
+const hashLog = 14
+const maxLiteral = 32
+const maxOffset = 1 << 13
+const maxReference = (1 << 8) + (1 << 3)
+
 type Lzf struct {
 	InBuffer  []byte
 	OutBuffer []byte
 	inIndex   int
 	outIndex  int
 	inLen     int
+	hashTable []int
 }
 
 func (l *Lzf) Decompress(length int) int {
@@ -29,6 +35,71 @@ func (l *Lzf) Decompress(length int) int {
 	return l.outIndex
 }
 
+// Compress compresses the first length bytes of InBuffer into OutBuffer and returns the number of bytes written.
+//
+// If the compressed data does not fit in OutBuffer, Compress returns 0. Callers should then store the block
+// uncompressed.
+func (l *Lzf) Compress(length int) int {
+	l.inLen = length
+	l.reset()
+	l.resetHashTable()
+
+	if l.inLen == 0 {
+		return 0
+	}
+
+	literals := 0
+	if !l.reserveLiteralLength() {
+		return 0
+	}
+	for l.inIndex < l.inLen-2 {
+		hash := l.hashAt(l.inIndex)
+		reference := l.hashTable[hash] - 1
+		l.hashTable[hash] = l.inIndex + 1
+
+		if reference >= 0 && l.inIndex-reference-1 < maxOffset && l.matches(reference) {
+			if l.outIndex+3 > len(l.OutBuffer) {
+				return 0
+			}
+			l.closeLiteralRun(literals)
+			literals = 0
+			l.writeBackReference(reference)
+			if !l.reserveLiteralLength() {
+				return 0
+			}
+			continue
+		}
+
+		if !l.copyLiteral() {
+			return 0
+		}
+		literals++
+		if literals == maxLiteral {
+			l.closeLiteralRun(literals)
+			literals = 0
+			if !l.reserveLiteralLength() {
+				return 0
+			}
+		}
+	}
+
+	for l.inIndex < l.inLen {
+		if !l.copyLiteral() {
+			return 0
+		}
+		literals++
+		if literals == maxLiteral {
+			l.closeLiteralRun(literals)
+			literals = 0
+			if !l.reserveLiteralLength() {
+				return 0
+			}
+		}
+	}
+	l.closeLiteralRun(literals)
+	return l.outIndex
+}
+
 func (l *Lzf) reset() {
 	l.inIndex = 0
 	l.outIndex = 0
@@ -75,6 +146,78 @@ func (l *Lzf) copyFromReferenceAndIncrement(reference int, size int) int {
 	return reference + size
 }
 
+func (l *Lzf) resetHashTable() {
+	if l.hashTable == nil {
+		l.hashTable = make([]int, 1<<hashLog)
+		return
+	}
+	for i := range l.hashTable {
+		l.hashTable[i] = 0
+	}
+}
+
+func (l *Lzf) hashAt(index int) int {
+	value := int(l.InBuffer[index])<<16 | int(l.InBuffer[index+1])<<8 | int(l.InBuffer[index+2])
+	return ((value >> (24 - hashLog)) - (value * 5)) & ((1 << hashLog) - 1)
+}
+
+func (l *Lzf) matches(reference int) bool {
+	return l.InBuffer[reference] == l.InBuffer[l.inIndex] &&
+		l.InBuffer[reference+1] == l.InBuffer[l.inIndex+1] &&
+		l.InBuffer[reference+2] == l.InBuffer[l.inIndex+2]
+}
+
+func (l *Lzf) writeBackReference(reference int) {
+	offset := l.inIndex - reference - 1
+	maxLength := min(l.inLen-l.inIndex, maxReference)
+	length := 3
+	for length < maxLength && l.InBuffer[reference+length] == l.InBuffer[l.inIndex+length] {
+		length++
+	}
+	l.inIndex += length
+
+	length -= 2
+	if length < 7 {
+		l.OutBuffer[l.outIndex] = byte((offset >> 8) + (length << 5))
+		l.outIndex++
+	} else {
+		l.OutBuffer[l.outIndex] = byte((offset >> 8) + (7 << 5))
+		l.OutBuffer[l.outIndex+1] = byte(length - 7)
+		l.outIndex += 2
+	}
+	l.OutBuffer[l.outIndex] = byte(offset)
+	l.outIndex++
+}
+
+func (l *Lzf) copyLiteral() bool {
+	if l.outIndex >= len(l.OutBuffer) {
+		return false
+	}
+	l.OutBuffer[l.outIndex] = l.InBuffer[l.inIndex]
+	l.outIndex++
+	l.inIndex++
+	return true
+}
+
+// reserveLiteralLength leaves room for the control byte of the next literal run.
+func (l *Lzf) reserveLiteralLength() bool {
+	if l.outIndex >= len(l.OutBuffer) {
+		return false
+	}
+	l.outIndex++
+	return true
+}
+
+// closeLiteralRun writes the control byte of the current literal run, or releases the reserved byte if the run is
+// empty.
+func (l *Lzf) closeLiteralRun(literals int) {
+	if literals == 0 {
+		l.outIndex--
+		return
+	}
+	l.OutBuffer[l.outIndex-literals-1] = byte(literals - 1)
+}
+
 func min(a int, b int) int {
 	if a < b {
 		return a
@@ -1,7 +1,9 @@
 package lzf_test
 
 import (
+	"bytes"
 	l "github.com/tlarsendataguy-yxdb/yxdb-go/lzf"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -80,6 +82,87 @@ func TestResetLzfAndStartAgain(t *testing.T) {
 	}
 }
 
+func TestCompressEmptyInput(t *testing.T) {
+	lzf := l.Lzf{InBuffer: []byte{}, OutBuffer: make([]byte, 10)}
+	if written := lzf.Compress(0); written != 0 {
+		t.Fatalf(`expected 0 written but got %v`, written)
+	}
+}
+
+func TestCompressRepeatedBytes(t *testing.T) {
+	inData := bytes.Repeat([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, 1000)
+	compressed := performRoundTrip(inData, t)
+	if compressed >= len(inData)/10 {
+		t.Fatalf(`expected repeated bytes to compress well but got %v bytes`, compressed)
+	}
+}
+
+func TestCompressShortInputs(t *testing.T) {
+	for _, inData := range [][]byte{{1}, {1, 2}, {1, 2, 3}, {1, 1, 1, 1}} {
+		performRoundTrip(inData, t)
+	}
+}
+
+func TestCompressRandomBytes(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, size := range []int{10, 100, 1000, 262144} {
+		inData := make([]byte, size)
+		random.Read(inData)
+		performRoundTrip(inData, t)
+	}
+}
+
+func TestCompressMixedBytes(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	inData := make([]byte, 0, 262144)
+	for len(inData) < 262144-300 {
+		if random.Intn(2) == 0 {
+			chunk := make([]byte, random.Intn(40))
+			random.Read(chunk)
+			inData = append(inData, chunk...)
+		} else {
+			start := random.Intn(len(inData) + 1)
+			end := min(len(inData), start+random.Intn(300))
+			inData = append(inData, inData[start:end]...)
+		}
+	}
+	performRoundTrip(inData, t)
+}
+
+func TestCompressOutputTooSmall(t *testing.T) {
+	inData := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	lzf := l.Lzf{InBuffer: inData, OutBuffer: make([]byte, 10)}
+	if written := lzf.Compress(len(inData)); written != 0 {
+		t.Fatalf(`expected 0 written but got %v`, written)
+	}
+}
+
+func performRoundTrip(inData []byte, t *testing.T) int {
+	compressed := make([]byte, len(inData)+(len(inData)/32)+1)
+	compressor := l.Lzf{InBuffer: inData, OutBuffer: compressed}
+	written := compressor.Compress(len(inData))
+	if written == 0 {
+		t.Fatalf(`expected compressed bytes but got none`)
+	}
+
+	outData := make([]byte, len(inData))
+	decompressor := l.Lzf{InBuffer: compressed, OutBuffer: outData}
+	if read := decompressor.Decompress(written); read != len(inData) {
+		t.Fatalf(`expected %v decompressed bytes but got %v`, len(inData), read)
+	}
+	if !reflect.DeepEqual(inData, outData) {
+		t.Fatalf(`decompressed bytes do not match the original input`)
+	}
+	return written
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func performTest(inData []byte, expected []byte, t *testing.T) {
 	outSize := len(expected)
 	outData := make([]byte, outSize)
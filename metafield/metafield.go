@@ -1,8 +1,16 @@
 package metafield
 
+import "encoding/xml"
+
+// MetaInfoField describes a field as it is declared in the MetaInfo XML of a .yxdb file.
 type MetaInfoField struct {
-	Name  string `xml:"name,attr"`
-	Type  string `xml:"type,attr"`
-	Size  int    `xml:"size,attr"`
-	Scale int    `xml:"scale,attr"`
+	Name        string `xml:"name,attr"`
+	Type        string `xml:"type,attr"`
+	Size        int    `xml:"size,attr"`
+	Scale       int    `xml:"scale,attr"`
+	Description string `xml:"description,attr"`
+	Source      string `xml:"source,attr"`
+
+	// OtherAttrs holds any attributes of the field that are not mapped to one of the fields above.
+	OtherAttrs []xml.Attr `xml:",any,attr"`
 }
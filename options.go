@@ -0,0 +1,72 @@
+package yxdb
+
+// An Option configures how a Reader reads a .yxdb file. Options are passed to ReadFile, ReadStream, and ReadReaderAt.
+type Option func(*options)
+
+type options struct {
+	pipelineBuffers int
+	columns         []string
+	predicates      []Predicate
+	filters         []func(Record) bool
+}
+
+// WithBackgroundDecompression reads and decompresses upcoming blocks of the file in a background goroutine while
+// records are being read, so that IO and decompression run on a different core than the code processing records.
+// buffers is the number of decompressed blocks, of 256 KB each, held in memory at once; values below 2 are raised
+// to 2.
+//
+// The background goroutine is stopped when the Reader is closed. Errors it encounters are reported by Err when the
+// reader reaches the failed block.
+func WithBackgroundDecompression(buffers int) Option {
+	return func(o *options) {
+		if buffers < 2 {
+			buffers = 2
+		}
+		o.pipelineBuffers = buffers
+	}
+}
+
+// WithColumns restricts the Reader to the named fields. ListFields, FieldInfo, and the WithIndex methods only see
+// these fields, indexed in the order they are listed; the other fields in the file are never decoded. MetaInfoStr
+// still returns the MetaInfo XML of the whole file. The Convert functions of the converter packages, such as
+// csvconv.Convert, write only these fields.
+//
+// If a name does not exist in the file, creating the Reader fails with an error wrapping ErrFieldNotFound.
+func WithColumns(names ...string) Option {
+	return func(o *options) {
+		o.columns = append([]string{}, names...)
+	}
+}
+
+// WithPredicate makes Next skip records that do not satisfy predicate. It may be passed more than once; records must
+// satisfy every predicate. Predicates may refer to any field in the file, including fields excluded by WithColumns.
+// NextBatch skips the same records, so the Convert functions of the converter packages write only the records that
+// satisfy every predicate.
+//
+// If the predicate refers to a field that does not exist or compares a field with an unsuitable value, creating the
+// Reader fails with an error wrapping ErrFieldNotFound or ErrTypeMismatch.
+func WithPredicate(predicate Predicate) Option {
+	return func(o *options) {
+		o.predicates = append(o.predicates, predicate)
+	}
+}
+
+// WithFilter makes Next skip records for which filter returns false. It may be passed more than once; records must
+// pass every filter, and filters are called after any predicates passed with WithPredicate.
+//
+// The Record passed to filter contains every field in the file, regardless of WithColumns, and is only valid until
+// filter returns. Predicates are faster for conditions they can express, since filter is called with a Record for
+// every record in the file.
+func WithFilter(filter func(Record) bool) Option {
+	return func(o *options) {
+		o.filters = append(o.filters, filter)
+	}
+}
+
+func newOptions(opts []Option) options {
+	result := options{}
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package yxdb
+
+import (
+	"github.com/tlarsendataguy-yxdb/yxdb-go/bufrecord"
+	"sync"
+	"sync/atomic"
+)
+
+// ParallelScan reads the .yxdb file at path using up to workers goroutines and calls scan once for every record.
+//
+// The file is split into contiguous ranges of record blocks using the record block index, and each goroutine opens
+// its own Reader to decompress and read one range. scan is called concurrently from different goroutines, and
+// records are not delivered in file order; within a range, records are delivered in order. Each Record is only
+// valid until scan returns; call its Snapshot method to keep it.
+//
+// Options are applied to the Reader of every goroutine, so WithPredicate and WithFilter limit the records passed to
+// scan. If scan returns an error or a range cannot be read, the remaining goroutines stop at their next record and
+// ParallelScan returns the error.
+func ParallelScan(path string, workers int, scan func(Record) error, opts ...Option) error {
+	reader, err := readFile(path, opts)
+	if err != nil {
+		return err
+	}
+	numRecords := reader.NumRecords()
+	err = reader.Close()
+	if err != nil {
+		return err
+	}
+
+	partitions := planPartitions(numRecords, workers)
+	errs := make([]error, len(partitions))
+	stopped := &atomic.Bool{}
+	wait := &sync.WaitGroup{}
+	for index, partition := range partitions {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			errs[index] = scanPartition(path, partition, scan, stopped, opts)
+			if errs[index] != nil {
+				stopped.Store(true)
+			}
+		}()
+	}
+	wait.Wait()
+	for _, err = range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partition is a range of records, starting at the first record of a block.
+type partition struct {
+	start int64
+	end   int64
+}
+
+// planPartitions splits numRecords into at most workers ranges of whole record blocks, sized as evenly as possible.
+func planPartitions(numRecords int64, workers int) []partition {
+	blocks := (numRecords + bufrecord.RecordsPerBlock - 1) / bufrecord.RecordsPerBlock
+	if workers < 1 {
+		workers = 1
+	}
+	if int64(workers) > blocks {
+		workers = int(blocks)
+	}
+	partitions := make([]partition, 0, workers)
+	startBlock := int64(0)
+	for worker := 0; worker < workers; worker++ {
+		endBlock := blocks * int64(worker+1) / int64(workers)
+		end := endBlock * bufrecord.RecordsPerBlock
+		if end > numRecords {
+			end = numRecords
+		}
+		partitions = append(partitions, partition{start: startBlock * bufrecord.RecordsPerBlock, end: end})
+		startBlock = endBlock
+	}
+	return partitions
+}
+
+func scanPartition(path string, p partition, scan func(Record) error, stopped *atomic.Bool, opts []Option) error {
+	yxdb, err := readFile(path, opts)
+	if err != nil {
+		return err
+	}
+	defer yxdb.Close()
+	err = yxdb.SeekRecord(p.start)
+	if err != nil {
+		return err
+	}
+	for recordNumber := p.start; recordNumber < p.end; recordNumber++ {
+		if stopped.Load() {
+			return nil
+		}
+		if !yxdb.recordReader.NextRecord() {
+			return yxdb.readError()
+		}
+		if !yxdb.matches() {
+			continue
+		}
+		err = scan(yxdb.currentRecord(yxdb.record))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package yxdb_test
+
+import (
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelScan(t *testing.T) {
+	path := tempPath(t, `Parallel.yxdb`)
+	writer, _ := yx.CreateFile(path, []metafield.MetaInfoField{{Name: `Id`, Type: `Int64`}})
+	for i := 0; i < 300000; i++ {
+		writer.WriteInt64WithIndex(0, int64(i))
+		_ = writer.WriteRecord()
+	}
+	_ = writer.Close()
+
+	seen := make([]int32, 300000)
+	err := yx.ParallelScan(path, 3, func(record yx.Record) error {
+		id, _ := record.ReadInt64WithName(`Id`)
+		atomic.AddInt32(&seen[id], 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Fatalf(`expected record %v to be scanned once but got %v`, id, count)
+		}
+	}
+}
+
+func TestParallelScanMoreWorkersThanBlocks(t *testing.T) {
+	sum := int64(0)
+	mutex := &sync.Mutex{}
+	err := yx.ParallelScan(getPath(`LotsOfRecords.yxdb`), 16, func(record yx.Record) error {
+		value, _ := record.ReadInt64WithIndex(0)
+		mutex.Lock()
+		sum += value
+		mutex.Unlock()
+		return nil
+	}, yx.WithBackgroundDecompression(2))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if sum != 5000050000 {
+		t.Fatalf(`expected 5000050000 but got %v`, sum)
+	}
+}
+
+func TestParallelScanStopsOnError(t *testing.T) {
+	stop := errors.New(`stop`)
+	calls := int64(0)
+	err := yx.ParallelScan(getPath(`LotsOfRecords.yxdb`), 2, func(record yx.Record) error {
+		if atomic.AddInt64(&calls, 1) == 10 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf(`expected the error returned by scan but got %v`, err)
+	}
+	if calls >= 100000 {
+		t.Fatalf(`expected scanning to stop early but got %v calls`, calls)
+	}
+}
+
+func TestParallelScanInvalidFile(t *testing.T) {
+	err := yx.ParallelScan(getPath(`invalid.txt`), 2, func(yx.Record) error { return nil })
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
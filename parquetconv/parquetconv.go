@@ -0,0 +1,474 @@
+// Package parquetconv converts .yxdb files to Apache Parquet.
+//
+// Every field becomes an optional column, mapped from its field type as follows:
+//
+//	Bool                                  BOOLEAN
+//	Byte                                  INT32 annotated as INTEGER(8, unsigned)
+//	Int16, Int32                          INT32 annotated as INTEGER(16, signed) and INTEGER(32, signed)
+//	Int64                                 INT64
+//	FixedDecimal                          FIXED_LEN_BYTE_ARRAY annotated as DECIMAL(size, scale)
+//	Float, Double                         FLOAT, DOUBLE
+//	String, WString, V_String, V_WString  BYTE_ARRAY annotated as STRING
+//	Date                                  INT32 annotated as DATE
+//	DateTime                              INT64 annotated as TIMESTAMP(MICROS) not adjusted to UTC
+//	Blob                                  BYTE_ARRAY
+//	SpatialObj                            BYTE_ARRAY holding WKB, described by GeoParquet "geo" metadata
+//
+// Records are written one row group at a time, so only a single row group is held in memory regardless of the size
+// of the file.
+package parquetconv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/convert"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"math"
+	"math/big"
+)
+
+// Compression is the codec used to compress the pages of a Parquet file.
+type Compression int
+
+const (
+	// Snappy is the default compression, and is fast to write and read.
+	Snappy Compression = iota
+
+	// Gzip produces smaller files than Snappy but is slower to write and read.
+	Gzip
+
+	// Uncompressed writes pages without compression.
+	Uncompressed
+)
+
+const (
+	// DefaultRowGroupSize is the number of records in each row group when Options.RowGroupSize is not set.
+	DefaultRowGroupSize = 131072
+
+	// DefaultPageSize is the number of records in each data page when Options.PageSize is not set.
+	DefaultPageSize = 8192
+)
+
+// Options configures a Writer, Convert, and ConvertFile.
+type Options struct {
+	// RowGroupSize is the maximum number of records in each row group. Values below 1 use DefaultRowGroupSize.
+	RowGroupSize int
+
+	// PageSize is the maximum number of records in each data page read by Convert. Values below 1 use
+	// DefaultPageSize.
+	PageSize int
+
+	Compression Compression
+}
+
+var magic = []byte(`PAR1`)
+
+// ConvertFile converts the .yxdb file at yxdbPath to a Parquet file at parquetPath, replacing it if it exists.
+func ConvertFile(yxdbPath string, parquetPath string, options Options) error {
+	return convert.File(yxdbPath, parquetPath, func(w io.Writer, reader yx.Reader) error {
+		return Convert(w, reader, options)
+	})
+}
+
+// Convert writes the remaining records of reader to w as a Parquet file. The columns of the file are the fields of
+// the reader.
+func Convert(w io.Writer, reader yx.Reader, options Options) error {
+	writer, err := NewWriter(w, reader.ListFields(), options)
+	if err != nil {
+		return err
+	}
+	return convert.Copy(writer, reader, func() int {
+		// end each page at the end of the row group so that row groups hold exactly RowGroupSize records
+		return min(writer.options.PageSize, writer.options.RowGroupSize-writer.rowGroupRows)
+	})
+}
+
+// A Writer writes batches of records read with Reader.NextBatch to a Parquet file.
+//
+// Each batch is written as one data page per column. Once the current row group holds RowGroupSize or more records,
+// it is written to the underlying io.Writer. Close must be called after the last batch to write the remaining
+// records and the file footer; it does not close the underlying io.Writer.
+type Writer struct {
+	out          io.Writer
+	written      int64
+	options      Options
+	columns      []column
+	chunks       []columnChunk
+	rowGroupRows int
+	rowGroups    []rowGroup
+	numRows      int64
+	closed       bool
+}
+
+// columnChunk holds the encoded pages of a column in the current row group.
+type columnChunk struct {
+	pages            []byte
+	uncompressedSize int64
+	numValues        int64
+}
+
+type columnChunkMetadata struct {
+	offset           int64
+	compressedSize   int64
+	uncompressedSize int64
+	numValues        int64
+}
+
+type rowGroup struct {
+	columns []columnChunkMetadata
+	numRows int64
+}
+
+// NewWriter writes the Parquet header to w and returns a Writer for batches with the specified fields.
+func NewWriter(w io.Writer, fields []yxrecord.YxdbField, options Options) (*Writer, error) {
+	if options.RowGroupSize < 1 {
+		options.RowGroupSize = DefaultRowGroupSize
+	}
+	if options.PageSize < 1 {
+		options.PageSize = DefaultPageSize
+	}
+	writer := &Writer{out: w, options: options, columns: make([]column, len(fields)), chunks: make([]columnChunk, len(fields))}
+	for index, field := range fields {
+		c, err := newColumn(field)
+		if err != nil {
+			return nil, err
+		}
+		writer.columns[index] = c
+	}
+	err := writer.write(magic)
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write adds batch to the current row group. The columns of batch must match the fields passed to NewWriter.
+func (w *Writer) Write(batch *yx.Batch) error {
+	if w.closed {
+		return convert.ErrWriterClosed
+	}
+	if len(batch.Columns) != len(w.columns) {
+		return fmt.Errorf(`expected a batch with %v columns but got %v`, len(w.columns), len(batch.Columns))
+	}
+	for index, c := range w.columns {
+		err := w.addPage(c, &batch.Columns[index], batch.Len, &w.chunks[index])
+		if err != nil {
+			return err
+		}
+	}
+	w.rowGroupRows += batch.Len
+	if w.rowGroupRows >= w.options.RowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// Close writes the current row group and the file footer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.rowGroupRows > 0 {
+		err := w.flushRowGroup()
+		if err != nil {
+			return err
+		}
+	}
+	footer := w.fileMetadata()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return w.write(append(footer, magic...))
+}
+
+func (w *Writer) addPage(c column, values *yx.Column, length int, chunk *columnChunk) error {
+	page := definitionLevels(values, length)
+	page, err := c.appendValues(page, values, length)
+	if err != nil {
+		return err
+	}
+	compressed, err := w.compress(page)
+	if err != nil {
+		return err
+	}
+	if len(compressed) > math.MaxInt32 || len(page) > math.MaxInt32 {
+		return fmt.Errorf(`field '%v' holds more than 2 GB in one page; use a smaller page size`, c.field.Name)
+	}
+
+	header := &thriftWriter{}
+	header.i32(1, 0)
+	header.i32(2, int32(len(page)))
+	header.i32(3, int32(len(compressed)))
+	header.beginStruct(5)
+	header.i32(1, int32(length))
+	header.i32(2, encodingPlain)
+	header.i32(3, encodingRLE)
+	header.i32(4, encodingRLE)
+	header.endStruct()
+	encodedHeader := header.finish()
+
+	chunk.pages = append(chunk.pages, encodedHeader...)
+	chunk.pages = append(chunk.pages, compressed...)
+	chunk.uncompressedSize += int64(len(encodedHeader) + len(page))
+	chunk.numValues += int64(length)
+	return nil
+}
+
+func (w *Writer) compress(page []byte) ([]byte, error) {
+	switch w.options.Compression {
+	case Snappy:
+		return snappyEncode(page), nil
+	case Gzip:
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		_, err := writer.Write(page)
+		if err == nil {
+			err = writer.Close()
+		}
+		return compressed.Bytes(), err
+	default:
+		return page, nil
+	}
+}
+
+// codec returns the CompressionCodec of parquet.thrift for the compression of the writer.
+func (w *Writer) codec() int32 {
+	switch w.options.Compression {
+	case Snappy:
+		return 1
+	case Gzip:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (w *Writer) flushRowGroup() error {
+	group := rowGroup{columns: make([]columnChunkMetadata, len(w.chunks)), numRows: int64(w.rowGroupRows)}
+	for index := range w.chunks {
+		chunk := &w.chunks[index]
+		group.columns[index] = columnChunkMetadata{
+			offset:           w.written,
+			compressedSize:   int64(len(chunk.pages)),
+			uncompressedSize: chunk.uncompressedSize,
+			numValues:        chunk.numValues,
+		}
+		err := w.write(chunk.pages)
+		if err != nil {
+			return err
+		}
+		*chunk = columnChunk{pages: chunk.pages[:0]}
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.numRows += int64(w.rowGroupRows)
+	w.rowGroupRows = 0
+	return nil
+}
+
+// fileMetadata encodes the FileMetaData struct of the footer.
+func (w *Writer) fileMetadata() []byte {
+	t := &thriftWriter{}
+	t.i32(1, 1)
+	t.structList(2, len(w.columns)+1, func(index int) {
+		if index == 0 {
+			t.string(4, `schema`)
+			t.i32(5, int32(len(w.columns)))
+			return
+		}
+		w.columns[index-1].writeSchemaElement(t)
+	})
+	t.i64(3, w.numRows)
+	t.structList(4, len(w.rowGroups), func(index int) {
+		group := w.rowGroups[index]
+		var totalByteSize, totalCompressedSize int64
+		t.structList(1, len(group.columns), func(index int) {
+			chunk := group.columns[index]
+			totalByteSize += chunk.uncompressedSize
+			totalCompressedSize += chunk.compressedSize
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, w.columns[index].physicalType)
+			t.i32List(2, []int32{encodingPlain, encodingRLE})
+			t.stringList(3, []string{w.columns[index].field.Name})
+			t.i32(4, w.codec())
+			t.i64(5, chunk.numValues)
+			t.i64(6, chunk.uncompressedSize)
+			t.i64(7, chunk.compressedSize)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+		})
+		t.i64(2, totalByteSize)
+		t.i64(3, group.numRows)
+		if len(group.columns) > 0 {
+			t.i64(5, group.columns[0].offset)
+		}
+		t.i64(6, totalCompressedSize)
+	})
+	if geo := geoParquetMetadata(w.columns); geo != `` {
+		t.structList(5, 1, func(int) {
+			t.string(1, `geo`)
+			t.string(2, geo)
+		})
+	}
+	t.string(6, `yxdb-go`)
+	return t.finish()
+}
+
+func (w *Writer) write(data []byte) error {
+	n, err := w.out.Write(data)
+	w.written += int64(n)
+	return err
+}
+
+// definitionLevels encodes the definition levels of a page, prefixed with their length, using the RLE/bit-packed
+// hybrid encoding. A page without nulls is a single run of 1s; otherwise every level is bit-packed.
+func definitionLevels(values *yx.Column, length int) []byte {
+	levels := make([]byte, 4, 4+length/8+16)
+	if !values.HasNulls() {
+		levels = binary.AppendUvarint(levels, uint64(length)<<1)
+		levels = append(levels, 1)
+	} else {
+		groups := (length + 7) / 8
+		levels = binary.AppendUvarint(levels, uint64(groups)<<1|1)
+		packed := make([]byte, groups)
+		for index := 0; index < length; index++ {
+			if !values.IsNull(index) {
+				packed[index/8] |= 1 << (index % 8)
+			}
+		}
+		levels = append(levels, packed...)
+	}
+	binary.LittleEndian.PutUint32(levels, uint32(len(levels)-4))
+	return levels
+}
+
+// appendValues appends the non-null values of a page to page in the PLAIN encoding.
+func (c column) appendValues(page []byte, values *yx.Column, length int) ([]byte, error) {
+	switch c.field.FieldType {
+	case yxrecord.TypeBool:
+		packed := make([]byte, (length+7)/8)
+		count := 0
+		for index, value := range values.Bools {
+			if values.IsNull(index) {
+				continue
+			}
+			if value {
+				packed[count/8] |= 1 << (count % 8)
+			}
+			count++
+		}
+		return append(page, packed[:(count+7)/8]...), nil
+	case yxrecord.TypeByte:
+		for index, value := range values.Bytes {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint32(page, uint32(value))
+			}
+		}
+	case yxrecord.TypeInt16, yxrecord.TypeInt32:
+		for index, value := range values.Int64s {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint32(page, uint32(value))
+			}
+		}
+	case yxrecord.TypeInt64:
+		for index, value := range values.Int64s {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint64(page, uint64(value))
+			}
+		}
+	case yxrecord.TypeFixedDecimal:
+		for index, value := range values.Decimals {
+			if values.IsNull(index) {
+				continue
+			}
+			start := len(page)
+			page = append(page, make([]byte, c.typeLength)...)
+			unscaled, err := value.Unscaled()
+			if err == nil {
+				err = putDecimal(page[start:], unscaled)
+			}
+			if err != nil {
+				return nil, fmt.Errorf(`field '%v': %w`, c.field.Name, err)
+			}
+		}
+	case yxrecord.TypeFloat:
+		for index, value := range values.Float64s {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint32(page, math.Float32bits(float32(value)))
+			}
+		}
+	case yxrecord.TypeDouble:
+		for index, value := range values.Float64s {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint64(page, math.Float64bits(value))
+			}
+		}
+	case yxrecord.TypeDate:
+		for index, value := range values.Times {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint32(page, uint32(floorDiv(value.Unix(), 86400)))
+			}
+		}
+	case yxrecord.TypeDateTime:
+		for index, value := range values.Times {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint64(page, uint64(value.UnixMicro()))
+			}
+		}
+	case yxrecord.TypeBlob:
+		for index, value := range values.Blobs {
+			if !values.IsNull(index) {
+				page = appendByteArray(page, value)
+			}
+		}
+	case yxrecord.TypeSpatialObj:
+		for index, value := range values.Blobs {
+			if values.IsNull(index) {
+				continue
+			}
+			wkb, err := spatial.ToWKB(value)
+			if err != nil {
+				return nil, fmt.Errorf(`field '%v': %w`, c.field.Name, err)
+			}
+			page = appendByteArray(page, wkb)
+		}
+	default:
+		for index, value := range values.Strings {
+			if !values.IsNull(index) {
+				page = binary.LittleEndian.AppendUint32(page, uint32(len(value)))
+				page = append(page, value...)
+			}
+		}
+	}
+	return page, nil
+}
+
+func appendByteArray(page []byte, value []byte) []byte {
+	page = binary.LittleEndian.AppendUint32(page, uint32(len(value)))
+	return append(page, value...)
+}
+
+// putDecimal writes value into dst as a big-endian two's complement integer, as Parquet stores decimals.
+func putDecimal(dst []byte, value *big.Int) error {
+	bits := uint(len(dst) * 8)
+	if value.BitLen() >= int(bits) {
+		return fmt.Errorf(`decimal %v does not fit in %v bytes`, value, len(dst))
+	}
+	if value.Sign() < 0 {
+		value = new(big.Int).Add(value, new(big.Int).Lsh(big.NewInt(1), bits))
+	}
+	value.FillBytes(dst)
+	return nil
+}
+
+func floorDiv(value int64, divisor int64) int64 {
+	quotient := value / divisor
+	if value%divisor < 0 {
+		quotient--
+	}
+	return quotient
+}
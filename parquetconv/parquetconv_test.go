@@ -0,0 +1,194 @@
+package parquetconv_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/parquetconv"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertUncompressed(t *testing.T) {
+	data := convert(t, `../test_files/LotsOfRecords.yxdb`, parquetconv.Options{Compression: parquetconv.Uncompressed, PageSize: 10000})
+	if !bytes.HasPrefix(data, []byte(`PAR1`)) || !bytes.HasSuffix(data, []byte(`PAR1`)) {
+		t.Fatalf(`expected the file to start and end with PAR1`)
+	}
+	page := readPage(t, data, 4)
+	if page.uncompressedSize != page.compressedSize || len(page.payload) != 8+40000 {
+		t.Fatalf(`expected an uncompressed page of 40008 bytes but got %v bytes`, len(page.payload))
+	}
+	// a single run of 10000 definition levels of 1, followed by the values
+	if !bytes.Equal(page.payload[:8], []byte{4, 0, 0, 0, 0xa0, 0x9c, 1, 1}) {
+		t.Fatalf(`unexpected definition levels %v`, page.payload[:8])
+	}
+	for index := 0; index < 10000; index++ {
+		value := binary.LittleEndian.Uint32(page.payload[8+4*index:])
+		if value != uint32(index+1) {
+			t.Fatalf(`expected value %v to be %v but got %v`, index, index+1, value)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	path := `../test_files/LotsOfRecords.yxdb`
+	uncompressed := convert(t, path, parquetconv.Options{Compression: parquetconv.Uncompressed})
+	expected := readPage(t, uncompressed, 4).payload
+
+	page := readPage(t, convert(t, path, parquetconv.Options{Compression: parquetconv.Snappy}), 4)
+	if actual := snappyDecode(t, page.payload); !bytes.Equal(actual, expected) || page.uncompressedSize != len(expected) {
+		t.Fatalf(`expected the snappy page to decompress to the uncompressed page`)
+	}
+
+	page = readPage(t, convert(t, path, parquetconv.Options{Compression: parquetconv.Gzip}), 4)
+	reader, err := gzip.NewReader(bytes.NewReader(page.payload))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if actual, _ := io.ReadAll(reader); !bytes.Equal(actual, expected) {
+		t.Fatalf(`expected the gzip page to decompress to the uncompressed page`)
+	}
+}
+
+func TestNulls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `nulls.yxdb`)
+	writer, err := yx.CreateFile(path, []metafield.MetaInfoField{
+		{Name: `Id`, Type: `Int32`},
+		{Name: `Name`, Type: `V_WString`},
+	})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	writer.WriteInt64WithIndex(0, 7)
+	writer.WriteStringWithIndex(1, `seven`)
+	_ = writer.WriteRecord()
+	_ = writer.WriteRecord()
+	_ = writer.Close()
+
+	data := convert(t, path, parquetconv.Options{Compression: parquetconv.Uncompressed})
+	id := readPage(t, data, 4)
+	// one bit-packed group of definition levels in which only the first record is defined
+	expected := []byte{2, 0, 0, 0, 3, 1, 7, 0, 0, 0}
+	if !bytes.Equal(id.payload, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, id.payload)
+	}
+	name := readPage(t, data, id.next)
+	expected = []byte{2, 0, 0, 0, 3, 1, 5, 0, 0, 0, 's', 'e', 'v', 'e', 'n'}
+	if !bytes.Equal(name.payload, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, name.payload)
+	}
+}
+
+func TestGeoParquetMetadata(t *testing.T) {
+	data := convert(t, `../test_files/point.yxdb`, parquetconv.Options{})
+	if !bytes.Contains(data, []byte(`{"version":"1.1.0","primary_column":"Spatial"`)) {
+		t.Fatalf(`expected GeoParquet metadata for the Spatial field`)
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `out.parquet`)
+	err := parquetconv.ConvertFile(`../test_files/AllNormalFields.yxdb`, path, parquetconv.Options{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.HasSuffix(data, []byte(`PAR1`)) || !bytes.Contains(data, []byte(`V_WStringLongField`)) {
+		t.Fatalf(`expected a parquet file with the fields of AllNormalFields.yxdb`)
+	}
+}
+
+func convert(t *testing.T, path string, options parquetconv.Options) []byte {
+	reader, err := yx.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	var data bytes.Buffer
+	err = parquetconv.Convert(&data, reader, options)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return data.Bytes()
+}
+
+type page struct {
+	uncompressedSize int
+	compressedSize   int
+	payload          []byte
+	next             int
+}
+
+// readPage reads the page starting at offset. The PageHeader is decoded from the Thrift compact protocol just far
+// enough to find the sizes of the page.
+func readPage(t *testing.T, data []byte, offset int) page {
+	var p page
+	position := offset
+	id := 0
+	for data[position] != 0 {
+		header := data[position]
+		position++
+		id += int(header >> 4)
+		switch header & 0x0f {
+		case 5:
+			value, n := binary.Uvarint(data[position:])
+			position += n
+			if id == 2 {
+				p.uncompressedSize = int(value >> 1)
+			} else if id == 3 {
+				p.compressedSize = int(value >> 1)
+			}
+		case 12:
+			// the DataPageHeader only holds i32 fields
+			for data[position] != 0 {
+				_, n := binary.Uvarint(data[position+1:])
+				position += 1 + n
+			}
+			position++
+		default:
+			t.Fatalf(`unexpected field type %v in the page header`, header&0x0f)
+		}
+	}
+	position++
+	p.payload = data[position : position+p.compressedSize]
+	p.next = position + p.compressedSize
+	return p
+}
+
+// snappyDecode decompresses a Snappy block, supporting the literals and 2 byte offset copies written by parquetconv.
+func snappyDecode(t *testing.T, src []byte) []byte {
+	length, n := binary.Uvarint(src)
+	dst := make([]byte, 0, length)
+	for position := n; position < len(src); {
+		tag := src[position]
+		switch tag & 3 {
+		case 0:
+			size := int(tag>>2) + 1
+			position++
+			if size > 60 {
+				extra := size - 60
+				size = 1
+				for index := 0; index < extra; index++ {
+					size += int(src[position+index]) << (8 * index)
+				}
+				position += extra
+			}
+			dst = append(dst, src[position:position+size]...)
+			position += size
+		case 2:
+			size := int(tag>>2) + 1
+			offset := int(binary.LittleEndian.Uint16(src[position+1:]))
+			for index := 0; index < size; index++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+			position += 3
+		default:
+			t.Fatalf(`unexpected snappy tag %v`, tag)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,185 @@
+package parquetconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"math"
+)
+
+// Physical types of parquet.thrift.
+const (
+	typeBoolean           = 0
+	typeInt32             = 1
+	typeInt64             = 2
+	typeFloat             = 4
+	typeDouble            = 5
+	typeByteArray         = 6
+	typeFixedLenByteArray = 7
+)
+
+// Converted types of parquet.thrift, written alongside logical types for older readers.
+const (
+	convertedUTF8    = 0
+	convertedDecimal = 5
+	convertedDate    = 6
+	convertedUint8   = 11
+	convertedInt16   = 16
+	convertedInt32   = 17
+	convertedInt64   = 18
+)
+
+// Members of the LogicalType union of parquet.thrift.
+const (
+	logicalString    = 1
+	logicalDecimal   = 5
+	logicalDate      = 6
+	logicalTimestamp = 8
+	logicalInteger   = 10
+)
+
+const (
+	repetitionOptional = 1
+	timeUnitMicros     = 2
+	encodingPlain      = 0
+	encodingRLE        = 3
+)
+
+// column describes how a field is stored in Parquet.
+type column struct {
+	field        yxrecord.YxdbField
+	physicalType int32
+	typeLength   int
+	precision    int
+}
+
+func newColumn(field yxrecord.YxdbField) (column, error) {
+	c := column{field: field}
+	switch field.FieldType {
+	case yxrecord.TypeBool:
+		c.physicalType = typeBoolean
+	case yxrecord.TypeByte, yxrecord.TypeInt16, yxrecord.TypeInt32, yxrecord.TypeDate:
+		c.physicalType = typeInt32
+	case yxrecord.TypeInt64, yxrecord.TypeDateTime:
+		c.physicalType = typeInt64
+	case yxrecord.TypeFixedDecimal:
+		c.physicalType = typeFixedLenByteArray
+		c.precision = max(field.Size, field.Scale, 1)
+		c.typeLength = decimalLength(c.precision)
+	case yxrecord.TypeFloat:
+		c.physicalType = typeFloat
+	case yxrecord.TypeDouble:
+		c.physicalType = typeDouble
+	case yxrecord.TypeString, yxrecord.TypeWString, yxrecord.TypeV_String, yxrecord.TypeV_WString,
+		yxrecord.TypeBlob, yxrecord.TypeSpatialObj:
+		c.physicalType = typeByteArray
+	default:
+		return c, fmt.Errorf(`field '%v' has unsupported type %v`, field.Name, field.FieldType)
+	}
+	return c, nil
+}
+
+// decimalLength returns the smallest number of bytes that holds every unscaled value of a decimal with precision
+// digits as a signed two's complement integer.
+func decimalLength(precision int) int {
+	length := 1
+	for float64(precision) > math.Floor(float64(8*length-1)*math.Log10(2)) {
+		length++
+	}
+	return length
+}
+
+// writeSchemaElement writes the SchemaElement struct of the column.
+func (c column) writeSchemaElement(w *thriftWriter) {
+	w.i32(1, c.physicalType)
+	if c.typeLength > 0 {
+		w.i32(2, int32(c.typeLength))
+	}
+	w.i32(3, repetitionOptional)
+	w.string(4, c.field.Name)
+	switch c.field.FieldType {
+	case yxrecord.TypeByte:
+		w.i32(6, convertedUint8)
+		writeIntLogicalType(w, 8, false)
+	case yxrecord.TypeInt16:
+		w.i32(6, convertedInt16)
+		writeIntLogicalType(w, 16, true)
+	case yxrecord.TypeInt32:
+		w.i32(6, convertedInt32)
+		writeIntLogicalType(w, 32, true)
+	case yxrecord.TypeInt64:
+		w.i32(6, convertedInt64)
+		writeIntLogicalType(w, 64, true)
+	case yxrecord.TypeFixedDecimal:
+		w.i32(6, convertedDecimal)
+		w.i32(7, int32(c.field.Scale))
+		w.i32(8, int32(c.precision))
+		w.beginStruct(10)
+		w.beginStruct(logicalDecimal)
+		w.i32(1, int32(c.field.Scale))
+		w.i32(2, int32(c.precision))
+		w.endStruct()
+		w.endStruct()
+	case yxrecord.TypeString, yxrecord.TypeWString, yxrecord.TypeV_String, yxrecord.TypeV_WString:
+		w.i32(6, convertedUTF8)
+		w.beginStruct(10)
+		w.emptyStruct(logicalString)
+		w.endStruct()
+	case yxrecord.TypeDate:
+		w.i32(6, convertedDate)
+		w.beginStruct(10)
+		w.emptyStruct(logicalDate)
+		w.endStruct()
+	case yxrecord.TypeDateTime:
+		// Alteryx date times have no time zone, which no converted type can express
+		w.beginStruct(10)
+		w.beginStruct(logicalTimestamp)
+		w.bool(1, false)
+		w.beginStruct(2)
+		w.emptyStruct(timeUnitMicros)
+		w.endStruct()
+		w.endStruct()
+		w.endStruct()
+	}
+}
+
+func writeIntLogicalType(w *thriftWriter, bitWidth int8, signed bool) {
+	w.beginStruct(10)
+	w.beginStruct(logicalInteger)
+	w.i8(1, bitWidth)
+	w.bool(2, signed)
+	w.endStruct()
+	w.endStruct()
+}
+
+type geoMetadata struct {
+	Version       string               `json:"version"`
+	PrimaryColumn string               `json:"primary_column"`
+	Columns       map[string]geoColumn `json:"columns"`
+}
+
+type geoColumn struct {
+	Encoding      string   `json:"encoding"`
+	GeometryTypes []string `json:"geometry_types"`
+}
+
+// geoParquetMetadata returns the GeoParquet "geo" file metadata describing the SpatialObj fields, which are written
+// as WKB, or an empty string if there are none. Alteryx spatial objects use longitude/latitude on WGS 84, which is
+// the GeoParquet default.
+func geoParquetMetadata(columns []column) string {
+	geo := geoMetadata{Version: `1.1.0`, Columns: map[string]geoColumn{}}
+	for _, c := range columns {
+		if c.field.FieldType != yxrecord.TypeSpatialObj {
+			continue
+		}
+		if geo.PrimaryColumn == `` {
+			geo.PrimaryColumn = c.field.Name
+		}
+		geo.Columns[c.field.Name] = geoColumn{Encoding: `WKB`, GeometryTypes: []string{}}
+	}
+	if geo.PrimaryColumn == `` {
+		return ``
+	}
+	raw, _ := json.Marshal(geo)
+	return string(raw)
+}
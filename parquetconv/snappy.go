@@ -0,0 +1,74 @@
+package parquetconv
+
+import "encoding/binary"
+
+const (
+	snappyHashBits  = 14
+	snappyMaxOffset = 1<<16 - 1
+)
+
+// snappyEncode compresses src into the Snappy block format used by Parquet.
+//
+// Matches of at least 4 bytes are found with a hash table of the most recent position of each 4 byte sequence and
+// written as copies with 2 byte offsets; everything else is written as literals.
+func snappyEncode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)+len(src)/6+16), uint64(len(src)))
+	var table [1 << snappyHashBits]int32
+	emitted := 0
+	position := 0
+	for position+4 <= len(src) {
+		sequence := binary.LittleEndian.Uint32(src[position:])
+		hash := (sequence * 0x1e35a7bd) >> (32 - snappyHashBits)
+		candidate := int(table[hash]) - 1
+		table[hash] = int32(position + 1)
+		if candidate < 0 || position-candidate > snappyMaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != sequence {
+			position++
+			continue
+		}
+		length := 4
+		for position+length < len(src) && src[candidate+length] == src[position+length] {
+			length++
+		}
+		dst = appendSnappyLiteral(dst, src[emitted:position])
+		dst = appendSnappyCopy(dst, position-candidate, length)
+		position += length
+		emitted = position
+	}
+	return appendSnappyLiteral(dst, src[emitted:])
+}
+
+func appendSnappyLiteral(dst []byte, literal []byte) []byte {
+	if len(literal) == 0 {
+		return dst
+	}
+	n := len(literal) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n<<2))
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
+
+// appendSnappyCopy writes a match as copies of at most 64 bytes, keeping every copy at least 4 bytes long.
+func appendSnappyCopy(dst []byte, offset int, length int) []byte {
+	for length > 0 {
+		size := length
+		if size > 64 {
+			size = 64
+			if length-size < 4 {
+				size = 60
+			}
+		}
+		dst = append(dst, byte((size-1)<<2)|2, byte(offset), byte(offset>>8))
+		length -= size
+	}
+	return dst
+}
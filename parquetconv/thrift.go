@@ -0,0 +1,139 @@
+package parquetconv
+
+import "encoding/binary"
+
+// Parquet metadata is encoded with the Thrift compact protocol. Only the parts of the protocol needed to write the
+// page headers and file metadata are implemented here rather than depending on the Thrift library.
+
+// Types of the Thrift compact protocol.
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter appends a Thrift compact protocol encoding to bytes. Fields must be written in increasing id order
+// within each struct.
+type thriftWriter struct {
+	bytes   []byte
+	lastIDs []int
+	lastID  int
+}
+
+func (w *thriftWriter) fieldHeader(id int, fieldType byte) {
+	delta := id - w.lastID
+	if delta > 0 && delta <= 15 {
+		w.bytes = append(w.bytes, byte(delta<<4)|fieldType)
+	} else {
+		w.bytes = append(w.bytes, fieldType)
+		w.varint(zigzag(int64(id)))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) varint(value uint64) {
+	w.bytes = binary.AppendUvarint(w.bytes, value)
+}
+
+func zigzag(value int64) uint64 {
+	return uint64((value << 1) ^ (value >> 63))
+}
+
+func (w *thriftWriter) i32(id int, value int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(zigzag(int64(value)))
+}
+
+func (w *thriftWriter) i64(id int, value int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(zigzag(value))
+}
+
+func (w *thriftWriter) i8(id int, value int8) {
+	w.fieldHeader(id, thriftByte)
+	w.bytes = append(w.bytes, byte(value))
+}
+
+func (w *thriftWriter) bool(id int, value bool) {
+	if value {
+		w.fieldHeader(id, thriftTrue)
+	} else {
+		w.fieldHeader(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) string(id int, value string) {
+	w.fieldHeader(id, thriftBinary)
+	w.varint(uint64(len(value)))
+	w.bytes = append(w.bytes, value...)
+}
+
+// beginStruct starts a struct field; the struct's own fields follow until endStruct.
+func (w *thriftWriter) beginStruct(id int) {
+	w.fieldHeader(id, thriftStruct)
+	w.pushStruct()
+}
+
+func (w *thriftWriter) pushStruct() {
+	w.lastIDs = append(w.lastIDs, w.lastID)
+	w.lastID = 0
+}
+
+// endStruct writes the stop field of the current struct.
+func (w *thriftWriter) endStruct() {
+	w.bytes = append(w.bytes, 0)
+	w.lastID = w.lastIDs[len(w.lastIDs)-1]
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+// finish writes the stop field of the outermost struct and returns the encoding.
+func (w *thriftWriter) finish() []byte {
+	return append(w.bytes, 0)
+}
+
+// emptyStruct writes a struct field without any fields, as used by the members of Thrift unions such as
+// LogicalType.
+func (w *thriftWriter) emptyStruct(id int) {
+	w.beginStruct(id)
+	w.endStruct()
+}
+
+func (w *thriftWriter) listHeader(id int, elementType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < 15 {
+		w.bytes = append(w.bytes, byte(size<<4)|elementType)
+		return
+	}
+	w.bytes = append(w.bytes, 0xf0|elementType)
+	w.varint(uint64(size))
+}
+
+func (w *thriftWriter) i32List(id int, values []int32) {
+	w.listHeader(id, thriftI32, len(values))
+	for _, value := range values {
+		w.varint(zigzag(int64(value)))
+	}
+}
+
+func (w *thriftWriter) stringList(id int, values []string) {
+	w.listHeader(id, thriftBinary, len(values))
+	for _, value := range values {
+		w.varint(uint64(len(value)))
+		w.bytes = append(w.bytes, value...)
+	}
+}
+
+// structList writes a list of size structs, calling write to write the fields of each.
+func (w *thriftWriter) structList(id int, size int, write func(index int)) {
+	w.listHeader(id, thriftStruct, size)
+	for index := 0; index < size; index++ {
+		w.pushStruct()
+		write(index)
+		w.endStruct()
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/binary"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"github.com/tlarsendataguy-yxdb/yxdb-go/bufrecord"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
 	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
 	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
 	"io"
+	"iter"
+	"math"
 	"os"
 	"reflect"
 	"time"
@@ -16,6 +20,18 @@ import (
 	"unsafe"
 )
 
+// ErrFieldNotFound is returned by the TryReadXxx methods when a field name or index does not exist.
+var ErrFieldNotFound = yxrecord.ErrFieldNotFound
+
+// ErrTypeMismatch is returned by the TryReadXxx methods when a field cannot be read as the requested type.
+var ErrTypeMismatch = yxrecord.ErrTypeMismatch
+
+// ErrTruncated is reported by Reader.Err when a .yxdb file ends before all of its records have been read.
+var ErrTruncated = bufrecord.ErrTruncated
+
+// ErrCorruptBlock is reported by Reader.Err when a compressed block in a .yxdb file cannot be read.
+var ErrCorruptBlock = bufrecord.ErrCorruptBlock
+
 type metaInfo struct {
 	Fields           []metafield.MetaInfoField `xml:"Field"`
 	RecordInfoFields []metafield.MetaInfoField `xml:"RecordInfo>Field"`
@@ -30,16 +46,50 @@ type Reader interface {
 	// ListFields returns the list of fields contained in the .yxdb file and their data type.
 	ListFields() []yxrecord.YxdbField
 
+	// FieldInfo returns the full metadata of the fields contained in the .yxdb file, as declared in the MetaInfo XML.
+	//
+	// Unlike ListFields, FieldInfo reports the original Alteryx field type along with the size, scale, description,
+	// source, and any other attributes of each field.
+	FieldInfo() []metafield.MetaInfoField
+
 	// Next iterates through the records in a .yxdb file, returning true if there are more records and false if
-	// all records have been read.
+	// all records have been read or an error occurred. Call Err to distinguish between the two.
+	//
+	// If the Reader was created with WithPredicate or WithFilter, Next skips records that do not match.
 	Next() bool
 
-	// NumRecords returns the number of records in the .yxdb file.
+	// Err returns the first error encountered by Next, or nil if iteration ended because all records were read.
+	//
+	// A file that ends before NumRecords records have been read reports an error wrapping ErrTruncated. A block that
+	// cannot be decompressed reports an error wrapping ErrCorruptBlock.
+	Err() error
+
+	// NumRecords returns the number of records in the .yxdb file, including records skipped by WithPredicate and
+	// WithFilter.
 	NumRecords() int64
 
 	// MetaInfoStr returns the XML metadata, as a string, of the fields contained in the .yxdb file.
 	MetaInfoStr() string
 
+	// Header returns the values stored in the 512-byte header of the .yxdb file.
+	Header() Header
+
+	// SeekRecord positions the Reader so that the next call to Next reads the record with the specified zero-based
+	// record number. Seeking to NumRecords positions the Reader at the end of the file.
+	//
+	// SeekRecord uses the record block index of the .yxdb file to jump to the block containing the record, so only that
+	// block is decompressed. If the record number is out of range or the Reader was created from a stream that
+	// does not implement io.Seeker, SeekRecord will return an error.
+	SeekRecord(int64) error
+
+	// ReadRecordAt loads the record with the specified zero-based record number so it can be read with the ReadXxx
+	// methods, whether or not it matches the predicates and filters of the Reader. Subsequent calls to Next continue
+	// from the following record.
+	//
+	// If the record number is out of range, the record cannot be read, or the Reader was created from a stream that
+	// does not implement io.Seeker, ReadRecordAt will return an error.
+	ReadRecordAt(int64) error
+
 	// ReadByteWithIndex reads a byte field at the specified field index.
 	//
 	// If the field at the specified index is not a byte field, ReadByteWithIndex will panic.
@@ -80,6 +130,17 @@ type Reader interface {
 	// If the name is not valid or the field with the specified name is not a numeric field, ReadFloat64WithName will panic.
 	ReadFloat64WithName(string) (float64, bool)
 
+	// ReadDecimalWithIndex reads the exact value of a FixedDecimal field at the specified field index.
+	//
+	// If the field at the specified index is not a FixedDecimal field, ReadDecimalWithIndex will panic.
+	ReadDecimalWithIndex(int) (decimal.Decimal, bool)
+
+	// ReadDecimalWithName reads the exact value of a FixedDecimal field with the specified name.
+	//
+	// If the name is not valid or the field with the specified name is not a FixedDecimal field, ReadDecimalWithName
+	// will panic.
+	ReadDecimalWithName(string) (decimal.Decimal, bool)
+
 	// ReadStringWithIndex reads a string field at the specified field index.
 	//
 	// If the field at the specified index is not a string field, ReadStringWithIndex will panic.
@@ -109,18 +170,191 @@ type Reader interface {
 	//
 	// If the name is not valid or the field with the specified name is not a binary field, ReadBlobWithName will panic.
 	ReadBlobWithName(string) []byte
+
+	// TryReadByteWithIndex reads a byte field at the specified field index.
+	//
+	// If the index is not valid or the field is not a byte field, TryReadByteWithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadByteWithIndex(int) (byte, bool, error)
+
+	// TryReadByteWithName reads a byte field with the specified name.
+	//
+	// If the name is not valid or the field is not a byte field, TryReadByteWithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadByteWithName(string) (byte, bool, error)
+
+	// TryReadBoolWithIndex reads a boolean field at the specified field index.
+	//
+	// If the index is not valid or the field is not a boolean field, TryReadBoolWithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadBoolWithIndex(int) (bool, bool, error)
+
+	// TryReadBoolWithName reads a boolean field with the specified name.
+	//
+	// If the name is not valid or the field is not a boolean field, TryReadBoolWithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadBoolWithName(string) (bool, bool, error)
+
+	// TryReadInt64WithIndex reads a integer field at the specified field index.
+	//
+	// If the index is not valid or the field is not a integer field, TryReadInt64WithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadInt64WithIndex(int) (int64, bool, error)
+
+	// TryReadInt64WithName reads a integer field with the specified name.
+	//
+	// If the name is not valid or the field is not a integer field, TryReadInt64WithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadInt64WithName(string) (int64, bool, error)
+
+	// TryReadFloat64WithIndex reads a numeric field at the specified field index.
+	//
+	// If the index is not valid or the field is not a numeric field, TryReadFloat64WithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadFloat64WithIndex(int) (float64, bool, error)
+
+	// TryReadFloat64WithName reads a numeric field with the specified name.
+	//
+	// If the name is not valid or the field is not a numeric field, TryReadFloat64WithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadFloat64WithName(string) (float64, bool, error)
+
+	// TryReadDecimalWithIndex reads the exact value of a FixedDecimal field at the specified field index.
+	//
+	// If the index is not valid or the field is not a FixedDecimal field, TryReadDecimalWithIndex returns an error
+	// wrapping ErrFieldNotFound or ErrTypeMismatch.
+	TryReadDecimalWithIndex(int) (decimal.Decimal, bool, error)
+
+	// TryReadDecimalWithName reads the exact value of a FixedDecimal field with the specified name.
+	//
+	// If the name is not valid or the field is not a FixedDecimal field, TryReadDecimalWithName returns an error
+	// wrapping ErrFieldNotFound or ErrTypeMismatch.
+	TryReadDecimalWithName(string) (decimal.Decimal, bool, error)
+
+	// TryReadStringWithIndex reads a string field at the specified field index.
+	//
+	// If the index is not valid or the field is not a string field, TryReadStringWithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadStringWithIndex(int) (string, bool, error)
+
+	// TryReadStringWithName reads a string field with the specified name.
+	//
+	// If the name is not valid or the field is not a string field, TryReadStringWithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadStringWithName(string) (string, bool, error)
+
+	// TryReadTimeWithIndex reads a date/datetime field at the specified field index.
+	//
+	// If the index is not valid or the field is not a date/datetime field, TryReadTimeWithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadTimeWithIndex(int) (time.Time, bool, error)
+
+	// TryReadTimeWithName reads a date/datetime field with the specified name.
+	//
+	// If the name is not valid or the field is not a date/datetime field, TryReadTimeWithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadTimeWithName(string) (time.Time, bool, error)
+
+	// TryReadBlobWithIndex reads a binary field at the specified field index.
+	//
+	// If the index is not valid or the field is not a binary field, TryReadBlobWithIndex returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadBlobWithIndex(int) ([]byte, error)
+
+	// TryReadBlobWithName reads a binary field with the specified name.
+	//
+	// If the name is not valid or the field is not a binary field, TryReadBlobWithName returns an error wrapping
+	// ErrFieldNotFound or ErrTypeMismatch.
+	TryReadBlobWithName(string) ([]byte, error)
+
+	// ReadValueWithIndex reads the field at the specified field index as the Go type returned by its ReadXxx method:
+	// byte, bool, int64, float64, string, time.Time, or []byte. FixedDecimal fields are read as decimal.Decimal.
+	// Null values are returned as nil.
+	//
+	// If the index is not valid, ReadValueWithIndex will panic.
+	ReadValueWithIndex(int) (any, bool)
+
+	// ReadValueWithName reads the field with the specified name in the same way as ReadValueWithIndex.
+	//
+	// If the name is not valid, ReadValueWithName will panic.
+	ReadValueWithName(string) (any, bool)
+
+	// Values returns the values of every field in the current record, in field order, as read by ReadValueWithIndex.
+	//
+	// The returned slice is newly allocated; it and any blobs it contains remain valid after the next call to Next.
+	Values() []any
+
+	// Map returns the values of every field in the current record, keyed by field name, as read by
+	// ReadValueWithIndex.
+	Map() map[string]any
+
+	// Scan copies the values of the current record into the struct pointed to by dest.
+	//
+	// Struct fields tagged with `yxdb:"FieldName"` receive the .yxdb field with that name; untagged exported fields
+	// receive the .yxdb field with the same name as the struct field, if there is one, and fields tagged with
+	// `yxdb:"-"` are skipped. Null values are stored as nil in pointer fields, passed as nil to fields that implement
+	// sql.Scanner (such as sql.NullString), and stored as the zero value in all other fields.
+	//
+	// Scan returns an error wrapping ErrFieldNotFound if a tagged field does not exist, ErrTypeMismatch if a field
+	// cannot be stored in the type of its struct field, and ErrOutOfRange if an integer does not fit in its struct
+	// field, such as 300 in an int8 or -1 in a uint. The mapping for each struct type is computed on the first call
+	// and reused afterward.
+	Scan(dest any) error
+
+	// Records returns an iterator over the remaining records in the file, for use with range:
+	//
+	//	for record, err := range reader.Records() {
+	//		if err != nil {
+	//			return err
+	//		}
+	//		id, _ := record.ReadInt64WithName(`Id`)
+	//	}
+	//
+	// Each Record reads from the reader's record buffer and is only valid until the next iteration; call its Snapshot
+	// method to keep it. If reading fails, the iterator yields the error once with an empty Record and stops. Breaking
+	// out of the loop leaves the reader positioned after the last record yielded.
+	Records() iter.Seq2[Record, error]
+
+	// RecordsWithColumns returns an iterator over the remaining records in the same way as Records, but each Record
+	// contains only the named fields, indexed in the order they are listed.
+	//
+	// If a name does not exist, the iterator yields a single error wrapping ErrFieldNotFound.
+	RecordsWithColumns(names ...string) iter.Seq2[Record, error]
+
+	// Snapshot returns a copy of the current record. The copy remains valid after Next is called, is never modified,
+	// and may be read from multiple goroutines at once, so it can be handed to worker goroutines.
+	//
+	// Blobs read from the snapshot are copies of its data, so modifying them does not change the snapshot.
+	Snapshot() Record
+
+	// NextBatch reads up to n of the remaining records into a Batch of typed column slices, so that numeric code can
+	// loop over the values of a field without a method call per record. Predicates, filters, and WithColumns apply to
+	// NextBatch in the same way as to Next.
+	//
+	// The batch holds fewer than n records when the end of the file is reached. When no records remain, NextBatch
+	// returns io.EOF. If an error occurs, NextBatch returns the records read before the error along with the error.
+	NextBatch(n int) (*Batch, error)
 }
 
 // ReadFile instantiates a Reader from the specified file path.
 //
 // If the file does not exist, cannot be opened, or is not a valid .yxdb file, ReadFile will return an error.
-func ReadFile(path string) (Reader, error) {
+func ReadFile(path string, opts ...Option) (Reader, error) {
+	reader, err := readFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+func readFile(path string, opts []Option) (*r, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	reader := &r{
-		stream: file,
+		stream:  file,
+		options: newOptions(opts),
 	}
 	err = reader.loadHeaderAndMetaInfo()
 	if err != nil {
@@ -134,9 +368,10 @@ func ReadFile(path string) (Reader, error) {
 // ReadStream instantiates a Reader from the specified io.ReadCloser.
 //
 // If the stream encounters an error or is not a valid .yxdb files, ReadStream will return an error.
-func ReadStream(stream io.ReadCloser) (Reader, error) {
+func ReadStream(stream io.ReadCloser, opts ...Option) (Reader, error) {
 	reader := &r{
-		stream: stream,
+		stream:  stream,
+		options: newOptions(opts),
 	}
 	err := reader.loadHeaderAndMetaInfo()
 	if err != nil {
@@ -146,36 +381,154 @@ func ReadStream(stream io.ReadCloser) (Reader, error) {
 	return reader, nil
 }
 
+// ReadReaderAt instantiates a Reader from the specified io.ReaderAt.
+//
+// Readers created with ReadReaderAt support SeekRecord and ReadRecordAt. Closing the Reader does not close the source.
+// If the source encounters an error or is not a valid .yxdb file, ReadReaderAt will return an error.
+func ReadReaderAt(source io.ReaderAt, opts ...Option) (Reader, error) {
+	return ReadStream(nopSeekCloser{io.NewSectionReader(source, 0, math.MaxInt64)}, opts...)
+}
+
+type nopSeekCloser struct {
+	*io.SectionReader
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
 type r struct {
-	stream       io.ReadCloser
-	fields       []metafield.MetaInfoField
-	metaInfoSize int
-	numRecords   int64
-	record       *yxrecord.YxdbRecord
-	recordReader *bufrecord.BufferedRecordReader
-	metaInfoStr  string
+	stream           io.ReadCloser
+	fields           []metafield.MetaInfoField
+	header           Header
+	recordBlockIndex []int64
+	record           *yxrecord.YxdbRecord
+	recordReader     *bufrecord.BufferedRecordReader
+	metaInfoStr      string
+	scanPlans        scanPlanCache
+	options          options
+	filter           matcher
+	appenders        []columnAppender
 }
 
 func (r *r) ListFields() []yxrecord.YxdbField {
 	return r.record.Fields
 }
 
+func (r *r) FieldInfo() []metafield.MetaInfoField {
+	fields := make([]metafield.MetaInfoField, len(r.fields))
+	copy(fields, r.fields)
+	return fields
+}
+
 func (r *r) Close() error {
-	return r.stream.Close()
+	return r.recordReader.Close()
 }
 
 func (r *r) Next() bool {
-	return r.recordReader.NextRecord()
+	for r.recordReader.NextRecord() {
+		if r.matches() {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the current record satisfies the predicates and filters of the reader.
+func (r *r) matches() bool {
+	return r.filter == nil || r.filter(r.recordReader.RecordBuffer)
+}
+
+func (r *r) Err() error {
+	return r.recordReader.Err
+}
+
+func (r *r) ReadValueWithIndex(index int) (any, bool) {
+	return r.record.ExtractValueWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) ReadValueWithName(name string) (any, bool) {
+	return r.record.ExtractValueWithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) Values() []any {
+	values := make([]any, len(r.record.Fields))
+	for index := range values {
+		values[index], _ = r.record.ExtractValueWithIndex(index, r.recordReader.RecordBuffer)
+	}
+	return values
+}
+
+func (r *r) Map() map[string]any {
+	values := make(map[string]any, len(r.record.Fields))
+	for index, field := range r.record.Fields {
+		values[field.Name], _ = r.record.ExtractValueWithIndex(index, r.recordReader.RecordBuffer)
+	}
+	return values
+}
+
+func (r *r) Snapshot() Record {
+	return newSnapshot(r.record, r.recordReader.RecordBuffer, &r.scanPlans)
+}
+
+func (r *r) Scan(dest any) error {
+	return r.scanPlans.scan(r.record, r.recordReader.RecordBuffer, dest)
+}
+
+func (r *r) Header() Header {
+	return r.header
 }
 
 func (r *r) NumRecords() int64 {
-	return r.numRecords
+	return r.header.NumRecords
 }
 
 func (r *r) MetaInfoStr() string {
 	return r.metaInfoStr
 }
 
+func (r *r) SeekRecord(recordNumber int64) error {
+	if recordNumber < 0 || recordNumber > r.header.NumRecords {
+		return recordOutOfRange(recordNumber)
+	}
+	if r.header.NumRecords == 0 {
+		return nil
+	}
+	err := r.loadRecordBlockIndex()
+	if err != nil {
+		return err
+	}
+	block := recordNumber / bufrecord.RecordsPerBlock
+	if block == int64(len(r.recordBlockIndex)) {
+		block--
+	}
+	recordsBefore := block * bufrecord.RecordsPerBlock
+	err = r.recordReader.SeekBlock(r.recordBlockIndex[block], recordsBefore)
+	if err != nil {
+		return err
+	}
+	for skip := recordNumber - recordsBefore; skip > 0; skip-- {
+		if !r.recordReader.NextRecord() {
+			return r.readError()
+		}
+	}
+	return nil
+}
+
+func (r *r) ReadRecordAt(recordNumber int64) error {
+	if recordNumber >= r.header.NumRecords {
+		return recordOutOfRange(recordNumber)
+	}
+	err := r.SeekRecord(recordNumber)
+	if err != nil {
+		return err
+	}
+	if !r.recordReader.NextRecord() {
+		return r.readError()
+	}
+	return nil
+}
+
 func (r *r) ReadByteWithIndex(index int) (byte, bool) {
 	return r.record.ExtractByteWithIndex(index, r.recordReader.RecordBuffer)
 }
@@ -208,6 +561,14 @@ func (r *r) ReadFloat64WithName(name string) (float64, bool) {
 	return r.record.ExtractFloat64WithName(name, r.recordReader.RecordBuffer)
 }
 
+func (r *r) ReadDecimalWithIndex(index int) (decimal.Decimal, bool) {
+	return r.record.ExtractDecimalWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) ReadDecimalWithName(name string) (decimal.Decimal, bool) {
+	return r.record.ExtractDecimalWithName(name, r.recordReader.RecordBuffer)
+}
+
 func (r *r) ReadStringWithIndex(index int) (string, bool) {
 	return r.record.ExtractStringWithIndex(index, r.recordReader.RecordBuffer)
 }
@@ -232,6 +593,70 @@ func (r *r) ReadBlobWithName(name string) []byte {
 	return r.record.ExtractBlobWithName(name, r.recordReader.RecordBuffer)
 }
 
+func (r *r) TryReadByteWithIndex(index int) (byte, bool, error) {
+	return r.record.TryExtractByteWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadByteWithName(name string) (byte, bool, error) {
+	return r.record.TryExtractByteWithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadBoolWithIndex(index int) (bool, bool, error) {
+	return r.record.TryExtractBoolWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadBoolWithName(name string) (bool, bool, error) {
+	return r.record.TryExtractBoolWithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadInt64WithIndex(index int) (int64, bool, error) {
+	return r.record.TryExtractInt64WithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadInt64WithName(name string) (int64, bool, error) {
+	return r.record.TryExtractInt64WithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadFloat64WithIndex(index int) (float64, bool, error) {
+	return r.record.TryExtractFloat64WithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadFloat64WithName(name string) (float64, bool, error) {
+	return r.record.TryExtractFloat64WithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadDecimalWithIndex(index int) (decimal.Decimal, bool, error) {
+	return r.record.TryExtractDecimalWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadDecimalWithName(name string) (decimal.Decimal, bool, error) {
+	return r.record.TryExtractDecimalWithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadStringWithIndex(index int) (string, bool, error) {
+	return r.record.TryExtractStringWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadStringWithName(name string) (string, bool, error) {
+	return r.record.TryExtractStringWithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadTimeWithIndex(index int) (time.Time, bool, error) {
+	return r.record.TryExtractTimeWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadTimeWithName(name string) (time.Time, bool, error) {
+	return r.record.TryExtractTimeWithName(name, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadBlobWithIndex(index int) ([]byte, error) {
+	return r.record.TryExtractBlobWithIndex(index, r.recordReader.RecordBuffer)
+}
+
+func (r *r) TryReadBlobWithName(name string) ([]byte, error) {
+	return r.record.TryExtractBlobWithName(name, r.recordReader.RecordBuffer)
+}
+
 func (r *r) loadHeaderAndMetaInfo() error {
 	r.fields = make([]metafield.MetaInfoField, 0)
 	header, err := r.getHeader()
@@ -244,8 +669,7 @@ func (r *r) loadHeaderAndMetaInfo() error {
 		return invalidYxdbFile()
 	}
 
-	r.numRecords = int64(binary.LittleEndian.Uint64(header[104:112]))
-	r.metaInfoSize = int(binary.LittleEndian.Uint32(header[80:84]))
+	r.header = parseHeader(header)
 	err = r.loadMetaInfo()
 	if err != nil {
 		return err
@@ -254,37 +678,58 @@ func (r *r) loadHeaderAndMetaInfo() error {
 	if err != nil {
 		return err
 	}
+	r.filter, err = r.compileFilter(r.record)
+	if err != nil {
+		return err
+	}
+	if r.options.columns != nil {
+		r.record, err = r.record.Project(r.options.columns)
+		if err != nil {
+			return err
+		}
+		r.fields = projectFieldInfo(r.fields, r.options.columns)
+	}
 	r.recordReader = bufrecord.NewBufferedRecordReader(
 		r.stream,
 		r.record.FixedSize,
 		r.record.HasVar,
-		r.numRecords,
+		r.header.NumRecords,
 	)
+	start := int64(headerSize) + int64(r.header.MetaInfoLength)*2
+	if r.header.RecordBlockIndexPos >= start {
+		r.recordReader.LimitBlocks(start, r.header.RecordBlockIndexPos)
+	}
+	if r.options.pipelineBuffers > 0 {
+		r.recordReader.EnablePipelining(r.options.pipelineBuffers)
+	}
 	return nil
 }
 
 func (r *r) getHeader() ([]byte, error) {
-	headerBytes := make([]byte, 512)
-	written, err := r.stream.Read(headerBytes)
+	headerBytes := make([]byte, headerSize)
+	_, err := io.ReadFull(r.stream, headerBytes)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, invalidYxdbFile()
+	}
 	if err != nil {
 		return nil, err
 	}
-	if written < 512 {
-		return nil, invalidYxdbFile()
-	}
 	return headerBytes, nil
 }
 
 func (r *r) loadMetaInfo() error {
-	size := r.metaInfoSize * 2
+	size := int(r.header.MetaInfoLength) * 2
+	if size < 2 {
+		return invalidYxdbFile()
+	}
 	metaInfoBytes := make([]byte, size)
-	read, err := r.stream.Read(metaInfoBytes)
+	_, err := io.ReadFull(r.stream, metaInfoBytes)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return invalidYxdbFile()
+	}
 	if err != nil {
 		return err
 	}
-	if read < size {
-		return invalidYxdbFile()
-	}
 	r.metaInfoStr = string(utf16.Decode(bytesToUint16(metaInfoBytes[0 : size-2])))
 	return r.getFields()
 }
@@ -302,6 +747,52 @@ func (r *r) getFields() error {
 	return nil
 }
 
+func projectFieldInfo(fields []metafield.MetaInfoField, names []string) []metafield.MetaInfoField {
+	byName := make(map[string]metafield.MetaInfoField, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+	projected := make([]metafield.MetaInfoField, len(names))
+	for index, name := range names {
+		projected[index] = byName[name]
+	}
+	return projected
+}
+
+func (r *r) readError() error {
+	if r.recordReader.Err != nil {
+		return r.recordReader.Err
+	}
+	return invalidYxdbFile()
+}
+
+func (r *r) loadRecordBlockIndex() error {
+	if r.recordBlockIndex != nil {
+		return nil
+	}
+	countBytes := make([]byte, 4)
+	err := r.recordReader.ReadAt(countBytes, r.header.RecordBlockIndexPos)
+	if err != nil {
+		return err
+	}
+	count := int64(binary.LittleEndian.Uint32(countBytes))
+	expected := (r.header.NumRecords + bufrecord.RecordsPerBlock - 1) / bufrecord.RecordsPerBlock
+	if count != expected {
+		return errors.New(`record block index does not match the number of records in the file`)
+	}
+	indexBytes := make([]byte, count*8)
+	err = r.recordReader.ReadAt(indexBytes, r.header.RecordBlockIndexPos+4)
+	if err != nil {
+		return err
+	}
+	index := make([]int64, count)
+	for i := range index {
+		index[i] = int64(binary.LittleEndian.Uint64(indexBytes[i*8 : (i+1)*8]))
+	}
+	r.recordBlockIndex = index
+	return nil
+}
+
 func (r *r) close() {
 	_ = r.stream.Close()
 }
@@ -316,6 +807,10 @@ func bytesToUint16(buffer []byte) []uint16 {
 	return utf16Bytes
 }
 
+func recordOutOfRange(recordNumber int64) error {
+	return fmt.Errorf(`record number %v is out of range`, recordNumber)
+}
+
 func invalidYxdbFile() error {
 	return errors.New(`file is not a valid YXDB format`)
 }
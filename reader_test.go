@@ -1,9 +1,16 @@
 package yxdb_test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"io"
 	"os"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -33,6 +40,9 @@ func TestGetReader(t *testing.T) {
 		checkField(t, int64(64), false, func() (interface{}, bool) { return yxdb.ReadInt64WithName(`Int64Field`) })
 		checkField(t, 123.45, false, func() (interface{}, bool) { return yxdb.ReadFloat64WithIndex(5) })
 		checkField(t, 123.45, false, func() (interface{}, bool) { return yxdb.ReadFloat64WithName(`FixedDecimalField`) })
+		expectedDecimal := decimal.Decimal{Text: `123.450000`, Scale: 6}
+		checkField(t, expectedDecimal, false, func() (interface{}, bool) { return yxdb.ReadDecimalWithIndex(5) })
+		checkField(t, expectedDecimal, false, func() (interface{}, bool) { return yxdb.ReadDecimalWithName(`FixedDecimalField`) })
 		checkField(t, `A`, false, func() (interface{}, bool) { return yxdb.ReadStringWithIndex(8) })
 		checkField(t, `A`, false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`StringField`) })
 		checkField(t, `AB`, false, func() (interface{}, bool) { return yxdb.ReadStringWithIndex(9) })
@@ -62,6 +72,60 @@ func TestGetReader(t *testing.T) {
 	}
 }
 
+func TestHeader(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	header := yxdb.Header()
+	if header.Description != "Alteryx Database File  (C) 2020 Alteryx\r\n" {
+		t.Fatalf(`unexpected description %q`, header.Description)
+	}
+	if header.FileId != 0x00440204 {
+		t.Fatalf(`expected file ID 0x440204 but got %x`, header.FileId)
+	}
+	if expected := time.Date(2020, 6, 19, 10, 51, 7, 0, time.UTC); header.CreationDate != expected {
+		t.Fatalf(`expected creation date %v but got %v`, expected, header.CreationDate)
+	}
+	if header.MetaInfoLength != 67 || header.RecordBlockIndexPos != 401024 || header.NumRecords != 100000 {
+		t.Fatalf(`unexpected header values %+v`, header)
+	}
+	if header.CompressionVersion != 1 || header.HasSpatialIndex() {
+		t.Fatalf(`unexpected header values %+v`, header)
+	}
+}
+
+func TestHeaderWithSpatialIndex(t *testing.T) {
+	yxdb := getYxdb(t, `point.yxdb`)
+	defer yxdb.Close()
+
+	header := yxdb.Header()
+	if !header.HasSpatialIndex() || header.SpatialIndexPos != 1242 {
+		t.Fatalf(`expected a spatial index at 1242 but got %v`, header.SpatialIndexPos)
+	}
+	if header.FileId != 0x00440205 {
+		t.Fatalf(`expected file ID 0x440205 but got %x`, header.FileId)
+	}
+}
+
+func TestFieldInfo(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+
+	fields := yxdb.FieldInfo()
+	if len(fields) != 16 {
+		t.Fatalf(`expected 16 fields but got %v`, len(fields))
+	}
+	if field := fields[2]; field.Name != `Int16Field` || field.Type != `Int16` || field.Source != `Formula: 16` {
+		t.Fatalf(`unexpected field %+v`, field)
+	}
+	if field := fields[5]; field.Type != `FixedDecimal` || field.Size != 19 || field.Scale != 6 {
+		t.Fatalf(`unexpected field %+v`, field)
+	}
+	if field := fields[12]; field.Type != `V_WString` || field.Size != 10 || field.Source != `Formula: "XZY"` {
+		t.Fatalf(`unexpected field %+v`, field)
+	}
+}
+
 func TestLotsOfRecords(t *testing.T) {
 	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
 
@@ -79,6 +143,76 @@ func TestLotsOfRecords(t *testing.T) {
 	_ = yxdb.Close()
 }
 
+func TestBackgroundDecompression(t *testing.T) {
+	yxdb, err := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithBackgroundDecompression(4))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	sum := int64(0)
+	for yxdb.Next() {
+		value, _ := yxdb.ReadInt64WithIndex(0)
+		sum += value
+	}
+	if yxdb.Err() != nil {
+		t.Fatalf(`expected no error but got: %v`, yxdb.Err().Error())
+	}
+	if sum != 5000050000 {
+		t.Fatalf(`expected 5000050000 but got %v`, sum)
+	}
+	err = yxdb.ReadRecordAt(70000)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	checkField(t, int64(70001), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+}
+
+func TestCloseDuringBackgroundDecompression(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+	yxdb, _ := yx.ReadFile(getPath(`LotsOfRecords.yxdb`), yx.WithBackgroundDecompression(2))
+	yxdb.Next()
+	if err := yxdb.Close(); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if after := runtime.NumGoroutine(); after != goroutines {
+		t.Fatalf(`expected %v goroutines after Close but got %v`, goroutines, after)
+	}
+}
+
+func TestWithColumns(t *testing.T) {
+	yxdb, err := yx.ReadFile(getPath(`AllNormalFields.yxdb`), yx.WithColumns(`DateTimeField`, `ByteField`))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer yxdb.Close()
+
+	if fields := yxdb.ListFields(); len(fields) != 2 || fields[0].Name != `DateTimeField` || fields[1].Name != `ByteField` {
+		t.Fatalf(`expected DateTimeField and ByteField but got %v`, fields)
+	}
+	if info := yxdb.FieldInfo(); len(info) != 2 || info[0].Type != `DateTime` || info[1].Type != `Byte` {
+		t.Fatalf(`expected DateTime and Byte field info but got %v`, info)
+	}
+	yxdb.Next()
+	expected := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+	checkField(t, expected, false, func() (interface{}, bool) { return yxdb.ReadTimeWithIndex(0) })
+	checkField(t, byte(1), false, func() (interface{}, bool) { return yxdb.ReadByteWithIndex(1) })
+	checkField(t, byte(1), false, func() (interface{}, bool) { return yxdb.ReadByteWithName(`ByteField`) })
+	if values := yxdb.Values(); len(values) != 2 {
+		t.Fatalf(`expected 2 values but got %v`, values)
+	}
+	if _, _, err = yxdb.TryReadStringWithName(`StringField`); !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+}
+
+func TestWithInvalidColumn(t *testing.T) {
+	_, err := yx.ReadFile(getPath(`AllNormalFields.yxdb`), yx.WithColumns(`invalid`))
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+}
+
 func TestLoadReaderFromStream(t *testing.T) {
 	path := getPath(`LotsOfRecords.yxdb`)
 	file, _ := os.Open(path)
@@ -157,6 +291,173 @@ func TestVeryLongField(t *testing.T) {
 	}
 }
 
+func TestReadRecordAt(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	for _, recordNumber := range []int64{70000, 5, 65535, 65536, 99999, 0} {
+		err := yxdb.ReadRecordAt(recordNumber)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		checkField(t, recordNumber+1, false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+	}
+	yxdb.Next()
+	checkField(t, int64(2), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+}
+
+func TestSeekRecord(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	err := yxdb.SeekRecord(99998)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	read := 0
+	for yxdb.Next() {
+		read++
+	}
+	if read != 2 {
+		t.Fatalf(`expected 2 records but got %v`, read)
+	}
+
+	err = yxdb.SeekRecord(yxdb.NumRecords())
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if yxdb.Next() {
+		t.Fatalf(`expected no more records`)
+	}
+}
+
+func TestSeekVariableLengthRecords(t *testing.T) {
+	yxdb := getYxdb(t, `TutorialData.yxdb`)
+	defer yxdb.Close()
+
+	expected := make([]string, 0, yxdb.NumRecords())
+	for yxdb.Next() {
+		value, _ := yxdb.ReadStringWithName(`Email`)
+		expected = append(expected, value)
+	}
+	for _, recordNumber := range []int64{8715, 4000, 1} {
+		err := yxdb.ReadRecordAt(recordNumber)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		checkField(t, expected[recordNumber], false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`Email`) })
+	}
+}
+
+func TestReadRecordAtOutOfRange(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+
+	err := yxdb.ReadRecordAt(1)
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	if err.Error() != `record number 1 is out of range` {
+		t.Fatalf(`expected 'record number 1 is out of range' but got '%v'`, err.Error())
+	}
+}
+
+func TestLoadReaderFromReaderAt(t *testing.T) {
+	raw, _ := os.ReadFile(getPath(`LotsOfRecords.yxdb`))
+	yxdb, err := yx.ReadReaderAt(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf(`expected no error but got %v`, err.Error())
+	}
+
+	err = yxdb.ReadRecordAt(80000)
+	if err != nil {
+		t.Fatalf(`expected no error but got %v`, err.Error())
+	}
+	checkField(t, int64(80001), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+}
+
+func TestSeekUnseekableStream(t *testing.T) {
+	file, _ := os.Open(getPath(`LotsOfRecords.yxdb`))
+	yxdb, _ := yx.ReadStream(struct{ io.ReadCloser }{file})
+	defer yxdb.Close()
+
+	err := yxdb.SeekRecord(10)
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+func TestSeekWithCorruptIndexKeepsPosition(t *testing.T) {
+	raw, _ := os.ReadFile(getPath(`LotsOfRecords.yxdb`))
+	binary.LittleEndian.PutUint32(raw[401024:401028], 1000)
+	for _, opts := range [][]yx.Option{nil, {yx.WithBackgroundDecompression(2)}} {
+		stream := struct {
+			io.ReadSeeker
+			io.Closer
+		}{bytes.NewReader(raw), io.NopCloser(nil)}
+		yxdb, _ := yx.ReadStream(stream, opts...)
+		yxdb.Next()
+		err := yxdb.SeekRecord(50000)
+		if err == nil {
+			t.Fatalf(`expected an error but got none`)
+		}
+		if !yxdb.Next() {
+			t.Fatalf(`expected a record but got error %v`, yxdb.Err())
+		}
+		checkField(t, int64(2), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+		_ = yxdb.Close()
+	}
+}
+
+func TestErrIsNilAfterAllRecords(t *testing.T) {
+	yxdb := getYxdb(t, `TutorialData.yxdb`)
+	defer yxdb.Close()
+
+	for yxdb.Next() {
+	}
+	if err := yxdb.Err(); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+}
+
+func TestTruncatedFile(t *testing.T) {
+	raw, _ := os.ReadFile(getPath(`LotsOfRecords.yxdb`))
+	yxdb, err := yx.ReadReaderAt(bytes.NewReader(raw[0:300000]))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+
+	read := int64(0)
+	for yxdb.Next() {
+		read++
+	}
+	if read >= yxdb.NumRecords() {
+		t.Fatalf(`expected fewer than %v records but got %v`, yxdb.NumRecords(), read)
+	}
+	err = yxdb.Err()
+	if !errors.Is(err, yx.ErrTruncated) {
+		t.Fatalf(`expected ErrTruncated but got: %v`, err)
+	}
+	expected := fmt.Sprintf(`error reading record %v of 100000: yxdb file is truncated`, read+1)
+	if err.Error() != expected {
+		t.Fatalf(`expected '%v' but got '%v'`, expected, err.Error())
+	}
+}
+
+func TestCorruptBlock(t *testing.T) {
+	raw, _ := os.ReadFile(getPath(`LotsOfRecords.yxdb`))
+	for i := 650; i < 1000; i++ {
+		raw[i] = 0xff
+	}
+	yxdb, _ := yx.ReadReaderAt(bytes.NewReader(raw))
+
+	for yxdb.Next() {
+	}
+	if err := yxdb.Err(); !errors.Is(err, yx.ErrCorruptBlock) {
+		t.Fatalf(`expected ErrCorruptBlock but got: %v`, err)
+	}
+}
+
 func TestReadStringFromNonStringIndex(t *testing.T) {
 	defer checkPanic(t, `field at index 0 is not a string field`)()
 	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
@@ -192,6 +493,13 @@ func TestReadFloat64FromNonFloat64Index(t *testing.T) {
 	yxdb.ReadFloat64WithIndex(0)
 }
 
+func TestReadDecimalFromNonDecimalIndex(t *testing.T) {
+	defer checkPanic(t, `field at index 7 is not a decimal field`)()
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+
+	yxdb.ReadDecimalWithIndex(7)
+}
+
 func TestReadInt64FromNonInt64Index(t *testing.T) {
 	defer checkPanic(t, `field at index 0 is not a int64 field`)()
 	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
@@ -255,6 +563,128 @@ func TestReadInvalidByteField(t *testing.T) {
 	yxdb.ReadByteWithName(`invalid`)
 }
 
+func TestTryReadFields(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	value, isNull, err := yxdb.TryReadInt64WithName(`Int32Field`)
+	if err != nil || isNull || value != 32 {
+		t.Fatalf(`expected 32, not null and no error but got %v, %v and %v`, value, isNull, err)
+	}
+	str, isNull, err := yxdb.TryReadStringWithIndex(10)
+	if err != nil || isNull || str != `ABC` {
+		t.Fatalf(`expected ABC, not null and no error but got %v, %v and %v`, str, isNull, err)
+	}
+	dec, isNull, err := yxdb.TryReadDecimalWithName(`FixedDecimalField`)
+	if err != nil || isNull || dec.Text != `123.450000` {
+		t.Fatalf(`expected 123.450000, not null and no error but got %v, %v and %v`, dec, isNull, err)
+	}
+	blob, err := yxdb.TryReadBlobWithName(`V_StringShortField`)
+	if !errors.Is(err, yx.ErrTypeMismatch) || blob != nil {
+		t.Fatalf(`expected ErrTypeMismatch but got %v`, err)
+	}
+}
+
+func TestTryReadInvalidName(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	_, _, err := yxdb.TryReadTimeWithName(`invalid`)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+	if err.Error() != `field 'invalid' does not exist` {
+		t.Fatalf(`expected 'field 'invalid' does not exist' but got '%v'`, err.Error())
+	}
+}
+
+func TestTryReadTypeMismatch(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	_, _, err := yxdb.TryReadBoolWithIndex(0)
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected ErrTypeMismatch but got %v`, err)
+	}
+	if err.Error() != `field at index 0 is not a bool field` {
+		t.Fatalf(`expected 'field at index 0 is not a bool field' but got '%v'`, err.Error())
+	}
+}
+
+func TestTryReadInvalidIndex(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	_, _, err := yxdb.TryReadFloat64WithIndex(100)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	yxdb := openYxdb(t, writeValuesAndNulls(t))
+	defer yxdb.Close()
+
+	yxdb.Next()
+	values := yxdb.Values()
+	expected := []any{
+		byte(1), true, int64(16), int64(32), int64(64), decimal.Decimal{Text: `123.450000`, Scale: 6},
+		float64(float32(678.9)), 0.12345, `A`, `AB`, `ABC`, strings.Repeat(`B`, 500), `XZY`, strings.Repeat(`W`, 500),
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC), []byte{1, 2},
+		[]byte(strings.Repeat(`S`, 100)),
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, values)
+	}
+
+	yxdb.Next()
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected values to remain unchanged after Next but got %v`, values)
+	}
+	for index, value := range yxdb.Values() {
+		if value != nil {
+			t.Fatalf(`expected nil at index %v but got %v`, index, value)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	values := yxdb.Map()
+	if len(values) != 16 {
+		t.Fatalf(`expected 16 values but got %v`, len(values))
+	}
+	if value := values[`Int16Field`]; value != int64(16) {
+		t.Fatalf(`expected 16 but got %v`, value)
+	}
+	if value := values[`V_WStringShortField`]; value != `XZY` {
+		t.Fatalf(`expected XZY but got %v`, value)
+	}
+}
+
+func TestReadValue(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	checkField(t, true, false, func() (interface{}, bool) { return yxdb.ReadValueWithIndex(1) })
+	checkField(t, `AB`, false, func() (interface{}, bool) { return yxdb.ReadValueWithName(`WStringField`) })
+}
+
+func TestReadValueWithInvalidIndex(t *testing.T) {
+	defer checkPanic(t, `field index 16 does not exist`)()
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+
+	yxdb.ReadValueWithIndex(16)
+}
+
 func TestInvalidFile(t *testing.T) {
 	_, err := yx.ReadFile(getPath(`invalid.txt`))
 	if err == nil {
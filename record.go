@@ -0,0 +1,234 @@
+package yxdb
+
+import (
+	"encoding/binary"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"iter"
+	"time"
+)
+
+// Record is a single record from a .yxdb file, produced by Reader.Records, Reader.RecordsWithColumns, and
+// Reader.Snapshot.
+//
+// Record has the same ReadXxx, TryReadXxx, and Scan methods as Reader and reads them from its own record rather than
+// the reader's current record. The methods panic and return errors in the same way as their Reader counterparts.
+//
+// Records produced by the iterators share the reader's record buffer and are only valid until the next iteration.
+// Records produced by Snapshot own a copy of the record's bytes; they are never modified and may be used from
+// multiple goroutines at once.
+type Record struct {
+	record *yxrecord.YxdbRecord
+	buffer []byte
+	plans  *scanPlanCache
+}
+
+// ListFields returns the list of fields contained in the record and their data type.
+func (r Record) ListFields() []yxrecord.YxdbField {
+	return r.record.Fields
+}
+
+func (r Record) ReadByteWithIndex(index int) (byte, bool) {
+	return r.record.ExtractByteWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadByteWithName(name string) (byte, bool) {
+	return r.record.ExtractByteWithName(name, r.buffer)
+}
+
+func (r Record) ReadBoolWithIndex(index int) (bool, bool) {
+	return r.record.ExtractBoolWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadBoolWithName(name string) (bool, bool) {
+	return r.record.ExtractBoolWithName(name, r.buffer)
+}
+
+func (r Record) ReadInt64WithIndex(index int) (int64, bool) {
+	return r.record.ExtractInt64WithIndex(index, r.buffer)
+}
+
+func (r Record) ReadInt64WithName(name string) (int64, bool) {
+	return r.record.ExtractInt64WithName(name, r.buffer)
+}
+
+func (r Record) ReadFloat64WithIndex(index int) (float64, bool) {
+	return r.record.ExtractFloat64WithIndex(index, r.buffer)
+}
+
+func (r Record) ReadFloat64WithName(name string) (float64, bool) {
+	return r.record.ExtractFloat64WithName(name, r.buffer)
+}
+
+func (r Record) ReadDecimalWithIndex(index int) (decimal.Decimal, bool) {
+	return r.record.ExtractDecimalWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadDecimalWithName(name string) (decimal.Decimal, bool) {
+	return r.record.ExtractDecimalWithName(name, r.buffer)
+}
+
+func (r Record) ReadStringWithIndex(index int) (string, bool) {
+	return r.record.ExtractStringWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadStringWithName(name string) (string, bool) {
+	return r.record.ExtractStringWithName(name, r.buffer)
+}
+
+func (r Record) ReadTimeWithIndex(index int) (time.Time, bool) {
+	return r.record.ExtractTimeWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadTimeWithName(name string) (time.Time, bool) {
+	return r.record.ExtractTimeWithName(name, r.buffer)
+}
+
+func (r Record) ReadBlobWithIndex(index int) []byte {
+	return r.record.ExtractBlobWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadBlobWithName(name string) []byte {
+	return r.record.ExtractBlobWithName(name, r.buffer)
+}
+
+func (r Record) TryReadByteWithIndex(index int) (byte, bool, error) {
+	return r.record.TryExtractByteWithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadByteWithName(name string) (byte, bool, error) {
+	return r.record.TryExtractByteWithName(name, r.buffer)
+}
+
+func (r Record) TryReadBoolWithIndex(index int) (bool, bool, error) {
+	return r.record.TryExtractBoolWithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadBoolWithName(name string) (bool, bool, error) {
+	return r.record.TryExtractBoolWithName(name, r.buffer)
+}
+
+func (r Record) TryReadInt64WithIndex(index int) (int64, bool, error) {
+	return r.record.TryExtractInt64WithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadInt64WithName(name string) (int64, bool, error) {
+	return r.record.TryExtractInt64WithName(name, r.buffer)
+}
+
+func (r Record) TryReadFloat64WithIndex(index int) (float64, bool, error) {
+	return r.record.TryExtractFloat64WithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadFloat64WithName(name string) (float64, bool, error) {
+	return r.record.TryExtractFloat64WithName(name, r.buffer)
+}
+
+func (r Record) TryReadDecimalWithIndex(index int) (decimal.Decimal, bool, error) {
+	return r.record.TryExtractDecimalWithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadDecimalWithName(name string) (decimal.Decimal, bool, error) {
+	return r.record.TryExtractDecimalWithName(name, r.buffer)
+}
+
+func (r Record) TryReadStringWithIndex(index int) (string, bool, error) {
+	return r.record.TryExtractStringWithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadStringWithName(name string) (string, bool, error) {
+	return r.record.TryExtractStringWithName(name, r.buffer)
+}
+
+func (r Record) TryReadTimeWithIndex(index int) (time.Time, bool, error) {
+	return r.record.TryExtractTimeWithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadTimeWithName(name string) (time.Time, bool, error) {
+	return r.record.TryExtractTimeWithName(name, r.buffer)
+}
+
+func (r Record) TryReadBlobWithIndex(index int) ([]byte, error) {
+	return r.record.TryExtractBlobWithIndex(index, r.buffer)
+}
+
+func (r Record) TryReadBlobWithName(name string) ([]byte, error) {
+	return r.record.TryExtractBlobWithName(name, r.buffer)
+}
+
+func (r Record) ReadValueWithIndex(index int) (any, bool) {
+	return r.record.ExtractValueWithIndex(index, r.buffer)
+}
+
+func (r Record) ReadValueWithName(name string) (any, bool) {
+	return r.record.ExtractValueWithName(name, r.buffer)
+}
+
+// Values returns the values of every field in the record, in field order, as read by ReadValueWithIndex.
+func (r Record) Values() []any {
+	values := make([]any, len(r.record.Fields))
+	for index := range values {
+		values[index], _ = r.record.ExtractValueWithIndex(index, r.buffer)
+	}
+	return values
+}
+
+// Map returns the values of every field in the record, keyed by field name, as read by ReadValueWithIndex.
+func (r Record) Map() map[string]any {
+	values := make(map[string]any, len(r.record.Fields))
+	for index, field := range r.record.Fields {
+		values[field.Name], _ = r.record.ExtractValueWithIndex(index, r.buffer)
+	}
+	return values
+}
+
+// Snapshot returns a copy of the record that remains valid after the reader moves to another record.
+func (r Record) Snapshot() Record {
+	return newSnapshot(r.record, r.buffer, r.plans)
+}
+
+// Scan copies the values of the record into the struct pointed to by dest, in the same way as Reader.Scan.
+func (r Record) Scan(dest any) error {
+	return r.plans.scan(r.record, r.buffer, dest)
+}
+
+func (r *r) Records() iter.Seq2[Record, error] {
+	return r.records(r.record)
+}
+
+func (r *r) RecordsWithColumns(names ...string) iter.Seq2[Record, error] {
+	projected, err := r.record.Project(names)
+	if err != nil {
+		return func(yield func(Record, error) bool) {
+			yield(Record{}, err)
+		}
+	}
+	return r.records(projected)
+}
+
+func (r *r) records(record *yxrecord.YxdbRecord) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for r.Next() {
+			if !yield(r.currentRecord(record), nil) {
+				return
+			}
+		}
+		if err := r.Err(); err != nil {
+			yield(Record{}, err)
+		}
+	}
+}
+
+func (r *r) currentRecord(record *yxrecord.YxdbRecord) Record {
+	return Record{record: record, buffer: r.recordReader.RecordBuffer, plans: &r.scanPlans}
+}
+
+func newSnapshot(record *yxrecord.YxdbRecord, buffer []byte, plans *scanPlanCache) Record {
+	length := record.FixedSize
+	if record.HasVar {
+		length += 4 + int(binary.LittleEndian.Uint32(buffer[record.FixedSize:record.FixedSize+4]))
+	}
+	snapshot := make([]byte, length)
+	copy(snapshot, buffer)
+	return Record{record: record, buffer: snapshot, plans: plans}
+}
@@ -0,0 +1,168 @@
+package yxdb_test
+
+import (
+	"bytes"
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecords(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	sum := int64(0)
+	for record, err := range yxdb.Records() {
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		value, _ := record.ReadInt64WithIndex(0)
+		sum += value
+	}
+	if sum != 5000050000 {
+		t.Fatalf(`expected 5000050000 but got %v`, sum)
+	}
+}
+
+func TestRecordsStopEarly(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	for record := range yxdb.Records() {
+		if value, _ := record.ReadInt64WithName(`RowCount`); value == 10 {
+			break
+		}
+	}
+	yxdb.Next()
+	if value, _ := yxdb.ReadInt64WithIndex(0); value != 11 {
+		t.Fatalf(`expected 11 but got %v`, value)
+	}
+}
+
+func TestRecordsWithColumns(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+
+	read := 0
+	for record, err := range yxdb.RecordsWithColumns(`V_WStringShortField`, `Int16Field`) {
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		if fields := record.ListFields(); len(fields) != 2 || fields[0].Name != `V_WStringShortField` {
+			t.Fatalf(`expected 2 fields starting with V_WStringShortField but got %v`, fields)
+		}
+		checkField(t, `XZY`, false, func() (interface{}, bool) { return record.ReadStringWithIndex(0) })
+		checkField(t, int64(16), false, func() (interface{}, bool) { return record.ReadInt64WithIndex(1) })
+		checkField(t, int64(16), false, func() (interface{}, bool) { return record.ReadInt64WithName(`Int16Field`) })
+		if _, _, err = record.TryReadInt64WithName(`Int32Field`); !errors.Is(err, yx.ErrFieldNotFound) {
+			t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+		}
+		read++
+	}
+	if read != 1 {
+		t.Fatalf(`expected 1 record but got %v`, read)
+	}
+}
+
+func TestRecordsWithInvalidColumn(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+
+	calls := 0
+	for _, err := range yxdb.RecordsWithColumns(`invalid`) {
+		if !errors.Is(err, yx.ErrFieldNotFound) {
+			t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+		}
+		calls++
+	}
+	if calls != 1 {
+		t.Fatalf(`expected 1 call but got %v`, calls)
+	}
+}
+
+func TestRecordsReportsErrors(t *testing.T) {
+	raw, _ := os.ReadFile(getPath(`LotsOfRecords.yxdb`))
+	yxdb, _ := yx.ReadReaderAt(bytes.NewReader(raw[0:300000]))
+
+	var lastErr error
+	for _, err := range yxdb.Records() {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, yx.ErrTruncated) {
+		t.Fatalf(`expected ErrTruncated but got %v`, lastErr)
+	}
+}
+
+func TestRecordScan(t *testing.T) {
+	yxdb := openYxdb(t, writeValuesAndNulls(t))
+	defer yxdb.Close()
+
+	var dest struct {
+		Name  *string `yxdb:"StringField"`
+		Value int64   `yxdb:"Int64Field"`
+	}
+	var names []*string
+	for record, err := range yxdb.Records() {
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		if err = record.Scan(&dest); err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		names = append(names, dest.Name)
+	}
+	if len(names) != 2 || *names[0] != `A` || names[1] != nil {
+		t.Fatalf(`expected A and nil but got %v`, names)
+	}
+}
+
+func TestSnapshotSurvivesNext(t *testing.T) {
+	yxdb := openYxdb(t, writeValuesAndNulls(t))
+	defer yxdb.Close()
+
+	yxdb.Next()
+	snapshot := yxdb.Snapshot()
+	yxdb.Next()
+	checkField(t, `ABC`, false, func() (interface{}, bool) { return snapshot.ReadStringWithName(`V_StringShortField`) })
+	checkField(t, int64(32), false, func() (interface{}, bool) { return snapshot.ReadInt64WithName(`Int32Field`) })
+	if blob := snapshot.ReadBlobWithName(`SpatialField`); string(blob) != strings.Repeat(`S`, 100) {
+		t.Fatalf(`expected 100 S's but got %v`, string(blob))
+	}
+	checkField(t, ``, true, func() (interface{}, bool) { return yxdb.ReadStringWithName(`V_StringShortField`) })
+}
+
+func TestSnapshotsAcrossGoroutines(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	snapshots := make(chan yx.Record, 100)
+	sums := make(chan int64)
+	for worker := 0; worker < 4; worker++ {
+		go func() {
+			sum := int64(0)
+			var dest struct{ RowCount int64 }
+			for snapshot := range snapshots {
+				_ = snapshot.Scan(&dest)
+				sum += dest.RowCount
+			}
+			sums <- sum
+		}()
+	}
+	for record, err := range yxdb.Records() {
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		snapshots <- record.Snapshot()
+	}
+	close(snapshots)
+
+	total := int64(0)
+	for worker := 0; worker < 4; worker++ {
+		total += <-sums
+	}
+	if total != 5000050000 {
+		t.Fatalf(`expected 5000050000 but got %v`, total)
+	}
+}
@@ -0,0 +1,395 @@
+package yxdb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Unmarshal reads the remaining records of r into dest, which must be a pointer to a slice of structs or of pointers
+// to structs. Struct fields are matched to .yxdb fields in the same way as Reader.Scan.
+func Unmarshal(r Reader, dest any) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Pointer || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf(`Unmarshal requires a pointer to a slice but got %T`, dest)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	isPointer := elemType.Kind() == reflect.Pointer
+	if isPointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf(`Unmarshal requires a slice of structs but got %T`, dest)
+	}
+	for r.Next() {
+		elem := reflect.New(elemType)
+		err := r.Scan(elem.Interface())
+		if err != nil {
+			return err
+		}
+		if isPointer {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return r.Err()
+}
+
+// scanPlanCache holds the scan plans compiled for a reader and the records it produces. It is safe for concurrent use.
+// The plan used last is kept outside the map, so that repeated scans into the same struct type do not take the mutex.
+type scanPlanCache struct {
+	last  atomic.Pointer[scanPlan]
+	mutex sync.Mutex
+	plans map[scanPlanKey]*scanPlan
+}
+
+type scanPlanKey struct {
+	record     *yxrecord.YxdbRecord
+	structType reflect.Type
+}
+
+func (c *scanPlanCache) scan(record *yxrecord.YxdbRecord, buffer []byte, dest any) error {
+	value, err := scanDestination(dest)
+	if err != nil {
+		return err
+	}
+	key := scanPlanKey{record: record, structType: value.Type()}
+	plan := c.last.Load()
+	if plan == nil || plan.key != key {
+		plan, err = c.get(key)
+		if err != nil {
+			return err
+		}
+		c.last.Store(plan)
+	}
+	return plan.scan(buffer, value)
+}
+
+func (c *scanPlanCache) get(key scanPlanKey) (*scanPlan, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	plan, ok := c.plans[key]
+	if ok {
+		return plan, nil
+	}
+	plan, err := compileScanPlan(key.record, key.structType)
+	if err != nil {
+		return nil, err
+	}
+	plan.key = key
+	if c.plans == nil {
+		c.plans = make(map[scanPlanKey]*scanPlan)
+	}
+	c.plans[key] = plan
+	return plan, nil
+}
+
+// scanPlan maps the fields of a struct type onto the fields of a .yxdb file. Plans are compiled once per struct type
+// so that Scan only performs the assignments.
+type scanPlan struct {
+	key    scanPlanKey
+	fields []scanField
+}
+
+type scanField struct {
+	name        string
+	structIndex []int
+	assign      scanAssigner
+}
+
+// scanAssigner reads a field from buffer and stores it in dest, returning an error if the value does not fit.
+type scanAssigner func(buffer []byte, dest reflect.Value) error
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+var bytesType = reflect.TypeOf([]byte(nil))
+
+func (p *scanPlan) scan(buffer []byte, dest reflect.Value) error {
+	for _, field := range p.fields {
+		err := field.assign(buffer, dest.FieldByIndex(field.structIndex))
+		if err != nil {
+			return fmt.Errorf(`struct field %v: %w`, field.name, err)
+		}
+	}
+	return nil
+}
+
+// compileScanPlan matches the exported fields of structType to the fields in record. A field tagged with
+// `yxdb:"Name"` reads the .yxdb field with that name and reports an error if it does not exist; untagged fields read
+// the .yxdb field with the same name as the struct field, if there is one. Fields tagged with `yxdb:"-"` are skipped.
+func compileScanPlan(record *yxrecord.YxdbRecord, structType reflect.Type) (*scanPlan, error) {
+	plan := &scanPlan{}
+	for _, structField := range reflect.VisibleFields(structType) {
+		if !structField.IsExported() || (structField.Anonymous && structField.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if isBehindPointer(structType, structField.Index) {
+			continue
+		}
+		name, tagged := structField.Tag.Lookup(`yxdb`)
+		if name == `-` {
+			continue
+		}
+		if !tagged {
+			name = structField.Name
+		}
+		index, err := record.IndexOf(name)
+		if err != nil {
+			if !tagged {
+				continue
+			}
+			return nil, fmt.Errorf(`struct field %v: %w`, structField.Name, err)
+		}
+		assign, err := newScanAssigner(record, index, structField.Type)
+		if err != nil {
+			return nil, fmt.Errorf(`struct field %v: %w`, structField.Name, err)
+		}
+		plan.fields = append(plan.fields, scanField{name: structField.Name, structIndex: structField.Index, assign: assign})
+	}
+	return plan, nil
+}
+
+// isBehindPointer returns true if a promoted field is reached through an embedded pointer, which Scan does not
+// allocate.
+func isBehindPointer(structType reflect.Type, index []int) bool {
+	for i := 1; i < len(index); i++ {
+		if structType.FieldByIndex(index[:i]).Type.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
+
+// newScanAssigner returns the assigner of the field at index into a struct field of destType. Fields that implement
+// sql.Scanner receive the value as an any; all other assignments read the field with its typed extractor.
+func newScanAssigner(record *yxrecord.YxdbRecord, index int, destType reflect.Type) (scanAssigner, error) {
+	if reflect.PointerTo(destType).Implements(scannerType) {
+		read := newScannerValueReader(record, index)
+		return func(buffer []byte, dest reflect.Value) error {
+			return dest.Addr().Interface().(sql.Scanner).Scan(read(buffer))
+		}, nil
+	}
+	valueType := destType
+	if destType.Kind() == reflect.Pointer {
+		valueType = destType.Elem()
+	}
+	field := record.Fields[index]
+	kind := valueType.Kind()
+	switch field.Type {
+	case yxrecord.Int64:
+		extract, _ := record.Int64Extractor(index)
+		switch {
+		case isIntKind(kind):
+			return newTypedAssigner(extract, destType, intSetter(field, index)), nil
+		case isUintKind(kind):
+			return newTypedAssigner(extract, destType, uintSetter(field, index)), nil
+		case isFloatKind(kind):
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value int64) error {
+				dest.SetFloat(float64(value))
+				return nil
+			}), nil
+		}
+	case yxrecord.Float64:
+		if field.FieldType == yxrecord.TypeFixedDecimal {
+			extract, _ := record.DecimalExtractor(index)
+			switch {
+			case valueType == decimalType:
+				return newTypedAssigner(extract, destType, func(dest reflect.Value, value decimal.Decimal) error {
+					dest.Set(reflect.ValueOf(value))
+					return nil
+				}), nil
+			case kind == reflect.String:
+				return newTypedAssigner(extract, destType, func(dest reflect.Value, value decimal.Decimal) error {
+					dest.SetString(value.Text)
+					return nil
+				}), nil
+			case isFloatKind(kind):
+				return newTypedAssigner(extract, destType, func(dest reflect.Value, value decimal.Decimal) error {
+					dest.SetFloat(value.Float64())
+					return nil
+				}), nil
+			}
+			break
+		}
+		if isFloatKind(kind) {
+			extract, _ := record.Float64Extractor(index)
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value float64) error {
+				dest.SetFloat(value)
+				return nil
+			}), nil
+		}
+	case yxrecord.String:
+		if kind == reflect.String {
+			extract, _ := record.StringExtractor(index)
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value string) error {
+				dest.SetString(value)
+				return nil
+			}), nil
+		}
+	case yxrecord.Date:
+		if valueType == timeType {
+			extract, _ := record.TimeExtractor(index)
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value time.Time) error {
+				dest.Set(reflect.ValueOf(value))
+				return nil
+			}), nil
+		}
+	case yxrecord.Boolean:
+		if kind == reflect.Bool {
+			extract, _ := record.BoolExtractor(index)
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value bool) error {
+				dest.SetBool(value)
+				return nil
+			}), nil
+		}
+	case yxrecord.Byte:
+		extract, _ := record.ByteExtractor(index)
+		setInt, setUint := intSetter(field, index), uintSetter(field, index)
+		switch {
+		case isIntKind(kind):
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value byte) error {
+				return setInt(dest, int64(value))
+			}), nil
+		case isUintKind(kind):
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value byte) error {
+				return setUint(dest, int64(value))
+			}), nil
+		}
+	case yxrecord.Blob:
+		if valueType == bytesType {
+			extractBlob, _ := record.BlobExtractor(index)
+			extract := func(buffer []byte) ([]byte, bool) {
+				value := extractBlob(buffer)
+				return value, value == nil
+			}
+			return newTypedAssigner(extract, destType, func(dest reflect.Value, value []byte) error {
+				dest.SetBytes(value)
+				return nil
+			}), nil
+		}
+	}
+	return nil, &scanTypeError{field: field, index: index, destType: valueType}
+}
+
+// newTypedAssigner returns an assigner that extracts a value and stores it with set. Null values are stored as nil
+// in pointer fields and as the zero value in all other fields.
+func newTypedAssigner[T any](extract func([]byte) (T, bool), destType reflect.Type, set func(reflect.Value, T) error) scanAssigner {
+	if destType.Kind() == reflect.Pointer {
+		return func(buffer []byte, dest reflect.Value) error {
+			value, isNull := extract(buffer)
+			if isNull {
+				dest.SetZero()
+				return nil
+			}
+			if dest.IsNil() {
+				dest.Set(reflect.New(destType.Elem()))
+			}
+			return set(dest.Elem(), value)
+		}
+	}
+	return func(buffer []byte, dest reflect.Value) error {
+		value, isNull := extract(buffer)
+		if isNull {
+			dest.SetZero()
+			return nil
+		}
+		return set(dest, value)
+	}
+}
+
+// intSetter stores integers in signed integer kinds, failing for values that overflow the kind.
+func intSetter(field yxrecord.YxdbField, index int) func(reflect.Value, int64) error {
+	return func(dest reflect.Value, value int64) error {
+		if dest.OverflowInt(value) {
+			return &scanRangeError{field: field, index: index, value: value, destType: dest.Type()}
+		}
+		dest.SetInt(value)
+		return nil
+	}
+}
+
+// uintSetter stores integers in unsigned integer kinds, failing for negative values and values that overflow the kind.
+func uintSetter(field yxrecord.YxdbField, index int) func(reflect.Value, int64) error {
+	return func(dest reflect.Value, value int64) error {
+		if value < 0 || dest.OverflowUint(uint64(value)) {
+			return &scanRangeError{field: field, index: index, value: value, destType: dest.Type()}
+		}
+		dest.SetUint(uint64(value))
+		return nil
+	}
+}
+
+// newScannerValueReader returns a function that reads the field at index as a value accepted by sql.Scanner
+// implementations, or nil if the field is null: bytes are int64 and FixedDecimal values are their exact text.
+func newScannerValueReader(record *yxrecord.YxdbRecord, index int) func([]byte) any {
+	return func(buffer []byte) any {
+		value, _ := record.ExtractValueWithIndex(index, buffer)
+		switch v := value.(type) {
+		case byte:
+			return int64(v)
+		case decimal.Decimal:
+			return v.Text
+		}
+		return value
+	}
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+type scanTypeError struct {
+	field    yxrecord.YxdbField
+	index    int
+	destType reflect.Type
+}
+
+func (s *scanTypeError) Error() string {
+	return fmt.Sprintf(`%v field '%v' at index %v cannot be stored in a %v`, s.field.FieldType, s.field.Name, s.index, s.destType)
+}
+
+func (s *scanTypeError) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+type scanRangeError struct {
+	field    yxrecord.YxdbField
+	index    int
+	value    int64
+	destType reflect.Type
+}
+
+func (s *scanRangeError) Error() string {
+	return fmt.Sprintf(`value %v of %v field '%v' at index %v does not fit in a %v`, s.value, s.field.FieldType, s.field.Name, s.index, s.destType)
+}
+
+func (s *scanRangeError) Unwrap() error {
+	return ErrOutOfRange
+}
+
+var errScanDestination = errors.New(`Scan requires a non-nil pointer to a struct`)
+
+func scanDestination(dest any) (reflect.Value, error) {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errScanDestination
+	}
+	return value.Elem(), nil
+}
@@ -0,0 +1,237 @@
+package yxdb_test
+
+import (
+	"database/sql"
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type allFieldsStruct struct {
+	Byte                uint8           `yxdb:"ByteField"`
+	Bool                bool            `yxdb:"BoolField"`
+	Int16               int16           `yxdb:"Int16Field"`
+	Int32               *int32          `yxdb:"Int32Field"`
+	Int64               sql.NullInt64   `yxdb:"Int64Field"`
+	Decimal             decimal.Decimal `yxdb:"FixedDecimalField"`
+	Float               float32         `yxdb:"FloatField"`
+	Double              sql.NullFloat64 `yxdb:"DoubleField"`
+	String              string          `yxdb:"StringField"`
+	WString             sql.NullString  `yxdb:"WStringField"`
+	VString             *string         `yxdb:"V_StringShortField"`
+	Date                time.Time       `yxdb:"DateField"`
+	DateTime            sql.NullTime    `yxdb:"DateTimeField"`
+	Blob                []byte          `yxdb:"BlobField"`
+	SpatialObj          *[]byte         `yxdb:"SpatialField"`
+	Ignored             string          `yxdb:"-"`
+	V_WStringShortField string
+	NotInFile           string
+}
+
+func TestScanAllFieldTypes(t *testing.T) {
+	yxdb := openYxdb(t, writeValuesAndNulls(t))
+	defer yxdb.Close()
+
+	yxdb.Next()
+	var actual allFieldsStruct
+	actual.Ignored = `unchanged`
+	err := yxdb.Scan(&actual)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	int32Value := int32(32)
+	vString := `ABC`
+	spatial := []byte(strings.Repeat(`S`, 100))
+	expected := allFieldsStruct{
+		Byte:                1,
+		Bool:                true,
+		Int16:               16,
+		Int32:               &int32Value,
+		Int64:               sql.NullInt64{Int64: 64, Valid: true},
+		Decimal:             decimal.Decimal{Text: `123.450000`, Scale: 6},
+		Float:               678.9,
+		Double:              sql.NullFloat64{Float64: 0.12345, Valid: true},
+		String:              `A`,
+		WString:             sql.NullString{String: `AB`, Valid: true},
+		VString:             &vString,
+		Date:                time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		DateTime:            sql.NullTime{Time: time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC), Valid: true},
+		Blob:                []byte{1, 2},
+		SpatialObj:          &spatial,
+		Ignored:             `unchanged`,
+		V_WStringShortField: `XZY`,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected\n%+v\nbut got\n%+v", expected, actual)
+	}
+
+	yxdb.Next()
+	err = yxdb.Scan(&actual)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	expected = allFieldsStruct{Ignored: `unchanged`}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected\n%+v\nbut got\n%+v", expected, actual)
+	}
+}
+
+func TestScanDoesNotShareRecordBuffer(t *testing.T) {
+	yxdb := openYxdb(t, writeValuesAndNulls(t))
+	defer yxdb.Close()
+
+	var first struct {
+		Blob []byte `yxdb:"BlobField"`
+	}
+	yxdb.Next()
+	_ = yxdb.Scan(&first)
+	yxdb.Next()
+	if !reflect.DeepEqual(first.Blob, []byte{1, 2}) {
+		t.Fatalf(`expected [1 2] but got %v`, first.Blob)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	yxdb := getYxdb(t, `LotsOfRecords.yxdb`)
+	defer yxdb.Close()
+
+	var records []*struct {
+		RowCount int64
+	}
+	err := yx.Unmarshal(yxdb, &records)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if len(records) != 100000 {
+		t.Fatalf(`expected 100000 records but got %v`, len(records))
+	}
+	for index, record := range records {
+		if record.RowCount != int64(index+1) {
+			t.Fatalf(`expected record %v to be %v but got %v`, index, index+1, record.RowCount)
+		}
+	}
+}
+
+func TestScanMissingField(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	var dest struct {
+		Missing string `yxdb:"Missing"`
+	}
+	err := yxdb.Scan(&dest)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+}
+
+func TestScanTypeMismatch(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	var dest struct {
+		Date string `yxdb:"DateField"`
+	}
+	err := yxdb.Scan(&dest)
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected ErrTypeMismatch but got %v`, err)
+	}
+	expected := `struct field Date: Date field 'DateField' at index 14 cannot be stored in a string`
+	if err.Error() != expected {
+		t.Fatalf(`expected '%v' but got '%v'`, expected, err.Error())
+	}
+}
+
+func TestScanOutOfRange(t *testing.T) {
+	path := tempPath(t, `OutOfRange.yxdb`)
+	writer, _ := yx.CreateFile(path, []metafield.MetaInfoField{{Name: `Value`, Type: `Int64`}})
+	for _, value := range []int64{300, -1, 255} {
+		writer.WriteInt64WithIndex(0, value)
+		_ = writer.WriteRecord()
+	}
+	_ = writer.Close()
+	yxdb := openYxdb(t, path)
+	defer yxdb.Close()
+
+	var small struct{ Value int8 }
+	var unsigned struct{ Value *uint }
+	yxdb.Next()
+	err := yxdb.Scan(&small)
+	if !errors.Is(err, yx.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got %v`, err)
+	}
+	expected := `struct field Value: value 300 of Int64 field 'Value' at index 0 does not fit in a int8`
+	if err.Error() != expected {
+		t.Fatalf(`expected '%v' but got '%v'`, expected, err.Error())
+	}
+	yxdb.Next()
+	if err = yxdb.Scan(&unsigned); !errors.Is(err, yx.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got %v`, err)
+	}
+	yxdb.Next()
+	if err = yxdb.Scan(&unsigned); err != nil || *unsigned.Value != 255 {
+		t.Fatalf(`expected 255 and no error but got %v and %v`, unsigned.Value, err)
+	}
+	if err = yxdb.Scan(&small); !errors.Is(err, yx.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange for 255 in an int8 but got %v`, err)
+	}
+}
+
+func TestScanInvalidDestination(t *testing.T) {
+	yxdb := getYxdb(t, `AllNormalFields.yxdb`)
+	defer yxdb.Close()
+	yxdb.Next()
+
+	var dest struct{}
+	if err := yxdb.Scan(dest); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	if err := yx.Unmarshal(yxdb, &dest); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+// writeValuesAndNulls writes allNormalFields to a temporary file; the first record contains values and the second
+// contains only nulls.
+func writeValuesAndNulls(t *testing.T) string {
+	path := tempPath(t, `ValuesAndNulls.yxdb`)
+	writer, err := yx.CreateFile(path, allNormalFields)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	writer.WriteByteWithName(`ByteField`, 1)
+	writer.WriteBoolWithName(`BoolField`, true)
+	writer.WriteInt64WithName(`Int16Field`, 16)
+	writer.WriteInt64WithName(`Int32Field`, 32)
+	writer.WriteInt64WithName(`Int64Field`, 64)
+	writer.WriteFloat64WithName(`FixedDecimalField`, 123.45)
+	writer.WriteFloat64WithName(`FloatField`, 678.9)
+	writer.WriteFloat64WithName(`DoubleField`, 0.12345)
+	writer.WriteStringWithName(`StringField`, `A`)
+	writer.WriteStringWithName(`WStringField`, `AB`)
+	writer.WriteStringWithName(`V_StringShortField`, `ABC`)
+	writer.WriteStringWithName(`V_StringLongField`, strings.Repeat(`B`, 500))
+	writer.WriteStringWithName(`V_WStringShortField`, `XZY`)
+	writer.WriteStringWithName(`V_WStringLongField`, strings.Repeat(`W`, 500))
+	writer.WriteTimeWithName(`DateField`, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	writer.WriteTimeWithName(`DateTimeField`, time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC))
+	writer.WriteBlobWithName(`BlobField`, []byte{1, 2})
+	writer.WriteBlobWithName(`SpatialField`, []byte(strings.Repeat(`S`, 100)))
+	if err = writer.WriteRecord(); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if err = writer.WriteRecord(); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return path
+}
@@ -0,0 +1,214 @@
+package setters
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+const dateFormat = `2006-01-02`
+const dateTimeFormat = `2006-01-02 15:04:05`
+
+// ErrOutOfRange is returned by the setters of Int16, Int32, and FixedDecimal fields when a value does not fit in the
+// field. The field is left unchanged.
+var ErrOutOfRange = errors.New(`value out of range`)
+
+type BoolSetter func([]byte, bool)
+type ByteSetter func([]byte, byte)
+type Int64Setter func([]byte, int64) error
+type Float64Setter func([]byte, float64) error
+type TimeSetter func([]byte, time.Time)
+type StringSetter func([]byte, string)
+type NullSetter func([]byte)
+type BlobSetter func([]byte, []byte) []byte
+
+func NewBoolSetter(start int) BoolSetter {
+	return func(buffer []byte, value bool) {
+		if value {
+			buffer[start] = 1
+			return
+		}
+		buffer[start] = 0
+	}
+}
+
+func NewBoolNullSetter(start int) NullSetter {
+	return func(buffer []byte) {
+		buffer[start] = 2
+	}
+}
+
+func NewByteSetter(start int) ByteSetter {
+	return func(buffer []byte, value byte) {
+		buffer[start] = value
+		buffer[start+1] = 0
+	}
+}
+
+func NewInt16Setter(start int) Int64Setter {
+	return func(buffer []byte, value int64) error {
+		if value < math.MinInt16 || value > math.MaxInt16 {
+			return fmt.Errorf(`%w: %v does not fit in an Int16`, ErrOutOfRange, value)
+		}
+		binary.LittleEndian.PutUint16(buffer[start:start+2], uint16(value))
+		buffer[start+2] = 0
+		return nil
+	}
+}
+
+func NewInt32Setter(start int) Int64Setter {
+	return func(buffer []byte, value int64) error {
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			return fmt.Errorf(`%w: %v does not fit in an Int32`, ErrOutOfRange, value)
+		}
+		binary.LittleEndian.PutUint32(buffer[start:start+4], uint32(value))
+		buffer[start+4] = 0
+		return nil
+	}
+}
+
+func NewInt64Setter(start int) Int64Setter {
+	return func(buffer []byte, value int64) error {
+		binary.LittleEndian.PutUint64(buffer[start:start+8], uint64(value))
+		buffer[start+8] = 0
+		return nil
+	}
+}
+
+// NewFixedDecimalSetter writes values as text with scale decimal places. Values whose text is longer than fieldLength,
+// and NaN and infinite values, are rejected rather than truncated.
+func NewFixedDecimalSetter(start int, fieldLength int, scale int) Float64Setter {
+	return func(buffer []byte, value float64) error {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf(`%w: %v cannot be written to a FixedDecimal`, ErrOutOfRange, value)
+		}
+		str := strconv.FormatFloat(value, 'f', scale, 64)
+		if len(str) > fieldLength {
+			return fmt.Errorf(`%w: %v does not fit in a FixedDecimal of size %v`, ErrOutOfRange, str, fieldLength)
+		}
+		putString(buffer, start, fieldLength, []byte(str))
+		buffer[start+fieldLength] = 0
+		return nil
+	}
+}
+
+func NewFloatSetter(start int) Float64Setter {
+	return func(buffer []byte, value float64) error {
+		binary.LittleEndian.PutUint32(buffer[start:start+4], math.Float32bits(float32(value)))
+		buffer[start+4] = 0
+		return nil
+	}
+}
+
+func NewDoubleSetter(start int) Float64Setter {
+	return func(buffer []byte, value float64) error {
+		binary.LittleEndian.PutUint64(buffer[start:start+8], math.Float64bits(value))
+		buffer[start+8] = 0
+		return nil
+	}
+}
+
+func NewDateSetter(start int) TimeSetter {
+	return func(buffer []byte, value time.Time) {
+		copy(buffer[start:start+10], value.Format(dateFormat))
+		buffer[start+10] = 0
+	}
+}
+
+func NewDateTimeSetter(start int) TimeSetter {
+	return func(buffer []byte, value time.Time) {
+		copy(buffer[start:start+19], value.Format(dateTimeFormat))
+		buffer[start+19] = 0
+	}
+}
+
+func NewStringSetter(start int, fieldLength int) StringSetter {
+	return func(buffer []byte, value string) {
+		putString(buffer, start, fieldLength, []byte(value))
+		buffer[start+fieldLength] = 0
+	}
+}
+
+// NewWStringSetter writes values as UTF-16, truncated to fieldLength code units. A surrogate pair that would be cut in
+// half by the truncation is dropped entirely.
+func NewWStringSetter(start int, fieldLength int) StringSetter {
+	return func(buffer []byte, value string) {
+		chars := StringToUtf16Bytes(value)
+		if cut := fieldLength * 2; cut > 0 && len(chars) > cut && isHighSurrogate(binary.LittleEndian.Uint16(chars[cut-2:])) {
+			chars = chars[:cut-2]
+		}
+		putString(buffer, start, fieldLength*2, chars)
+		buffer[start+(fieldLength*2)] = 0
+	}
+}
+
+func isHighSurrogate(char uint16) bool {
+	return char >= 0xd800 && char < 0xdc00
+}
+
+// NewNullSetter clears the value bytes of a fixed-width field and sets the null flag that follows them.
+func NewNullSetter(start int, valueLength int) NullSetter {
+	return func(buffer []byte) {
+		zero(buffer[start : start+valueLength])
+		buffer[start+valueLength] = 1
+	}
+}
+
+// NewBlobSetter writes the fixed portion of a variable-length field at start and appends the value, if it does not
+// fit in the fixed portion, to the end of buffer. The (possibly reallocated) buffer is returned.
+//
+// A nil value is written as null.
+func NewBlobSetter(start int) BlobSetter {
+	return func(buffer []byte, value []byte) []byte {
+		fixed := buffer[start : start+4]
+		if value == nil {
+			binary.LittleEndian.PutUint32(fixed, 1)
+			return buffer
+		}
+		length := len(value)
+		if length == 0 {
+			binary.LittleEndian.PutUint32(fixed, 0)
+			return buffer
+		}
+		if length < 4 {
+			zero(fixed)
+			copy(fixed, value)
+			fixed[3] = byte(length << 4)
+			return buffer
+		}
+
+		binary.LittleEndian.PutUint32(fixed, uint32(len(buffer)-start))
+		if length < 128 {
+			buffer = append(buffer, byte(length<<1)|1)
+			return append(buffer, value...)
+		}
+		buffer = binary.LittleEndian.AppendUint32(buffer, uint32(length*2))
+		return append(buffer, value...)
+	}
+}
+
+// StringToUtf16Bytes encodes a string as little-endian UTF-16, the way Alteryx stores WString and V_WString fields.
+func StringToUtf16Bytes(value string) []byte {
+	chars := utf16.Encode([]rune(value))
+	bytes := make([]byte, len(chars)*2)
+	for index, char := range chars {
+		binary.LittleEndian.PutUint16(bytes[index*2:], char)
+	}
+	return bytes
+}
+
+func putString(buffer []byte, start int, fieldLength int, value []byte) {
+	field := buffer[start : start+fieldLength]
+	written := copy(field, value)
+	zero(field[written:])
+}
+
+func zero(buffer []byte) {
+	for i := range buffer {
+		buffer[i] = 0
+	}
+}
@@ -0,0 +1,154 @@
+package setters_test
+
+import (
+	"errors"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/extractors"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/setters"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetInt16(t *testing.T) {
+	buffer := make([]byte, 5)
+	setters.NewInt16Setter(2)(buffer, 10)
+	checkBuffer(t, buffer, []byte{0, 0, 10, 0, 0})
+}
+
+func TestSetInt16OutOfRange(t *testing.T) {
+	buffer := make([]byte, 3)
+	err := setters.NewInt16Setter(0)(buffer, 40000)
+	if !errors.Is(err, setters.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got: %v`, err)
+	}
+	checkBuffer(t, buffer, []byte{0, 0, 0})
+}
+
+func TestSetInt32OutOfRange(t *testing.T) {
+	buffer := make([]byte, 5)
+	err := setters.NewInt32Setter(0)(buffer, -1<<31-1)
+	if !errors.Is(err, setters.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got: %v`, err)
+	}
+	err = setters.NewInt32Setter(0)(buffer, -1<<31)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err)
+	}
+	checkBuffer(t, buffer, []byte{0, 0, 0, 128, 0})
+}
+
+func TestSetNullInt16(t *testing.T) {
+	buffer := []byte{0, 0, 10, 0, 0}
+	setters.NewNullSetter(2, 2)(buffer)
+	checkBuffer(t, buffer, []byte{0, 0, 0, 0, 1})
+}
+
+func TestSetInt64(t *testing.T) {
+	buffer := make([]byte, 9)
+	setters.NewInt64Setter(0)(buffer, -1)
+	checkBuffer(t, buffer, []byte{255, 255, 255, 255, 255, 255, 255, 255, 0})
+}
+
+func TestSetBool(t *testing.T) {
+	buffer := make([]byte, 1)
+	setters.NewBoolSetter(0)(buffer, true)
+	checkBuffer(t, buffer, []byte{1})
+	setters.NewBoolNullSetter(0)(buffer)
+	checkBuffer(t, buffer, []byte{2})
+}
+
+func TestSetFixedDecimal(t *testing.T) {
+	buffer := make([]byte, 11)
+	setters.NewFixedDecimalSetter(0, 10, 2)(buffer, 123.456)
+	checkBuffer(t, buffer, []byte{49, 50, 51, 46, 52, 54, 0, 0, 0, 0, 0})
+}
+
+func TestSetFixedDecimalOutOfRange(t *testing.T) {
+	buffer := make([]byte, 6)
+	err := setters.NewFixedDecimalSetter(0, 5, 2)(buffer, 12345.67)
+	if !errors.Is(err, setters.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got: %v`, err)
+	}
+	if err.Error() != `value out of range: 12345.67 does not fit in a FixedDecimal of size 5` {
+		t.Fatalf(`expected a message naming the value and size but got: %v`, err)
+	}
+	checkBuffer(t, buffer, []byte{0, 0, 0, 0, 0, 0})
+	err = setters.NewFixedDecimalSetter(0, 5, 2)(buffer, math.NaN())
+	if !errors.Is(err, setters.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got: %v`, err)
+	}
+	err = setters.NewFixedDecimalSetter(0, 5, 2)(buffer, -1.5)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err)
+	}
+	checkBuffer(t, buffer, []byte{45, 49, 46, 53, 48, 0})
+}
+
+func TestSetDateTime(t *testing.T) {
+	buffer := make([]byte, 20)
+	setters.NewDateTimeSetter(0)(buffer, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+	checkBuffer(t, buffer, []byte{50, 48, 50, 49, 45, 48, 49, 45, 48, 50, 32, 48, 51, 58, 48, 52, 58, 48, 53, 0})
+}
+
+func TestSetTruncatedString(t *testing.T) {
+	buffer := []byte{1, 1, 1, 1, 1, 1}
+	setters.NewStringSetter(0, 5)(buffer, `hello world`)
+	checkBuffer(t, buffer, []byte{104, 101, 108, 108, 111, 0})
+}
+
+func TestSetWStringKeepsSurrogatePairs(t *testing.T) {
+	buffer := make([]byte, 7)
+	setters.NewWStringSetter(0, 3)(buffer, "ab\U0001F600")
+	checkBuffer(t, buffer, []byte{97, 0, 98, 0, 0, 0, 0})
+	setters.NewWStringSetter(0, 3)(buffer, "a\U0001F600b")
+	checkBuffer(t, buffer, []byte{97, 0, 0x3d, 0xd8, 0x00, 0xde, 0})
+}
+
+func TestSetShortWString(t *testing.T) {
+	buffer := []byte{1, 1, 1, 1, 1, 1, 1}
+	setters.NewWStringSetter(0, 3)(buffer, `hi`)
+	checkBuffer(t, buffer, []byte{104, 0, 105, 0, 0, 0, 0})
+}
+
+func TestSetNullBlob(t *testing.T) {
+	buffer := setters.NewBlobSetter(0)(make([]byte, 4), nil)
+	checkBuffer(t, buffer, []byte{1, 0, 0, 0})
+}
+
+func TestSetEmptyBlob(t *testing.T) {
+	buffer := setters.NewBlobSetter(0)(make([]byte, 4), []byte{})
+	checkBuffer(t, buffer, []byte{0, 0, 0, 0})
+}
+
+func TestSetTinyBlob(t *testing.T) {
+	buffer := setters.NewBlobSetter(0)(make([]byte, 4), []byte{65, 66, 67})
+	checkBuffer(t, buffer, []byte{65, 66, 67, 48})
+}
+
+func TestSetSmallBlob(t *testing.T) {
+	buffer := setters.NewBlobSetter(0)(make([]byte, 8), []byte{1, 2, 3, 4, 5})
+	checkBuffer(t, buffer, []byte{8, 0, 0, 0, 0, 0, 0, 0, 11, 1, 2, 3, 4, 5})
+}
+
+func TestBlobsRoundTrip(t *testing.T) {
+	extract := extractors.NewBlobExtractor(4)
+	for _, length := range []int{0, 1, 3, 4, 127, 128, 1000} {
+		value := []byte(strings.Repeat(`A`, length))
+		buffer := setters.NewBlobSetter(4)(make([]byte, 12), value)
+		if actual := extract(buffer); !reflect.DeepEqual(actual, value) {
+			t.Fatalf(`expected %v bytes but got %v`, length, len(actual))
+		}
+	}
+}
+
+func TestStringToUtf16Bytes(t *testing.T) {
+	checkBuffer(t, setters.StringToUtf16Bytes(`Aé`), []byte{65, 0, 233, 0})
+}
+
+func checkBuffer(t *testing.T, actual []byte, expected []byte) {
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, actual)
+	}
+}
@@ -0,0 +1,127 @@
+package spatial
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	shapePoints   = 8
+	shapeLines    = 3
+	shapePolygons = 5
+)
+
+// FromGeoJSON translates a GeoJSON geometry into the binary format of SpatialObj fields, the reverse of ToGeoJSON.
+//
+// Point, MultiPoint, LineString, MultiLineString, Polygon, and MultiPolygon geometries are supported. Alteryx stores
+// the rings of every polygon of a MultiPolygon together, so they are read back by ToGeoJSON as a single MultiPolygon
+// of all the rings. Altitudes are discarded.
+func FromGeoJSON(geojson string) ([]byte, error) {
+	var obj struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	err := json.Unmarshal([]byte(geojson), &obj)
+	if err != nil {
+		return nil, err
+	}
+	switch obj.Type {
+	case `Point`:
+		var point [2]float64
+		err = json.Unmarshal(obj.Coordinates, &point)
+		return encodePoints([][2]float64{point}, err)
+	case `MultiPoint`:
+		var points [][2]float64
+		err = json.Unmarshal(obj.Coordinates, &points)
+		return encodePoints(points, err)
+	case `LineString`:
+		var line [][2]float64
+		err = json.Unmarshal(obj.Coordinates, &line)
+		return encodeParts(shapeLines, [][][2]float64{line}, err)
+	case `MultiLineString`:
+		var lines [][][2]float64
+		err = json.Unmarshal(obj.Coordinates, &lines)
+		return encodeParts(shapeLines, lines, err)
+	case `Polygon`:
+		var rings [][][2]float64
+		err = json.Unmarshal(obj.Coordinates, &rings)
+		return encodeParts(shapePolygons, rings, err)
+	case `MultiPolygon`:
+		var polygons [][][][2]float64
+		err = json.Unmarshal(obj.Coordinates, &polygons)
+		var rings [][][2]float64
+		for _, polygon := range polygons {
+			rings = append(rings, polygon...)
+		}
+		return encodeParts(shapePolygons, rings, err)
+	}
+	return nil, fmt.Errorf(`GeoJSON type '%v' cannot be stored in a spatial object`, obj.Type)
+}
+
+var errNoPoints = errors.New(`the GeoJSON geometry has no points`)
+
+// encodePoints encodes points, unless err is set by reading them.
+func encodePoints(points [][2]float64, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, errNoPoints
+	}
+	value := appendShapeHeader(nil, shapePoints, points)
+	value = binary.LittleEndian.AppendUint32(value, uint32(len(points)))
+	for _, point := range points {
+		value = appendCoord(value, point)
+	}
+	return value, nil
+}
+
+// encodeParts encodes lines or polygon rings, unless err is set by reading them. The header lists the index of the
+// first point of each part.
+func encodeParts(shapeType int, parts [][][2]float64, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	var points [][2]float64
+	for _, part := range parts {
+		if len(part) == 0 {
+			return nil, errNoPoints
+		}
+		points = append(points, part...)
+	}
+	if len(points) == 0 {
+		return nil, errNoPoints
+	}
+	value := appendShapeHeader(nil, shapeType, points)
+	value = binary.LittleEndian.AppendUint32(value, uint32(len(parts)))
+	value = binary.LittleEndian.AppendUint32(value, uint32(len(points)))
+	start := 0
+	for _, part := range parts {
+		value = binary.LittleEndian.AppendUint32(value, uint32(start))
+		start += len(part)
+	}
+	for _, point := range points {
+		value = appendCoord(value, point)
+	}
+	return value, nil
+}
+
+// appendShapeHeader appends the shape type and the bounding box of points.
+func appendShapeHeader(value []byte, shapeType int, points [][2]float64) []byte {
+	value = binary.LittleEndian.AppendUint32(value, uint32(shapeType))
+	minimum, maximum := points[0], points[0]
+	for _, point := range points[1:] {
+		minimum = [2]float64{math.Min(minimum[0], point[0]), math.Min(minimum[1], point[1])}
+		maximum = [2]float64{math.Max(maximum[0], point[0]), math.Max(maximum[1], point[1])}
+	}
+	value = appendCoord(value, minimum)
+	return appendCoord(value, maximum)
+}
+
+func appendCoord(value []byte, coord [2]float64) []byte {
+	value = binary.LittleEndian.AppendUint64(value, math.Float64bits(coord[0]))
+	return binary.LittleEndian.AppendUint64(value, math.Float64bits(coord[1]))
+}
@@ -17,22 +17,31 @@ func ToGeoJSON(value []byte) (string, error) {
 	if value == nil {
 		return ``, nil
 	}
+	obj, err := parse(value)
+	if err != nil {
+		return ``, err
+	}
+	raw, err := json.Marshal(obj)
+	return string(raw), err
+}
+
+func parse(value []byte) (*geometry, error) {
 	if len(value) < 20 {
-		return ``, errors.New(`bytes are not a spatial object`)
+		return nil, errors.New(`bytes are not a spatial object`)
 	}
 	objType := int(binary.LittleEndian.Uint32(value[0:4]))
 	switch objType {
 	case 8:
-		return parsePoints(value)
+		return parsePoints(value), nil
 	case 3:
-		return parseLines(value)
+		return parseLines(value), nil
 	case 5:
-		return parsePoly(value)
+		return parsePoly(value), nil
 	}
-	return ``, errors.New(`bytes are not a spatial object`)
+	return nil, errors.New(`bytes are not a spatial object`)
 }
 
-func parsePoints(value []byte) (string, error) {
+func parsePoints(value []byte) *geometry {
 	totalPoints := int(binary.LittleEndian.Uint32(value[36:40]))
 	if totalPoints == 1 {
 		return parseSinglePoint(value)
@@ -40,36 +49,36 @@ func parsePoints(value []byte) (string, error) {
 	return parseMultiPoint(totalPoints, value)
 }
 
-func parseSinglePoint(value []byte) (string, error) {
-	return geoJSON(`Point`, getCoordAt(value, 40))
+func parseSinglePoint(value []byte) *geometry {
+	return &geometry{Type: `Point`, Coordinates: getCoordAt(value, 40)}
 }
 
-func parseMultiPoint(totalPoints int, value []byte) (string, error) {
+func parseMultiPoint(totalPoints int, value []byte) *geometry {
 	points := make([][2]float64, 0, totalPoints)
 	i := 40
 	for i < len(value) {
 		points = append(points, getCoordAt(value, i))
 		i += bytesPerPoint
 	}
-	return geoJSON(`MultiPoint`, points)
+	return &geometry{Type: `MultiPoint`, Coordinates: points}
 }
 
-func parseLines(value []byte) (string, error) {
+func parseLines(value []byte) *geometry {
 	lines := parseMultiPointObject(value)
 
 	if len(lines) == 1 {
-		return geoJSON(`LineString`, lines[0])
+		return &geometry{Type: `LineString`, Coordinates: lines[0]}
 	}
-	return geoJSON(`MultiLineString`, lines)
+	return &geometry{Type: `MultiLineString`, Coordinates: lines}
 }
 
-func parsePoly(value []byte) (string, error) {
+func parsePoly(value []byte) *geometry {
 	poly := parseMultiPointObject(value)
 
 	if len(poly) == 1 {
-		return geoJSON(`Polygon`, poly)
+		return &geometry{Type: `Polygon`, Coordinates: poly}
 	}
-	return geoJSON(`MultiPolygon`, []any{poly})
+	return &geometry{Type: `MultiPolygon`, Coordinates: [][][][2]float64{poly}}
 }
 
 func parseMultiPointObject(value []byte) [][][2]float64 {
@@ -111,15 +120,7 @@ func getCoordAt(value []byte, i int) [2]float64 {
 	return [2]float64{lng, lat}
 }
 
-type returnObj struct {
+type geometry struct {
 	Type        string `json:"type"`
 	Coordinates any    `json:"coordinates"`
 }
-
-func geoJSON(objType string, data any) (string, error) {
-	raw, err := json.Marshal(returnObj{
-		Type:        objType,
-		Coordinates: data,
-	})
-	return string(raw), err
-}
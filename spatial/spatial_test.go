@@ -1,9 +1,12 @@
 package spatial_test
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"github.com/tlarsendataguy-yxdb/yxdb-go"
 	"github.com/tlarsendataguy-yxdb/yxdb-go/spatial"
+	"math"
 	"strings"
 	"testing"
 )
@@ -116,3 +119,103 @@ func testSpatial(path string, expected string) error {
 	}
 	return nil
 }
+
+func TestPointToWKB(t *testing.T) {
+	wkb := readWKB(t, `../test_files/point.yxdb`)
+	if len(wkb) != 21 || wkb[0] != 1 || binary.LittleEndian.Uint32(wkb[1:5]) != 1 {
+		t.Fatalf(`expected a little-endian WKB point but got %v`, wkb)
+	}
+	x := math.Float64frombits(binary.LittleEndian.Uint64(wkb[5:13]))
+	y := math.Float64frombits(binary.LittleEndian.Uint64(wkb[13:21]))
+	if x != -96.679688 || y != 37.230328 {
+		t.Fatalf(`expected (-96.679688, 37.230328) but got (%v, %v)`, x, y)
+	}
+}
+
+func TestPolyWithHoleToWKB(t *testing.T) {
+	wkb := readWKB(t, `../test_files/multi-poly-holes.yxdb`)
+	geometryType := binary.LittleEndian.Uint32(wkb[1:5])
+	polygons := binary.LittleEndian.Uint32(wkb[5:9])
+	polygonType := binary.LittleEndian.Uint32(wkb[10:14])
+	rings := binary.LittleEndian.Uint32(wkb[14:18])
+	if geometryType != 6 || polygons != 1 || polygonType != 3 || rings != 3 {
+		t.Fatalf(`expected a multipolygon of 1 polygon with 3 rings but got types %v and %v with %v polygons and %v rings`, geometryType, polygonType, polygons, rings)
+	}
+	// 18 header bytes, then 3 rings of 5 points, each with a 4 byte point count
+	if len(wkb) != 18+3*(4+5*16) {
+		t.Fatalf(`expected %v bytes but got %v`, 18+3*(4+5*16), len(wkb))
+	}
+}
+
+func TestNullToWKB(t *testing.T) {
+	wkb, err := spatial.ToWKB(nil)
+	if wkb != nil || err != nil {
+		t.Fatalf(`expected nil but got %v and %v`, wkb, err)
+	}
+	if _, err = spatial.ToWKB([]byte{1, 0, 0, 0}); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+func readWKB(t *testing.T, path string) []byte {
+	reader, err := yxdb.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer reader.Close()
+	reader.Next()
+	wkb, err := spatial.ToWKB(reader.ReadBlobWithIndex(1))
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return wkb
+}
+
+func TestFromGeoJSON(t *testing.T) {
+	paths := []string{`point`, `multi-point`, `line`, `multi-line`, `poly`, `multi-poly`, `multi-poly-holes`}
+	for _, path := range paths {
+		reader, err := yxdb.ReadFile(`../test_files/` + path + `.yxdb`)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		reader.Next()
+		blob := reader.ReadBlobWithIndex(1)
+		_ = reader.Close()
+		geo, err := spatial.ToGeoJSON(blob)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		actual, err := spatial.FromGeoJSON(geo)
+		if err != nil {
+			t.Fatalf(`expected no error for %v but got: %v`, path, err.Error())
+		}
+		if !bytes.Equal(actual, blob) {
+			t.Fatalf("expected %v to round trip to\n%v\nbut got\n%v", path, blob, actual)
+		}
+	}
+}
+
+func TestFromGeoJSONMultiPolygon(t *testing.T) {
+	geo := `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,7],[5,5]]]]}`
+	value, err := spatial.FromGeoJSON(geo)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	expected := `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]],[[5,5],[6,5],[6,7],[5,5]]]]}`
+	if actual, _ := spatial.ToGeoJSON(value); actual != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, actual)
+	}
+}
+
+func TestInvalidGeoJSON(t *testing.T) {
+	for _, geo := range []string{
+		`{"type":"GeometryCollection","geometries":[]}`,
+		`{"type":"LineString","coordinates":[]}`,
+		`{"type":"Point","coordinates":"a"}`,
+		`not json`,
+	} {
+		if _, err := spatial.FromGeoJSON(geo); err == nil {
+			t.Fatalf(`expected an error for %v but got none`, geo)
+		}
+	}
+}
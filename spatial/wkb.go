@@ -0,0 +1,92 @@
+package spatial
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	wkbPoint           = 1
+	wkbLineString      = 2
+	wkbPolygon         = 3
+	wkbMultiPoint      = 4
+	wkbMultiLineString = 5
+	wkbMultiPolygon    = 6
+)
+
+// ToWKB translates SpatialObj fields into little-endian Well-Known Binary.
+//
+// The geometry types match those produced by ToGeoJSON. A nil value returns nil.
+func ToWKB(value []byte) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	obj, err := parse(value)
+	if err != nil {
+		return nil, err
+	}
+	switch obj.Type {
+	case `Point`:
+		return appendPoint(nil, obj.Coordinates.([2]float64)), nil
+	case `MultiPoint`:
+		points := obj.Coordinates.([][2]float64)
+		wkb := appendHeader(nil, wkbMultiPoint, len(points))
+		for _, point := range points {
+			wkb = appendPoint(wkb, point)
+		}
+		return wkb, nil
+	case `LineString`:
+		return appendLineString(nil, obj.Coordinates.([][2]float64)), nil
+	case `MultiLineString`:
+		lines := obj.Coordinates.([][][2]float64)
+		wkb := appendHeader(nil, wkbMultiLineString, len(lines))
+		for _, line := range lines {
+			wkb = appendLineString(wkb, line)
+		}
+		return wkb, nil
+	case `Polygon`:
+		return appendPolygon(nil, obj.Coordinates.([][][2]float64)), nil
+	default:
+		polys := obj.Coordinates.([][][][2]float64)
+		wkb := appendHeader(nil, wkbMultiPolygon, len(polys))
+		for _, poly := range polys {
+			wkb = appendPolygon(wkb, poly)
+		}
+		return wkb, nil
+	}
+}
+
+// appendHeader appends the byte order, geometry type, and element count of a geometry.
+func appendHeader(wkb []byte, geometryType uint32, count int) []byte {
+	wkb = append(wkb, 1)
+	wkb = binary.LittleEndian.AppendUint32(wkb, geometryType)
+	return binary.LittleEndian.AppendUint32(wkb, uint32(count))
+}
+
+func appendPoint(wkb []byte, point [2]float64) []byte {
+	wkb = append(wkb, 1)
+	wkb = binary.LittleEndian.AppendUint32(wkb, wkbPoint)
+	return appendCoords(wkb, [][2]float64{point})
+}
+
+func appendLineString(wkb []byte, line [][2]float64) []byte {
+	wkb = appendHeader(wkb, wkbLineString, len(line))
+	return appendCoords(wkb, line)
+}
+
+func appendPolygon(wkb []byte, rings [][][2]float64) []byte {
+	wkb = appendHeader(wkb, wkbPolygon, len(rings))
+	for _, ring := range rings {
+		wkb = binary.LittleEndian.AppendUint32(wkb, uint32(len(ring)))
+		wkb = appendCoords(wkb, ring)
+	}
+	return wkb
+}
+
+func appendCoords(wkb []byte, coords [][2]float64) []byte {
+	for _, coord := range coords {
+		wkb = binary.LittleEndian.AppendUint64(wkb, math.Float64bits(coord[0]))
+		wkb = binary.LittleEndian.AppendUint64(wkb, math.Float64bits(coord[1]))
+	}
+	return wkb
+}
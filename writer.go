@@ -0,0 +1,483 @@
+package yxdb
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/bufrecord"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/setters"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const yxdbFileId = 0x00440204
+const yxdbCompressionVersion = 1
+
+// ErrOutOfRange is returned by Writer.WriteRecord and Writer.Close when a value does not fit in its field, and by
+// Reader.Scan when an integer does not fit in the type of its struct field.
+var ErrOutOfRange = setters.ErrOutOfRange
+
+// A Writer is the interface that creates .yxdb files.
+//
+// Instantiate a Writer using the CreateFile and WriteStream functions.
+// Set the values of a record using the WriteXxxWithIndex and WriteXxxWithName methods and then call WriteRecord to
+// add the record to the file. Fields that are not set before calling WriteRecord are written as null.
+// The file is not valid until Close has been called.
+//
+// Integers outside the range of an Int16 or Int32 field, and numbers with more characters than the size of a
+// FixedDecimal field, are not truncated. Instead the Writer fails: the record and every later record are not written,
+// and WriteRecord and Close return an error wrapping ErrOutOfRange.
+type Writer interface {
+	io.Closer
+
+	// ListFields returns the list of fields contained in the .yxdb file and their data type.
+	ListFields() []yxrecord.YxdbField
+
+	// NumRecords returns the number of records that have been written.
+	NumRecords() int64
+
+	// MetaInfoStr returns the XML metadata, as a string, of the fields contained in the .yxdb file.
+	MetaInfoStr() string
+
+	// WriteRecord adds the current field values to the file as a new record and resets all fields to null.
+	WriteRecord() error
+
+	// WriteNullWithIndex sets the field at the specified field index to null.
+	//
+	// If the index is not valid, WriteNullWithIndex will panic.
+	WriteNullWithIndex(int)
+
+	// WriteNullWithName sets the field with the specified name to null.
+	//
+	// If the name is not valid, WriteNullWithName will panic.
+	WriteNullWithName(string)
+
+	// WriteByteWithIndex sets a byte field at the specified field index.
+	//
+	// If the field at the specified index is not a byte field, WriteByteWithIndex will panic.
+	WriteByteWithIndex(int, byte)
+
+	// WriteByteWithName sets a byte field with the specified name.
+	//
+	// If the name is not valid or the field with the specified name is not a byte field, WriteByteWithName will panic.
+	WriteByteWithName(string, byte)
+
+	// WriteBoolWithIndex sets a boolean field at the specified field index.
+	//
+	// If the field at the specified index is not a boolean field, WriteBoolWithIndex will panic.
+	WriteBoolWithIndex(int, bool)
+
+	// WriteBoolWithName sets a boolean field with the specified name.
+	//
+	// If the name is not valid or the field with the specified name is not a boolean field, WriteBoolWithName will panic.
+	WriteBoolWithName(string, bool)
+
+	// WriteInt64WithIndex sets an integer field at the specified field index.
+	//
+	// If the field at the specified index is not an integer field, WriteInt64WithIndex will panic.
+	WriteInt64WithIndex(int, int64)
+
+	// WriteInt64WithName sets an integer field with the specified name.
+	//
+	// If the name is not valid or the field with the specified name is not an integer field, WriteInt64WithName will panic.
+	WriteInt64WithName(string, int64)
+
+	// WriteFloat64WithIndex sets a numeric field at the specified field index.
+	//
+	// If the field at the specified index is not a numeric field, WriteFloat64WithIndex will panic.
+	WriteFloat64WithIndex(int, float64)
+
+	// WriteFloat64WithName sets a numeric field with the specified name.
+	//
+	// If the name is not valid or the field with the specified name is not a numeric field, WriteFloat64WithName will panic.
+	WriteFloat64WithName(string, float64)
+
+	// WriteStringWithIndex sets a string field at the specified field index.
+	//
+	// Values longer than the size of a String or WString field are truncated.
+	// If the field at the specified index is not a string field, WriteStringWithIndex will panic.
+	WriteStringWithIndex(int, string)
+
+	// WriteStringWithName sets a string field with the specified name.
+	//
+	// Values longer than the size of a String or WString field are truncated.
+	// If the name is not valid or the field with the specified name is not a string field, WriteStringWithName will panic.
+	WriteStringWithName(string, string)
+
+	// WriteTimeWithIndex sets a date/datetime field at the specified field index.
+	//
+	// If the field at the specified index is not a date/datetime field, WriteTimeWithIndex will panic.
+	WriteTimeWithIndex(int, time.Time)
+
+	// WriteTimeWithName sets a date/datetime field with the specified name.
+	//
+	// If the name is not valid or the field with the specified name is not a date/datetime field, WriteTimeWithName will panic.
+	WriteTimeWithName(string, time.Time)
+
+	// WriteBlobWithIndex sets a binary field at the specified field index. A nil value is written as null.
+	//
+	// If the field at the specified index is not a binary field, WriteBlobWithIndex will panic.
+	WriteBlobWithIndex(int, []byte)
+
+	// WriteBlobWithName sets a binary field with the specified name. A nil value is written as null.
+	//
+	// If the name is not valid or the field with the specified name is not a binary field, WriteBlobWithName will panic.
+	WriteBlobWithName(string, []byte)
+}
+
+// CreateFile instantiates a Writer that creates a new .yxdb file at the specified path with the specified fields.
+//
+// If the file already exists it is truncated. If the file cannot be created or the field list is not valid, CreateFile
+// will return an error.
+func CreateFile(path string, fields []metafield.MetaInfoField) (Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := newWriter(file, fields)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	writer.closer = file
+	return writer, nil
+}
+
+// WriteStream instantiates a Writer that writes a .yxdb file with the specified fields to the specified
+// io.WriteSeeker, starting at the stream's current position.
+//
+// The header is rewritten when the Writer is closed, so the stream must support seeking. Closing the Writer does not
+// close the stream. If the stream encounters an error or the field list is not valid, WriteStream will return an error.
+func WriteStream(stream io.WriteSeeker, fields []metafield.MetaInfoField) (Writer, error) {
+	return newWriter(stream, fields)
+}
+
+type w struct {
+	stream       io.WriteSeeker
+	closer       io.Closer
+	startAt      int64
+	metaInfoStr  string
+	record       *yxrecord.YxdbRecordBuilder
+	recordWriter *bufrecord.BufferedRecordWriter
+	closed       bool
+	err          error
+}
+
+func newWriter(stream io.WriteSeeker, fields []metafield.MetaInfoField) (*w, error) {
+	err := validateFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	record, err := yxrecord.BuilderFromFieldList(fieldsAsWritten(fields))
+	if err != nil {
+		return nil, err
+	}
+	startAt, err := stream.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	writer := &w{
+		stream:      stream,
+		startAt:     startAt,
+		metaInfoStr: generateMetaInfo(fields),
+		record:      record,
+	}
+	err = writer.writeHeaderAndMetaInfo()
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (w *w) ListFields() []yxrecord.YxdbField {
+	return w.record.Fields
+}
+
+func (w *w) NumRecords() int64 {
+	return w.recordWriter.TotalRecords
+}
+
+func (w *w) MetaInfoStr() string {
+	return w.metaInfoStr
+}
+
+func (w *w) WriteRecord() error {
+	if w.closed {
+		return errors.New(`cannot write a record to a closed writer`)
+	}
+	if w.err != nil {
+		return w.err
+	}
+	return w.recordWriter.WriteRecord(w.record.Build())
+}
+
+func (w *w) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.finish()
+	if w.closer != nil {
+		closeErr := w.closer.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+	if w.err != nil {
+		return w.err
+	}
+	return err
+}
+
+// fail records the first error returned by a setter, which WriteRecord and Close return from then on.
+func (w *w) fail(name string, err error) {
+	if err != nil && w.err == nil {
+		w.err = fmt.Errorf(`field '%v' of record %v: %w`, name, w.recordWriter.TotalRecords+1, err)
+	}
+}
+
+func (w *w) WriteNullWithIndex(index int) {
+	w.record.SetNullWithIndex(index)
+}
+
+func (w *w) WriteNullWithName(name string) {
+	w.record.SetNullWithName(name)
+}
+
+func (w *w) WriteByteWithIndex(index int, value byte) {
+	w.record.SetByteWithIndex(index, value)
+}
+
+func (w *w) WriteByteWithName(name string, value byte) {
+	w.record.SetByteWithName(name, value)
+}
+
+func (w *w) WriteBoolWithIndex(index int, value bool) {
+	w.record.SetBoolWithIndex(index, value)
+}
+
+func (w *w) WriteBoolWithName(name string, value bool) {
+	w.record.SetBoolWithName(name, value)
+}
+
+func (w *w) WriteInt64WithIndex(index int, value int64) {
+	err := w.record.SetInt64WithIndex(index, value)
+	if err != nil {
+		w.fail(w.record.Fields[index].Name, err)
+	}
+}
+
+func (w *w) WriteInt64WithName(name string, value int64) {
+	w.fail(name, w.record.SetInt64WithName(name, value))
+}
+
+func (w *w) WriteFloat64WithIndex(index int, value float64) {
+	err := w.record.SetFloat64WithIndex(index, value)
+	if err != nil {
+		w.fail(w.record.Fields[index].Name, err)
+	}
+}
+
+func (w *w) WriteFloat64WithName(name string, value float64) {
+	w.fail(name, w.record.SetFloat64WithName(name, value))
+}
+
+func (w *w) WriteStringWithIndex(index int, value string) {
+	w.record.SetStringWithIndex(index, value)
+}
+
+func (w *w) WriteStringWithName(name string, value string) {
+	w.record.SetStringWithName(name, value)
+}
+
+func (w *w) WriteTimeWithIndex(index int, value time.Time) {
+	w.record.SetTimeWithIndex(index, value)
+}
+
+func (w *w) WriteTimeWithName(name string, value time.Time) {
+	w.record.SetTimeWithName(name, value)
+}
+
+func (w *w) WriteBlobWithIndex(index int, value []byte) {
+	w.record.SetBlobWithIndex(index, value)
+}
+
+func (w *w) WriteBlobWithName(name string, value []byte) {
+	w.record.SetBlobWithName(name, value)
+}
+
+func (w *w) writeHeaderAndMetaInfo() error {
+	_, err := w.stream.Write(make([]byte, headerSize))
+	if err != nil {
+		return err
+	}
+	metaInfoBytes := setters.StringToUtf16Bytes(w.metaInfoStr)
+	metaInfoBytes = append(metaInfoBytes, 0, 0)
+	_, err = w.stream.Write(metaInfoBytes)
+	if err != nil {
+		return err
+	}
+	w.recordWriter = bufrecord.NewBufferedRecordWriter(w.stream, int64(headerSize+len(metaInfoBytes)))
+	return nil
+}
+
+func (w *w) finish() error {
+	err := w.recordWriter.Flush()
+	if err != nil {
+		return err
+	}
+	recordBlockIndexPos := w.recordWriter.Position()
+	err = w.writeRecordBlockIndex()
+	if err != nil {
+		return err
+	}
+	_, err = w.stream.Seek(w.startAt, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = w.stream.Write(w.generateHeader(recordBlockIndexPos))
+	if err != nil {
+		return err
+	}
+	_, err = w.stream.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (w *w) writeRecordBlockIndex() error {
+	blocks := w.recordWriter.RecordBlockIndex
+	index := make([]byte, 4+(len(blocks)*8))
+	binary.LittleEndian.PutUint32(index[0:4], uint32(len(blocks)))
+	for i, position := range blocks {
+		binary.LittleEndian.PutUint64(index[4+(i*8):12+(i*8)], uint64(position))
+	}
+	_, err := w.stream.Write(index)
+	return err
+}
+
+func (w *w) generateHeader(recordBlockIndexPos int64) []byte {
+	header := Header{
+		Description:         `Alteryx Database File`,
+		FileId:              yxdbFileId,
+		CreationDate:        time.Now(),
+		MetaInfoLength:      uint32(len(setters.StringToUtf16Bytes(w.metaInfoStr))/2 + 1),
+		RecordBlockIndexPos: recordBlockIndexPos,
+		NumRecords:          w.recordWriter.TotalRecords,
+		CompressionVersion:  yxdbCompressionVersion,
+	}
+	return header.toBytes()
+}
+
+func validateFields(fields []metafield.MetaInfoField) error {
+	names := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field.Name == `` {
+			return errors.New(`field names cannot be empty`)
+		}
+		if names[field.Name] {
+			return fmt.Errorf(`field '%v' is defined more than once`, field.Name)
+		}
+		names[field.Name] = true
+		switch field.Type {
+		case `FixedDecimal`, `String`, `WString`:
+			if field.Size <= 0 {
+				return fmt.Errorf(`field '%v' of type %v requires a size greater than 0`, field.Name, field.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func generateMetaInfo(fields []metafield.MetaInfoField) string {
+	builder := strings.Builder{}
+	builder.WriteString("<RecordInfo>\n")
+	for _, field := range fields {
+		builder.WriteString("\t<Field")
+		for _, attr := range fieldAttrs(field) {
+			builder.WriteString(fmt.Sprintf(` %v="%v"`, attr.Name.Local, escapeAttr(attr.Value)))
+		}
+		builder.WriteString("/>\n")
+	}
+	builder.WriteString("</RecordInfo>\n")
+	return builder.String()
+}
+
+// fieldAttrs lists the attributes of a field in alphabetical order, the way Alteryx writes them.
+func fieldAttrs(field metafield.MetaInfoField) []xml.Attr {
+	attrs := []xml.Attr{
+		{Name: xml.Name{Local: `name`}, Value: field.Name},
+		{Name: xml.Name{Local: `type`}, Value: field.Type},
+	}
+	switch field.Type {
+	case `FixedDecimal`:
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `scale`}, Value: strconv.Itoa(field.Scale)})
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `size`}, Value: strconv.Itoa(field.Size)})
+	case `String`, `WString`:
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `size`}, Value: strconv.Itoa(field.Size)})
+	case `V_String`, `V_WString`, `Blob`, `SpatialObj`:
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `size`}, Value: strconv.Itoa(varFieldSize(field))})
+	}
+	if field.Description != `` {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `description`}, Value: field.Description})
+	}
+	if field.Source != `` {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `source`}, Value: field.Source})
+	}
+	for _, attr := range field.OtherAttrs {
+		if !hasAttr(attrs, attr.Name.Local) {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+	return attrs
+}
+
+func hasAttr(attrs []xml.Attr, name string) bool {
+	for _, attr := range attrs {
+		if attr.Name.Local == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldsAsWritten returns fields with the sizes written to the MetaInfo, so that ListFields reports the fields the way
+// a Reader of the file does.
+func fieldsAsWritten(fields []metafield.MetaInfoField) []metafield.MetaInfoField {
+	written := make([]metafield.MetaInfoField, len(fields))
+	for index, field := range fields {
+		switch field.Type {
+		case `FixedDecimal`, `String`, `WString`:
+		case `V_String`, `V_WString`, `Blob`, `SpatialObj`:
+			field.Size = varFieldSize(field)
+		default:
+			field.Size = 0
+		}
+		written[index] = field
+	}
+	return written
+}
+
+func varFieldSize(field metafield.MetaInfoField) int {
+	if field.Size > 0 {
+		return field.Size
+	}
+	if field.Type == `V_WString` {
+		return 1073741823
+	}
+	return 2147483647
+}
+
+func escapeAttr(value string) string {
+	builder := strings.Builder{}
+	_ = xml.EscapeText(&builder, []byte(value))
+	return builder.String()
+}
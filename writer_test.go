@@ -0,0 +1,321 @@
+package yxdb_test
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var allNormalFields = []metafield.MetaInfoField{
+	{Name: `ByteField`, Type: `Byte`},
+	{Name: `BoolField`, Type: `Bool`},
+	{Name: `Int16Field`, Type: `Int16`},
+	{Name: `Int32Field`, Type: `Int32`},
+	{Name: `Int64Field`, Type: `Int64`},
+	{Name: `FixedDecimalField`, Type: `FixedDecimal`, Size: 19, Scale: 6},
+	{Name: `FloatField`, Type: `Float`},
+	{Name: `DoubleField`, Type: `Double`},
+	{Name: `StringField`, Type: `String`, Size: 64},
+	{Name: `WStringField`, Type: `WString`, Size: 64},
+	{Name: `V_StringShortField`, Type: `V_String`, Size: 1000},
+	{Name: `V_StringLongField`, Type: `V_String`},
+	{Name: `V_WStringShortField`, Type: `V_WString`, Size: 10},
+	{Name: `V_WStringLongField`, Type: `V_WString`},
+	{Name: `DateField`, Type: `Date`},
+	{Name: `DateTimeField`, Type: `DateTime`},
+	{Name: `BlobField`, Type: `Blob`},
+	{Name: `SpatialField`, Type: `SpatialObj`},
+}
+
+func TestWriteAllFieldTypes(t *testing.T) {
+	path := tempPath(t, `AllFields.yxdb`)
+	writer, err := yx.CreateFile(path, allNormalFields)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	writer.WriteByteWithName(`ByteField`, 1)
+	writer.WriteBoolWithName(`BoolField`, true)
+	writer.WriteInt64WithName(`Int16Field`, 16)
+	writer.WriteInt64WithName(`Int32Field`, 32)
+	writer.WriteInt64WithName(`Int64Field`, 64)
+	writer.WriteFloat64WithName(`FixedDecimalField`, 123.45)
+	writer.WriteFloat64WithName(`FloatField`, 678.9)
+	writer.WriteFloat64WithName(`DoubleField`, 0.12345)
+	writer.WriteStringWithName(`StringField`, `A`)
+	writer.WriteStringWithName(`WStringField`, `AB`)
+	writer.WriteStringWithName(`V_StringShortField`, `ABC`)
+	writer.WriteStringWithName(`V_StringLongField`, strings.Repeat(`B`, 500))
+	writer.WriteStringWithName(`V_WStringShortField`, `XZY`)
+	writer.WriteStringWithName(`V_WStringLongField`, strings.Repeat(`W`, 500))
+	writer.WriteTimeWithName(`DateField`, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	writer.WriteTimeWithName(`DateTimeField`, time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC))
+	writer.WriteBlobWithName(`BlobField`, []byte{1, 2})
+	writer.WriteBlobWithName(`SpatialField`, []byte(strings.Repeat(`S`, 100)))
+	err = writer.WriteRecord()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	err = writer.WriteRecord()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+
+	yxdb := openYxdb(t, path)
+	defer yxdb.Close()
+	if yxdb.NumRecords() != 2 {
+		t.Fatalf(`expected 2 records but got %v`, yxdb.NumRecords())
+	}
+	if header := yxdb.Header(); header.FileId != 0x00440204 || time.Since(header.CreationDate) > time.Minute {
+		t.Fatalf(`unexpected header values %+v`, header)
+	}
+	if !reflect.DeepEqual(yxdb.ListFields(), writer.ListFields()) {
+		t.Fatalf(`expected %v but got %v`, writer.ListFields(), yxdb.ListFields())
+	}
+
+	yxdb.Next()
+	checkField(t, byte(1), false, func() (interface{}, bool) { return yxdb.ReadByteWithName(`ByteField`) })
+	checkField(t, true, false, func() (interface{}, bool) { return yxdb.ReadBoolWithName(`BoolField`) })
+	checkField(t, int64(16), false, func() (interface{}, bool) { return yxdb.ReadInt64WithName(`Int16Field`) })
+	checkField(t, int64(32), false, func() (interface{}, bool) { return yxdb.ReadInt64WithName(`Int32Field`) })
+	checkField(t, int64(64), false, func() (interface{}, bool) { return yxdb.ReadInt64WithName(`Int64Field`) })
+	checkField(t, 123.45, false, func() (interface{}, bool) { return yxdb.ReadFloat64WithName(`FixedDecimalField`) })
+	checkField(t, float64(float32(678.9)), false, func() (interface{}, bool) { return yxdb.ReadFloat64WithName(`FloatField`) })
+	checkField(t, 0.12345, false, func() (interface{}, bool) { return yxdb.ReadFloat64WithName(`DoubleField`) })
+	checkField(t, `A`, false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`StringField`) })
+	checkField(t, `AB`, false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`WStringField`) })
+	checkField(t, `ABC`, false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`V_StringShortField`) })
+	checkField(t, strings.Repeat(`B`, 500), false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`V_StringLongField`) })
+	checkField(t, `XZY`, false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`V_WStringShortField`) })
+	checkField(t, strings.Repeat(`W`, 500), false, func() (interface{}, bool) { return yxdb.ReadStringWithName(`V_WStringLongField`) })
+	checkField(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), false, func() (interface{}, bool) { return yxdb.ReadTimeWithName(`DateField`) })
+	checkField(t, time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC), false, func() (interface{}, bool) { return yxdb.ReadTimeWithName(`DateTimeField`) })
+	if blob := yxdb.ReadBlobWithName(`BlobField`); !reflect.DeepEqual(blob, []byte{1, 2}) {
+		t.Fatalf(`expected [1 2] but got %v`, blob)
+	}
+	if blob := yxdb.ReadBlobWithName(`SpatialField`); string(blob) != strings.Repeat(`S`, 100) {
+		t.Fatalf(`expected 100 S's but got %v`, string(blob))
+	}
+
+	yxdb.Next()
+	checkField(t, byte(0), true, func() (interface{}, bool) { return yxdb.ReadByteWithName(`ByteField`) })
+	checkField(t, false, true, func() (interface{}, bool) { return yxdb.ReadBoolWithName(`BoolField`) })
+	checkField(t, int64(0), true, func() (interface{}, bool) { return yxdb.ReadInt64WithName(`Int64Field`) })
+	checkField(t, 0.0, true, func() (interface{}, bool) { return yxdb.ReadFloat64WithName(`FixedDecimalField`) })
+	checkField(t, ``, true, func() (interface{}, bool) { return yxdb.ReadStringWithName(`WStringField`) })
+	checkField(t, ``, true, func() (interface{}, bool) { return yxdb.ReadStringWithName(`V_WStringLongField`) })
+	checkField(t, time.Time{}, true, func() (interface{}, bool) { return yxdb.ReadTimeWithName(`DateTimeField`) })
+	if blob := yxdb.ReadBlobWithName(`BlobField`); blob != nil {
+		t.Fatalf(`expected nil but got %v`, blob)
+	}
+}
+
+func TestWriteFieldMetadata(t *testing.T) {
+	path := tempPath(t, `Metadata.yxdb`)
+	fields := []metafield.MetaInfoField{
+		{
+			Name:        `Amount`,
+			Type:        `FixedDecimal`,
+			Size:        19,
+			Scale:       2,
+			Description: `Total "amount" in USD`,
+			Source:      `Formula: [Price] * [Qty]`,
+			OtherAttrs:  []xml.Attr{{Name: xml.Name{Local: `custom`}, Value: `kept`}},
+		},
+	}
+	writer, _ := yx.CreateFile(path, fields)
+	expected := "<RecordInfo>\n\t<Field custom=\"kept\" description=\"Total &#34;amount&#34; in USD\" name=\"Amount\" scale=\"2\" size=\"19\" source=\"Formula: [Price] * [Qty]\" type=\"FixedDecimal\"/>\n</RecordInfo>\n"
+	if writer.MetaInfoStr() != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, writer.MetaInfoStr())
+	}
+	_ = writer.Close()
+
+	yxdb := openYxdb(t, path)
+	defer yxdb.Close()
+	if actual := yxdb.FieldInfo(); !reflect.DeepEqual(actual, fields) {
+		t.Fatalf(`expected %+v but got %+v`, fields, actual)
+	}
+}
+
+func TestWriteLotsOfRecords(t *testing.T) {
+	path := tempPath(t, `LotsOfRecords.yxdb`)
+	writer, err := yx.CreateFile(path, []metafield.MetaInfoField{{Name: `Value`, Type: `Int32`}})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	for i := int64(1); i <= 100000; i++ {
+		writer.WriteInt64WithIndex(0, i)
+		err = writer.WriteRecord()
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+	}
+	_ = writer.Close()
+
+	raw, _ := os.ReadFile(path)
+	indexPos := binary.LittleEndian.Uint64(raw[96:104])
+	if blocks := binary.LittleEndian.Uint32(raw[indexPos : indexPos+4]); blocks != 2 {
+		t.Fatalf(`expected 2 record blocks but got %v`, blocks)
+	}
+
+	yxdb := openYxdb(t, path)
+	sum := int64(0)
+	for yxdb.Next() {
+		value, _ := yxdb.ReadInt64WithIndex(0)
+		sum += value
+	}
+	if sum != 5000050000 {
+		t.Fatalf(`expected 5000050000 but got %v`, sum)
+	}
+	err = yxdb.ReadRecordAt(70000)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	checkField(t, int64(70001), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+	_ = yxdb.Close()
+}
+
+func TestWriteEmptyStrings(t *testing.T) {
+	path := tempPath(t, `EmptyStrings.yxdb`)
+	writer, _ := yx.CreateFile(path, []metafield.MetaInfoField{
+		{Name: `V_String`, Type: `V_String`},
+		{Name: `V_WString`, Type: `V_WString`},
+		{Name: `String`, Type: `String`, Size: 2},
+	})
+	writer.WriteStringWithIndex(0, ``)
+	writer.WriteStringWithIndex(1, `Z`)
+	writer.WriteStringWithIndex(2, `truncated`)
+	_ = writer.WriteRecord()
+	_ = writer.Close()
+
+	yxdb := openYxdb(t, path)
+	yxdb.Next()
+	checkField(t, ``, false, func() (interface{}, bool) { return yxdb.ReadStringWithIndex(0) })
+	checkField(t, `Z`, false, func() (interface{}, bool) { return yxdb.ReadStringWithIndex(1) })
+	checkField(t, `tr`, false, func() (interface{}, bool) { return yxdb.ReadStringWithIndex(2) })
+	_ = yxdb.Close()
+}
+
+func TestWriteNegativeIntegers(t *testing.T) {
+	path := tempPath(t, `NegativeIntegers.yxdb`)
+	writer, _ := yx.CreateFile(path, []metafield.MetaInfoField{
+		{Name: `Int16`, Type: `Int16`},
+		{Name: `Int32`, Type: `Int32`},
+	})
+	rows := [][2]int64{{-5, -7}, {math.MinInt16, math.MinInt32}, {math.MaxInt16, math.MaxInt32}, {-1, -1}}
+	for _, row := range rows {
+		writer.WriteInt64WithIndex(0, row[0])
+		writer.WriteInt64WithIndex(1, row[1])
+		err := writer.WriteRecord()
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+
+	yxdb := openYxdb(t, path)
+	defer yxdb.Close()
+	for _, row := range rows {
+		yxdb.Next()
+		checkField(t, row[0], false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+		checkField(t, row[1], false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(1) })
+	}
+}
+
+func TestWriteOutOfRange(t *testing.T) {
+	path := tempPath(t, `OutOfRange.yxdb`)
+	writer, _ := yx.CreateFile(path, []metafield.MetaInfoField{
+		{Name: `Int16`, Type: `Int16`},
+		{Name: `Decimal`, Type: `FixedDecimal`, Size: 5, Scale: 2},
+	})
+	writer.WriteInt64WithIndex(0, 1)
+	writer.WriteFloat64WithIndex(1, 12.34)
+	err := writer.WriteRecord()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	writer.WriteFloat64WithName(`Decimal`, 12345.67)
+	writer.WriteInt64WithIndex(0, 40000)
+	err = writer.WriteRecord()
+	if !errors.Is(err, yx.ErrOutOfRange) {
+		t.Fatalf(`expected ErrOutOfRange but got: %v`, err)
+	}
+	if err.Error() != `field 'Decimal' of record 2: value out of range: 12345.67 does not fit in a FixedDecimal of size 5` {
+		t.Fatalf(`expected the first error to name the field and record but got: %v`, err.Error())
+	}
+	writer.WriteInt64WithIndex(0, 2)
+	if err = writer.WriteRecord(); !errors.Is(err, yx.ErrOutOfRange) {
+		t.Fatalf(`expected later records to fail but got: %v`, err)
+	}
+	if err = writer.Close(); !errors.Is(err, yx.ErrOutOfRange) {
+		t.Fatalf(`expected Close to return ErrOutOfRange but got: %v`, err)
+	}
+
+	yxdb := openYxdb(t, path)
+	if yxdb.NumRecords() != 1 {
+		t.Fatalf(`expected 1 record but got %v`, yxdb.NumRecords())
+	}
+	yxdb.Next()
+	checkField(t, int64(1), false, func() (interface{}, bool) { return yxdb.ReadInt64WithIndex(0) })
+	_ = yxdb.Close()
+}
+
+func TestWriteInvalidFieldType(t *testing.T) {
+	_, err := yx.CreateFile(tempPath(t, `Invalid.yxdb`), []metafield.MetaInfoField{{Name: `Field`, Type: `Invalid`}})
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+func TestWriteStringWithoutSize(t *testing.T) {
+	_, err := yx.CreateFile(tempPath(t, `Invalid.yxdb`), []metafield.MetaInfoField{{Name: `Field`, Type: `String`}})
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	if err.Error() != `field 'Field' of type String requires a size greater than 0` {
+		t.Fatalf(`expected 'field 'Field' of type String requires a size greater than 0' but got '%v'`, err.Error())
+	}
+}
+
+func TestWriteStringToNonStringIndex(t *testing.T) {
+	defer checkPanic(t, `field at index 0 is not a string field`)()
+	writer, _ := yx.CreateFile(tempPath(t, `Panic.yxdb`), allNormalFields)
+	defer writer.Close()
+
+	writer.WriteStringWithIndex(0, `A`)
+}
+
+func TestWriteInvalidField(t *testing.T) {
+	defer checkPanic(t, `field 'invalid' does not exist`)()
+	writer, _ := yx.CreateFile(tempPath(t, `Panic.yxdb`), allNormalFields)
+	defer writer.Close()
+
+	writer.WriteInt64WithName(`invalid`, 1)
+}
+
+func tempPath(t *testing.T, fileName string) string {
+	return filepath.Join(t.TempDir(), fileName)
+}
+
+func openYxdb(t *testing.T, path string) yx.Reader {
+	yxdb, err := yx.ReadFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	return yxdb
+}
@@ -0,0 +1,135 @@
+package yxdbsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"time"
+)
+
+// dateTimeLayouts are the layouts accepted for strings compared with Date and DateTime fields.
+var dateTimeLayouts = []string{`2006-01-02`, `2006-01-02 15:04:05`, `2006-01-02T15:04:05`, time.RFC3339Nano}
+
+// negated holds the operators that give the opposite result for non-null values.
+var negated = map[yx.Operator]yx.Operator{
+	yx.Equal:          yx.NotEqual,
+	yx.NotEqual:       yx.Equal,
+	yx.Less:           yx.GreaterOrEqual,
+	yx.LessOrEqual:    yx.Greater,
+	yx.Greater:        yx.LessOrEqual,
+	yx.GreaterOrEqual: yx.Less,
+}
+
+// never is a predicate that no record satisfies.
+var never = yx.Or()
+
+// binder turns a WHERE clause into a yx.Predicate, using the fields of the file to convert literals.
+type binder struct {
+	fields map[string]yxrecord.YxdbField
+	args   []driver.Value
+}
+
+// bind returns the predicate for cond, or its negation if negate is true.
+//
+// Comparisons with null are unknown in SQL, so they match no records whether or not they are negated. Negations are
+// pushed down to the comparisons, rather than built with yx.Not, so that NOT (x = 1) does not match records where x
+// is null.
+func (b *binder) bind(cond condition, negate bool) (yx.Predicate, error) {
+	switch cond := cond.(type) {
+	case comparison:
+		return b.compare(cond.column, cond.op, cond.value, negate)
+	case isNull:
+		if cond.not != negate {
+			return yx.Not(yx.IsNull(cond.column)), nil
+		}
+		return yx.IsNull(cond.column), nil
+	case in:
+		// x IN (a, b) is x = a OR x = b, and x NOT IN (a, b) is x <> a AND x <> b
+		predicates := make([]yx.Predicate, len(cond.values))
+		for index, value := range cond.values {
+			predicate, err := b.compare(cond.column, yx.Equal, value, cond.not != negate)
+			if err != nil {
+				return yx.Predicate{}, err
+			}
+			predicates[index] = predicate
+		}
+		if cond.not != negate {
+			return yx.And(predicates...), nil
+		}
+		return yx.Or(predicates...), nil
+	case between:
+		// x BETWEEN a AND b is x >= a AND x <= b, and x NOT BETWEEN a AND b is x < a OR x > b
+		low, err := b.compare(cond.column, yx.GreaterOrEqual, cond.low, cond.not != negate)
+		if err != nil {
+			return yx.Predicate{}, err
+		}
+		high, err := b.compare(cond.column, yx.LessOrEqual, cond.high, cond.not != negate)
+		if err != nil {
+			return yx.Predicate{}, err
+		}
+		if cond.not != negate {
+			return yx.Or(low, high), nil
+		}
+		return yx.And(low, high), nil
+	case logical:
+		predicates := make([]yx.Predicate, len(cond.conditions))
+		for index, operand := range cond.conditions {
+			predicate, err := b.bind(operand, negate)
+			if err != nil {
+				return yx.Predicate{}, err
+			}
+			predicates[index] = predicate
+		}
+		if cond.and != negate {
+			return yx.And(predicates...), nil
+		}
+		return yx.Or(predicates...), nil
+	case not:
+		return b.bind(cond.condition, !negate)
+	default:
+		return yx.Predicate{}, fmt.Errorf(`unsupported condition %T`, cond)
+	}
+}
+
+func (b *binder) compare(column string, op yx.Operator, value literal, negate bool) (yx.Predicate, error) {
+	field, ok := b.fields[column]
+	if !ok {
+		return yx.Predicate{}, fmt.Errorf(`field '%v' does not exist: %w`, column, yx.ErrFieldNotFound)
+	}
+	compareValue, err := b.value(field, value)
+	if err != nil {
+		return yx.Predicate{}, err
+	}
+	if compareValue == nil {
+		return never, nil
+	}
+	if negate {
+		op = negated[op]
+	}
+	return yx.Compare(column, op, compareValue), nil
+}
+
+// value returns the literal, or the argument it refers to, converted for comparison with field. It returns nil for
+// null.
+func (b *binder) value(field yxrecord.YxdbField, value literal) (any, error) {
+	result := value.value
+	if value.placeholder >= 0 {
+		if value.placeholder >= len(b.args) {
+			return nil, fmt.Errorf(`expected %v arguments but got %v`, value.placeholder+1, len(b.args))
+		}
+		result = b.args[value.placeholder]
+	}
+	if data, ok := result.([]byte); ok {
+		result = string(data)
+	}
+	if text, ok := result.(string); ok && field.Type == yxrecord.Date {
+		for _, layout := range dateTimeLayouts {
+			if moment, err := time.Parse(layout, text); err == nil {
+				return moment.UTC(), nil
+			}
+		}
+		return nil, fmt.Errorf(`value '%v' compared with field '%v' is not a date: %w`, text, field.Name, yx.ErrTypeMismatch)
+	}
+	return result, nil
+}
@@ -0,0 +1,295 @@
+// Package yxdbsql is a database/sql driver that queries .yxdb files.
+//
+// Importing the package registers the driver as "yxdb". The data source name is the path of a .yxdb file, which is
+// exposed as a single table; the table name used in queries is not checked:
+//
+//	db, err := sql.Open("yxdb", "/path/to/file.yxdb")
+//	rows, err := db.Query(`SELECT Id, "Customer Name" FROM data WHERE Amount > ? AND Region IN ('East', 'West') LIMIT 10`, 100)
+//
+// Queries have the form
+//
+//	SELECT * | column [[AS] alias], ... FROM table [WHERE condition] [LIMIT count]
+//
+// where a condition compares a field with a literal or ? parameter using =, <>, !=, <, <=, >, or >=, tests it with
+// IS [NOT] NULL, [NOT] IN (value, ...), or [NOT] BETWEEN low AND high, and combines conditions with AND, OR, NOT, and
+// parentheses. Names containing spaces or keywords are quoted with double quotes, brackets, or backticks, and strings
+// with single quotes. Strings compared with Date and DateTime fields are parsed as ISO 8601 dates and date times.
+//
+// Conditions are evaluated as yxdb.Predicate values, so records that do not match are skipped without being decoded,
+// and only the selected fields are read. Comparisons with null values are never satisfied, as in SQL.
+//
+// Values are returned as int64 for Byte, Int16, Int32, and Int64 fields, as float64 for Float and Double fields, as
+// strings holding the stored digits for FixedDecimal fields, as time.Time for Date and DateTime fields, and as []byte
+// for Blob and SpatialObj fields. Rows report the type, size, and scale declared in the MetaInfo of each field through
+// sql.ColumnType.
+//
+// The driver is read-only: Exec and transactions return an error.
+package yxdbsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+)
+
+var (
+	errReadOnly       = errors.New(`yxdb files are read-only`)
+	errNoTransactions = errors.New(`transactions are not supported by the yxdb driver`)
+)
+
+func init() {
+	sql.Register(`yxdb`, &Driver{})
+}
+
+// Driver is the database/sql driver registered as "yxdb".
+type Driver struct{}
+
+// Open returns a connection to the .yxdb file at name.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	reader, err := yx.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	c := &conn{path: name, fields: map[string]yxrecord.YxdbField{}}
+	for _, field := range reader.ListFields() {
+		c.fields[field.Name] = field
+	}
+	return c, nil
+}
+
+type conn struct {
+	path   string
+	fields map[string]yxrecord.YxdbField
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	parsed, err := parse(query)
+	if err != nil {
+		return nil, fmt.Errorf(`error parsing query: %w`, err)
+	}
+	for _, column := range parsed.columns {
+		if _, ok := c.fields[column.name]; !ok {
+			return nil, fmt.Errorf(`field '%v' does not exist: %w`, column.name, yx.ErrFieldNotFound)
+		}
+	}
+	return &stmt{conn: c, query: parsed}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errNoTransactions
+}
+
+type stmt struct {
+	conn  *conn
+	query *query
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.query.placeholders
+}
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errReadOnly
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	var opts []yx.Option
+	if s.query.where != nil {
+		b := &binder{fields: s.conn.fields, args: args}
+		predicate, err := b.bind(s.query.where, false)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, yx.WithPredicate(predicate))
+	}
+	limit := int64(-1)
+	if s.query.limit != nil {
+		var err error
+		limit, err = limitValue(*s.query.limit, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a field selected more than once is read once
+	var names, reads []string
+	var indexes []int
+	positions := map[string]int{}
+	for _, column := range s.query.columns {
+		position, ok := positions[column.name]
+		if !ok {
+			position = len(reads)
+			positions[column.name] = position
+			reads = append(reads, column.name)
+		}
+		names = append(names, column.alias)
+		indexes = append(indexes, position)
+	}
+	if s.query.columns != nil {
+		opts = append(opts, yx.WithColumns(reads...))
+	}
+
+	reader, err := yx.ReadFile(s.conn.path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	info := reader.FieldInfo()
+	if s.query.columns == nil {
+		for index, field := range info {
+			names = append(names, field.Name)
+			indexes = append(indexes, index)
+		}
+	}
+	return &rows{
+		reader:  reader,
+		names:   names,
+		indexes: indexes,
+		fields:  reader.ListFields(),
+		info:    info,
+		limit:   limit,
+	}, nil
+}
+
+func limitValue(value literal, args []driver.Value) (int64, error) {
+	limit := value.value
+	if value.placeholder >= 0 {
+		if value.placeholder >= len(args) {
+			return 0, fmt.Errorf(`expected %v arguments but got %v`, value.placeholder+1, len(args))
+		}
+		limit = args[value.placeholder]
+	}
+	count, ok := limit.(int64)
+	if !ok || count < 0 {
+		return 0, fmt.Errorf(`LIMIT must be a non-negative integer but got %v`, limit)
+	}
+	return count, nil
+}
+
+type rows struct {
+	reader  yx.Reader
+	names   []string
+	indexes []int
+	fields  []yxrecord.YxdbField
+	info    []metafield.MetaInfoField
+
+	// limit is the number of rows left to return, or -1 if there is no limit.
+	limit int64
+}
+
+func (r *rows) Columns() []string {
+	return r.names
+}
+
+func (r *rows) Close() error {
+	return r.reader.Close()
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.limit == 0 || !r.reader.Next() {
+		if err := r.reader.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	if r.limit > 0 {
+		r.limit--
+	}
+	for column, index := range r.indexes {
+		value, isNull := r.reader.ReadValueWithIndex(index)
+		if isNull {
+			dest[column] = nil
+			continue
+		}
+		switch value := value.(type) {
+		case byte:
+			dest[column] = int64(value)
+		case driver.Valuer:
+			converted, err := value.Value()
+			if err != nil {
+				return err
+			}
+			dest[column] = converted
+		default:
+			dest[column] = value
+		}
+	}
+	return nil
+}
+
+func (r *rows) fieldType(index int) yxrecord.FieldType {
+	return r.fields[r.indexes[index]].FieldType
+}
+
+// ColumnTypeDatabaseTypeName returns the upper case name of the Alteryx field type, such as V_WSTRING.
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return strings.ToUpper(r.info[r.indexes[index]].Type)
+}
+
+// ColumnTypeNullable reports that every column is nullable, since any field of a .yxdb file can hold null.
+func (r *rows) ColumnTypeNullable(int) (nullable, ok bool) {
+	return true, true
+}
+
+// ColumnTypeLength returns the size of string fields in characters. Blob and SpatialObj fields have no limit.
+func (r *rows) ColumnTypeLength(index int) (length int64, ok bool) {
+	switch r.fieldType(index) {
+	case yxrecord.TypeString, yxrecord.TypeWString, yxrecord.TypeV_String, yxrecord.TypeV_WString:
+		return int64(r.fields[r.indexes[index]].Size), true
+	case yxrecord.TypeBlob, yxrecord.TypeSpatialObj:
+		return math.MaxInt64, true
+	default:
+		return 0, false
+	}
+}
+
+// ColumnTypePrecisionScale returns the size and scale of FixedDecimal fields.
+func (r *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if r.fieldType(index) != yxrecord.TypeFixedDecimal {
+		return 0, 0, false
+	}
+	field := r.fields[r.indexes[index]]
+	return int64(field.Size), int64(field.Scale), true
+}
+
+var scanTypes = map[yxrecord.FieldType]reflect.Type{
+	yxrecord.TypeBool:         reflect.TypeOf(sql.NullBool{}),
+	yxrecord.TypeByte:         reflect.TypeOf(sql.NullInt64{}),
+	yxrecord.TypeInt16:        reflect.TypeOf(sql.NullInt64{}),
+	yxrecord.TypeInt32:        reflect.TypeOf(sql.NullInt64{}),
+	yxrecord.TypeInt64:        reflect.TypeOf(sql.NullInt64{}),
+	yxrecord.TypeFixedDecimal: reflect.TypeOf(sql.NullString{}),
+	yxrecord.TypeFloat:        reflect.TypeOf(sql.NullFloat64{}),
+	yxrecord.TypeDouble:       reflect.TypeOf(sql.NullFloat64{}),
+	yxrecord.TypeString:       reflect.TypeOf(sql.NullString{}),
+	yxrecord.TypeWString:      reflect.TypeOf(sql.NullString{}),
+	yxrecord.TypeV_String:     reflect.TypeOf(sql.NullString{}),
+	yxrecord.TypeV_WString:    reflect.TypeOf(sql.NullString{}),
+	yxrecord.TypeDate:         reflect.TypeOf(sql.NullTime{}),
+	yxrecord.TypeDateTime:     reflect.TypeOf(sql.NullTime{}),
+	yxrecord.TypeBlob:         reflect.TypeOf([]byte{}),
+	yxrecord.TypeSpatialObj:   reflect.TypeOf([]byte{}),
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	if scanType, ok := scanTypes[r.fieldType(index)]; ok {
+		return scanType
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
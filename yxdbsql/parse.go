@@ -0,0 +1,366 @@
+package yxdbsql
+
+import (
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/sqlscan"
+	"strconv"
+	"strings"
+)
+
+// query is a parsed SELECT statement.
+type query struct {
+	// columns lists the selected fields; it is nil for SELECT *.
+	columns []selectColumn
+	where   condition
+
+	// limit is the LIMIT literal, or nil if there is no limit.
+	limit *literal
+
+	placeholders int
+}
+
+type selectColumn struct {
+	name  string
+	alias string
+}
+
+// condition is a node of a WHERE clause: a comparison, isNull, in, between, logical, or not.
+type condition any
+
+type comparison struct {
+	column string
+	op     yx.Operator
+	value  literal
+}
+
+type isNull struct {
+	column string
+	not    bool
+}
+
+type in struct {
+	column string
+	values []literal
+	not    bool
+}
+
+type between struct {
+	column    string
+	low, high literal
+	not       bool
+}
+
+type logical struct {
+	and        bool
+	conditions []condition
+}
+
+type not struct {
+	condition condition
+}
+
+// literal is a constant of the statement or, if placeholder is not negative, the index of a ? parameter.
+type literal struct {
+	value       any
+	placeholder int
+}
+
+type parser struct {
+	tokens       []sqlscan.Token
+	pos          int
+	placeholders int
+}
+
+// parse parses a statement of the form
+//
+//	SELECT * | column [[AS] alias], ... FROM table [WHERE condition] [LIMIT count]
+func parse(sql string) (*query, error) {
+	tokens, err := sqlscan.Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &query{}
+	err = p.expect(`SELECT`)
+	if err != nil {
+		return nil, err
+	}
+	if p.accept(`*`) {
+		q.columns = nil
+	} else {
+		for {
+			column, err := p.selectColumn()
+			if err != nil {
+				return nil, err
+			}
+			q.columns = append(q.columns, column)
+			if !p.accept(`,`) {
+				break
+			}
+		}
+	}
+	err = p.expect(`FROM`)
+	if err != nil {
+		return nil, err
+	}
+	// a file holds a single table, so its name is not checked
+	if _, err = p.identifier(); err != nil {
+		return nil, err
+	}
+	if p.accept(`WHERE`) {
+		q.where, err = p.or()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(`LIMIT`) {
+		limit, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		q.limit = &limit
+	}
+	p.accept(`;`)
+	if next := p.peek(); next.Kind != sqlscan.EOF {
+		return nil, p.unexpected(next)
+	}
+	q.placeholders = p.placeholders
+	return q, nil
+}
+
+func (p *parser) peek() sqlscan.Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() sqlscan.Token {
+	next := p.tokens[p.pos]
+	if next.Kind != sqlscan.EOF {
+		p.pos++
+	}
+	return next
+}
+
+// accept consumes the next token if it is the keyword or symbol text.
+func (p *parser) accept(text string) bool {
+	if p.peek().Is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf(`expected %v but got %w`, text, p.unexpected(p.peek()))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(t sqlscan.Token) error {
+	if t.Kind == sqlscan.EOF {
+		return fmt.Errorf(`unexpected end of statement`)
+	}
+	return fmt.Errorf(`unexpected '%v' at position %v`, t.Text, t.Pos+1)
+}
+
+// keywords cannot be used as unquoted identifiers.
+var keywords = map[string]bool{
+	`SELECT`: true, `FROM`: true, `WHERE`: true, `LIMIT`: true, `AS`: true, `AND`: true, `OR`: true, `NOT`: true,
+	`IS`: true, `NULL`: true, `IN`: true, `BETWEEN`: true, `TRUE`: true, `FALSE`: true,
+}
+
+func (p *parser) identifier() (string, error) {
+	next := p.peek()
+	if next.Kind == sqlscan.QuotedIdent || (next.Kind == sqlscan.Ident && !keywords[strings.ToUpper(next.Text)]) {
+		p.pos++
+		return next.Text, nil
+	}
+	return ``, fmt.Errorf(`expected a name but got %w`, p.unexpected(next))
+}
+
+func (p *parser) selectColumn() (selectColumn, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return selectColumn{}, err
+	}
+	column := selectColumn{name: name, alias: name}
+	if p.accept(`AS`) {
+		column.alias, err = p.identifier()
+		return column, err
+	}
+	if alias, err := p.identifier(); err == nil {
+		column.alias = alias
+	}
+	return column, nil
+}
+
+func (p *parser) or() (condition, error) {
+	return p.logical(false, p.and)
+}
+
+func (p *parser) and() (condition, error) {
+	return p.logical(true, p.not)
+}
+
+// logical parses operands separated by AND or OR, returning the operand itself if there is only one.
+func (p *parser) logical(and bool, operand func() (condition, error)) (condition, error) {
+	keyword := `OR`
+	if and {
+		keyword = `AND`
+	}
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	conditions := []condition{first}
+	for p.accept(keyword) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, next)
+	}
+	if len(conditions) == 1 {
+		return first, nil
+	}
+	return logical{and: and, conditions: conditions}, nil
+}
+
+func (p *parser) not() (condition, error) {
+	if p.accept(`NOT`) {
+		operand, err := p.not()
+		return not{condition: operand}, err
+	}
+	if p.accept(`(`) {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(`)`)
+	}
+	return p.predicate()
+}
+
+var operators = map[string]yx.Operator{
+	`=`:  yx.Equal,
+	`<>`: yx.NotEqual,
+	`!=`: yx.NotEqual,
+	`<`:  yx.Less,
+	`<=`: yx.LessOrEqual,
+	`>`:  yx.Greater,
+	`>=`: yx.GreaterOrEqual,
+}
+
+// flipped holds the operators that give the same result when the operands are swapped.
+var flipped = map[yx.Operator]yx.Operator{
+	yx.Equal:          yx.Equal,
+	yx.NotEqual:       yx.NotEqual,
+	yx.Less:           yx.Greater,
+	yx.LessOrEqual:    yx.GreaterOrEqual,
+	yx.Greater:        yx.Less,
+	yx.GreaterOrEqual: yx.LessOrEqual,
+}
+
+// predicate parses a comparison of a column with a literal, in either order, or an IS NULL, IN, or BETWEEN test.
+func (p *parser) predicate() (condition, error) {
+	column, err := p.identifier()
+	if err != nil {
+		value, literalErr := p.literal()
+		if literalErr != nil {
+			return nil, err
+		}
+		op, ok := operators[p.peek().Text]
+		if !ok || p.peek().Kind != sqlscan.Symbol {
+			return nil, fmt.Errorf(`expected a comparison but got %w`, p.unexpected(p.peek()))
+		}
+		p.pos++
+		column, err = p.identifier()
+		return comparison{column: column, op: flipped[op], value: value}, err
+	}
+
+	if p.accept(`IS`) {
+		negated := p.accept(`NOT`)
+		return isNull{column: column, not: negated}, p.expect(`NULL`)
+	}
+	negated := p.accept(`NOT`)
+	if p.accept(`IN`) {
+		err = p.expect(`(`)
+		if err != nil {
+			return nil, err
+		}
+		test := in{column: column, not: negated}
+		for {
+			value, err := p.literal()
+			if err != nil {
+				return nil, err
+			}
+			test.values = append(test.values, value)
+			if !p.accept(`,`) {
+				break
+			}
+		}
+		return test, p.expect(`)`)
+	}
+	if p.accept(`BETWEEN`) {
+		test := between{column: column, not: negated}
+		test.low, err = p.literal()
+		if err != nil {
+			return nil, err
+		}
+		err = p.expect(`AND`)
+		if err != nil {
+			return nil, err
+		}
+		test.high, err = p.literal()
+		return test, err
+	}
+	if negated {
+		return nil, fmt.Errorf(`expected IN or BETWEEN but got %w`, p.unexpected(p.peek()))
+	}
+
+	next := p.next()
+	op, ok := operators[next.Text]
+	if !ok || next.Kind != sqlscan.Symbol {
+		return nil, fmt.Errorf(`expected a comparison but got %w`, p.unexpected(next))
+	}
+	value, err := p.literal()
+	return comparison{column: column, op: op, value: value}, err
+}
+
+// literal parses a number, string, TRUE, FALSE, NULL, or ? parameter. NULL is returned with a nil value.
+func (p *parser) literal() (literal, error) {
+	next := p.next()
+	switch {
+	case next.Is(`?`):
+		p.placeholders++
+		return literal{placeholder: p.placeholders - 1}, nil
+	case next.Kind == sqlscan.String:
+		return literal{value: next.Text, placeholder: -1}, nil
+	case next.Is(`TRUE`), next.Is(`FALSE`):
+		return literal{value: next.Is(`TRUE`), placeholder: -1}, nil
+	case next.Is(`NULL`):
+		return literal{placeholder: -1}, nil
+	case next.Is(`-`) || next.Is(`+`):
+		number := p.next()
+		if number.Kind != sqlscan.Number {
+			return literal{}, fmt.Errorf(`expected a number but got %w`, p.unexpected(number))
+		}
+		return parseNumber(next.Text+number.Text, number)
+	case next.Kind == sqlscan.Number:
+		return parseNumber(next.Text, next)
+	}
+	return literal{}, fmt.Errorf(`expected a value but got %w`, p.unexpected(next))
+}
+
+// parseNumber parses integers as int64 and other numbers as float64.
+func parseNumber(text string, t sqlscan.Token) (literal, error) {
+	if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return literal{value: integer, placeholder: -1}, nil
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return literal{}, fmt.Errorf(`invalid number '%v' at position %v`, t.Text, t.Pos+1)
+	}
+	return literal{value: number, placeholder: -1}, nil
+}
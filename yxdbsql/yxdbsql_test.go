@@ -0,0 +1,290 @@
+package yxdbsql_test
+
+import (
+	"database/sql"
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	_ "github.com/tlarsendataguy-yxdb/yxdb-go/yxdbsql"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testFields = []metafield.MetaInfoField{
+	{Name: `Id`, Type: `Int32`},
+	{Name: `Amount`, Type: `FixedDecimal`, Size: 10, Scale: 2},
+	{Name: `Ratio`, Type: `Double`},
+	{Name: `Name`, Type: `V_WString`, Size: 20},
+	{Name: `Region Name`, Type: `String`, Size: 10},
+	{Name: `Date`, Type: `Date`},
+	{Name: `Data`, Type: `Blob`},
+}
+
+func TestSelectAll(t *testing.T) {
+	db := openTestFile(t)
+	rows, err := db.Query(`select * from data`)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	expected := []string{`Id`, `Amount`, `Ratio`, `Name`, `Region Name`, `Date`, `Data`}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, columns)
+	}
+	var values [][]any
+	for rows.Next() {
+		row := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for index := range row {
+			pointers[index] = &row[index]
+		}
+		err = rows.Scan(pointers...)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		values = append(values, row)
+	}
+	if rows.Err() != nil || len(values) != 4 {
+		t.Fatalf(`expected 4 rows but got %v and %v`, len(values), rows.Err())
+	}
+	first := []any{int64(1), `12.50`, 0.5, `Alice`, `East`, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), []byte{1, 2}}
+	if !reflect.DeepEqual(values[0], first) {
+		t.Fatalf(`expected %v but got %v`, first, values[0])
+	}
+	nulls := []any{int64(3), nil, nil, nil, `North`, nil, nil}
+	if !reflect.DeepEqual(values[2], nulls) {
+		t.Fatalf(`expected %v but got %v`, nulls, values[2])
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	db := openTestFile(t)
+	rows, err := db.Query(`SELECT Name AS Customer, [Region Name] region, Id, Id FROM data WHERE Ratio > ? LIMIT ?`, 0.5, 2)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	if expected := []string{`Customer`, `region`, `Id`, `Id`}; !reflect.DeepEqual(columns, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, columns)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		var region sql.NullString
+		var id, again int
+		err = rows.Scan(&name, &region, &id, &again)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		if id != again {
+			t.Fatalf(`expected the same id twice but got %v and %v`, id, again)
+		}
+		names = append(names, name+`/`+region.String)
+	}
+	if expected := []string{`Bob/West`, `Dora/`}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, names)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	db := openTestFile(t)
+	cases := []struct {
+		where    string
+		args     []any
+		expected []int
+	}{
+		{`Id = 2`, nil, []int{2}},
+		{`2 < Id`, nil, []int{3, 4}},
+		{`Amount >= 12.5`, nil, []int{1, 4}},
+		{`Amount < ?`, []any{0}, []int{2}},
+		{`Name <> 'Bob'`, nil, []int{1, 4}},
+		{`NOT Name = 'Bob'`, nil, []int{1, 4}},
+		{`Name IS NULL`, nil, []int{3}},
+		{`Name IS NOT NULL AND "Region Name" IS NOT NULL`, nil, []int{1, 2}},
+		{`Id IN (1, 3, 5)`, nil, []int{1, 3}},
+		{`Name NOT IN ('Bob', NULL)`, nil, nil},
+		{`Ratio BETWEEN 1 AND 2.5`, nil, []int{2, 4}},
+		{`Ratio NOT BETWEEN 1 AND 2`, nil, []int{1, 4}},
+		{`Date > '2024-02-01'`, nil, []int{2, 4}},
+		{`Date = ?`, []any{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}, []int{2}},
+		{`Id = 1 OR (Ratio > 1 AND NOT Name = 'Dora')`, nil, []int{1, 2}},
+		{`Name = NULL OR Name <> NULL`, nil, nil},
+		{`Name = 'O''Brien'`, nil, nil},
+		{`Id > -1 -- every record`, nil, []int{1, 2, 3, 4}},
+	}
+	for _, c := range cases {
+		rows, err := db.Query(`SELECT Id FROM data WHERE `+c.where, c.args...)
+		if err != nil {
+			t.Fatalf(`expected no error for %v but got: %v`, c.where, err.Error())
+		}
+		var ids []int
+		for rows.Next() {
+			var id int
+			_ = rows.Scan(&id)
+			ids = append(ids, id)
+		}
+		_ = rows.Close()
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf(`expected %v for %v but got %v`, c.expected, c.where, ids)
+		}
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	db := openTestFile(t)
+	rows, err := db.Query(`SELECT Id, Amount, Name, Data FROM data LIMIT 0`)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if rows.Next() {
+		t.Fatalf(`expected no rows with LIMIT 0`)
+	}
+	expectedNames := []string{`INT32`, `FIXEDDECIMAL`, `V_WSTRING`, `BLOB`}
+	for index, columnType := range types {
+		if name := columnType.DatabaseTypeName(); name != expectedNames[index] {
+			t.Fatalf(`expected %v but got %v`, expectedNames[index], name)
+		}
+		if nullable, ok := columnType.Nullable(); !nullable || !ok {
+			t.Fatalf(`expected %v to be nullable`, columnType.Name())
+		}
+	}
+	if _, ok := types[0].Length(); ok {
+		t.Fatalf(`expected Id to have no length`)
+	}
+	if length, ok := types[2].Length(); length != 20 || !ok {
+		t.Fatalf(`expected Name to have a length of 20 but got %v`, length)
+	}
+	if length, _ := types[3].Length(); length != math.MaxInt64 {
+		t.Fatalf(`expected Data to have no limit but got %v`, length)
+	}
+	if precision, scale, ok := types[1].DecimalSize(); precision != 10 || scale != 2 || !ok {
+		t.Fatalf(`expected Amount to have a size of 10 and scale of 2 but got %v and %v`, precision, scale)
+	}
+	if _, _, ok := types[0].DecimalSize(); ok {
+		t.Fatalf(`expected Id to have no decimal size`)
+	}
+	if scanType := types[0].ScanType(); scanType != reflect.TypeOf(sql.NullInt64{}) {
+		t.Fatalf(`expected sql.NullInt64 but got %v`, scanType)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	db := openTestFile(t)
+	cases := []struct {
+		query    string
+		args     []any
+		expected string
+	}{
+		{`SELECT Id FROM data ORDER BY Id`, nil, `error parsing query: unexpected 'ORDER' at position 21`},
+		{`SELECT FROM data`, nil, `error parsing query: expected a name but got unexpected 'FROM' at position 8`},
+		{`SELECT Id FROM data WHERE Name = 'abc`, nil, `error parsing query: unterminated ' at position 34`},
+		{`SELECT Id FROM data WHERE Id = #`, nil, `error parsing query: unexpected character '#' at position 32`},
+		{`SELECT Id FROM data LIMIT -1`, nil, `LIMIT must be a non-negative integer but got -1`},
+		{`SELECT Id FROM data WHERE Date < 'soon'`, nil, `value 'soon' compared with field 'Date' is not a date: field type mismatch`},
+	}
+	for _, c := range cases {
+		_, err := db.Query(c.query, c.args...)
+		if err == nil || err.Error() != c.expected {
+			t.Fatalf("expected error for %v to be\n%v\nbut got\n%v", c.query, c.expected, err)
+		}
+	}
+
+	_, err := db.Query(`SELECT Missing FROM data`)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected an error wrapping ErrFieldNotFound but got %v`, err)
+	}
+	_, err = db.Query(`SELECT Id FROM data WHERE Missing = 1`)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected an error wrapping ErrFieldNotFound but got %v`, err)
+	}
+	_, err = db.Query(`SELECT Id FROM data WHERE Name = 1`)
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected an error wrapping ErrTypeMismatch but got %v`, err)
+	}
+	_, err = db.Exec(`SELECT Id FROM data`)
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	_, err = db.Begin()
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+func TestLotsOfRecords(t *testing.T) {
+	db, err := sql.Open(`yxdb`, `../test_files/LotsOfRecords.yxdb`)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	defer db.Close()
+	var count, total int64
+	rows, err := db.Query(`SELECT RowCount FROM "LotsOfRecords" WHERE RowCount BETWEEN ? AND ?`, 1001, 2000)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	for rows.Next() {
+		var value int64
+		_ = rows.Scan(&value)
+		count++
+		total += value
+	}
+	if count != 1000 || total != 1500500 {
+		t.Fatalf(`expected 1000 rows totalling 1500500 but got %v totalling %v`, count, total)
+	}
+}
+
+// openTestFile writes four records to a .yxdb file and opens it with the yxdb driver.
+func openTestFile(t *testing.T) *sql.DB {
+	path := filepath.Join(t.TempDir(), `test.yxdb`)
+	writer, err := yx.CreateFile(path, testFields)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	records := []struct {
+		id            int64
+		amount, ratio float64
+		name, region  string
+		date          time.Time
+	}{
+		{1, 12.5, 0.5, `Alice`, `East`, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{2, -3.25, 1.5, `Bob`, `West`, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{3, 0, 0, ``, `North`, time.Time{}},
+		{4, 100, 2.5, `Dora`, ``, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, record := range records {
+		writer.WriteInt64WithIndex(0, record.id)
+		if record.name != `` {
+			writer.WriteFloat64WithIndex(1, record.amount)
+			writer.WriteFloat64WithIndex(2, record.ratio)
+			writer.WriteStringWithIndex(3, record.name)
+			writer.WriteTimeWithIndex(5, record.date)
+		}
+		if record.region != `` {
+			writer.WriteStringWithIndex(4, record.region)
+		}
+		if record.id == 1 {
+			writer.WriteBlobWithIndex(6, []byte{1, 2})
+		}
+		_ = writer.WriteRecord()
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	db, err := sql.Open(`yxdb`, path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
@@ -0,0 +1,302 @@
+package yxquery
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statement is a parsed SELECT statement.
+type statement struct {
+	distinct bool
+	items    []selectItem
+	from     tableRef
+	joins    []joinClause
+	where    expr
+	groupBy  []expr
+	having   expr
+	orderBy  []orderItem
+
+	// limit and offset are nil if the statement does not have them.
+	limit  expr
+	offset expr
+}
+
+// selectItem is an expression of the select list, or a * or table.* wildcard if star is true.
+type selectItem struct {
+	expr  expr
+	alias string
+	star  bool
+	table string
+}
+
+// tableRef is a table of the FROM clause, given either as the quoted path of a .yxdb file or as the name of a table
+// registered with WithTable.
+type tableRef struct {
+	path  string
+	name  string
+	alias string
+}
+
+type joinKind int
+
+const (
+	innerJoin joinKind = iota
+	leftJoin
+	crossJoin
+)
+
+type joinClause struct {
+	kind  joinKind
+	table tableRef
+	on    expr
+	using []string
+}
+
+type orderItem struct {
+	expr expr
+	desc bool
+}
+
+// expr is a node of an expression: a literalExpr, columnExpr, unaryExpr, binaryExpr, isNullExpr, inExpr,
+// betweenExpr, likeExpr, callExpr, or caseExpr.
+type expr interface {
+	String() string
+}
+
+// literalExpr is a constant, which is nil, bool, int64, float64, or string. The ? parameters of a statement are
+// replaced with literals of their arguments, which may also be *big.Rat, time.Time, or []byte.
+type literalExpr struct {
+	value any
+}
+
+type columnExpr struct {
+	table string
+	name  string
+}
+
+type unaryExpr struct {
+	op      string
+	operand expr
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+type isNullExpr struct {
+	operand expr
+	not     bool
+}
+
+type inExpr struct {
+	operand expr
+	list    []expr
+	not     bool
+}
+
+type betweenExpr struct {
+	operand, low, high expr
+	not                bool
+}
+
+type likeExpr struct {
+	operand, pattern expr
+	not              bool
+}
+
+// callExpr is a call of a scalar or aggregate function. The name is upper case, and star is set for COUNT(*).
+type callExpr struct {
+	name     string
+	args     []expr
+	star     bool
+	distinct bool
+}
+
+// caseExpr is a CASE expression. Operand is nil for the searched form, CASE WHEN condition THEN result.
+type caseExpr struct {
+	operand expr
+	whens   []whenClause
+	orElse  expr
+}
+
+type whenClause struct {
+	when, then expr
+}
+
+func (e literalExpr) String() string { return formatExpr(e, nil) }
+func (e columnExpr) String() string  { return formatExpr(e, nil) }
+func (e unaryExpr) String() string   { return formatExpr(e, nil) }
+func (e binaryExpr) String() string  { return formatExpr(e, nil) }
+func (e isNullExpr) String() string  { return formatExpr(e, nil) }
+func (e inExpr) String() string      { return formatExpr(e, nil) }
+func (e betweenExpr) String() string { return formatExpr(e, nil) }
+func (e likeExpr) String() string    { return formatExpr(e, nil) }
+func (e callExpr) String() string    { return formatExpr(e, nil) }
+func (e caseExpr) String() string    { return formatExpr(e, nil) }
+
+// formatExpr writes e as SQL. If column is not nil, it formats the column references, so that the planner can
+// recognize the same expression written with different qualifiers.
+func formatExpr(e expr, column func(columnExpr) string) string {
+	var b strings.Builder
+	writeExpr(&b, e, column)
+	return b.String()
+}
+
+func writeExpr(b *strings.Builder, e expr, column func(columnExpr) string) {
+	switch e := e.(type) {
+	case literalExpr:
+		switch value := e.value.(type) {
+		case nil:
+			b.WriteString(`NULL`)
+		case bool:
+			b.WriteString(strings.ToUpper(strconv.FormatBool(value)))
+		case string:
+			b.WriteString(`'` + strings.ReplaceAll(value, `'`, `''`) + `'`)
+		case time.Time:
+			b.WriteString(`'` + value.Format(dateTimeLayout) + `'`)
+		case []byte:
+			fmt.Fprintf(b, `X'%X'`, value)
+		case *big.Rat:
+			b.WriteString(toText(value))
+		default:
+			fmt.Fprint(b, value)
+		}
+	case columnExpr:
+		if column != nil {
+			b.WriteString(column(e))
+			return
+		}
+		if e.table != `` {
+			b.WriteString(e.table + `.`)
+		}
+		b.WriteString(e.name)
+	case unaryExpr:
+		b.WriteString(e.op)
+		if e.op == `NOT` {
+			b.WriteString(` `)
+		}
+		writeOperand(b, e.operand, column)
+	case binaryExpr:
+		writeOperand(b, e.left, column)
+		b.WriteString(` ` + e.op + ` `)
+		writeOperand(b, e.right, column)
+	case isNullExpr:
+		writeOperand(b, e.operand, column)
+		b.WriteString(` IS` + not(e.not) + ` NULL`)
+	case inExpr:
+		writeOperand(b, e.operand, column)
+		b.WriteString(not(e.not) + ` IN (`)
+		for index, item := range e.list {
+			if index > 0 {
+				b.WriteString(`, `)
+			}
+			writeExpr(b, item, column)
+		}
+		b.WriteString(`)`)
+	case betweenExpr:
+		writeOperand(b, e.operand, column)
+		b.WriteString(not(e.not) + ` BETWEEN `)
+		writeOperand(b, e.low, column)
+		b.WriteString(` AND `)
+		writeOperand(b, e.high, column)
+	case likeExpr:
+		writeOperand(b, e.operand, column)
+		b.WriteString(not(e.not) + ` LIKE `)
+		writeOperand(b, e.pattern, column)
+	case callExpr:
+		b.WriteString(e.name + `(`)
+		if e.distinct {
+			b.WriteString(`DISTINCT `)
+		}
+		if e.star {
+			b.WriteString(`*`)
+		}
+		for index, arg := range e.args {
+			if index > 0 {
+				b.WriteString(`, `)
+			}
+			writeExpr(b, arg, column)
+		}
+		b.WriteString(`)`)
+	case caseExpr:
+		b.WriteString(`CASE`)
+		if e.operand != nil {
+			b.WriteString(` `)
+			writeExpr(b, e.operand, column)
+		}
+		for _, when := range e.whens {
+			b.WriteString(` WHEN `)
+			writeExpr(b, when.when, column)
+			b.WriteString(` THEN `)
+			writeExpr(b, when.then, column)
+		}
+		if e.orElse != nil {
+			b.WriteString(` ELSE `)
+			writeExpr(b, e.orElse, column)
+		}
+		b.WriteString(` END`)
+	}
+}
+
+// writeOperand writes e, in parentheses if it is an operation, so that the formatted expression has the same
+// precedence as the original.
+func writeOperand(b *strings.Builder, e expr, column func(columnExpr) string) {
+	switch e.(type) {
+	case unaryExpr, binaryExpr, isNullExpr, inExpr, betweenExpr, likeExpr:
+		b.WriteString(`(`)
+		writeExpr(b, e, column)
+		b.WriteString(`)`)
+	default:
+		writeExpr(b, e, column)
+	}
+}
+
+func not(negated bool) string {
+	if negated {
+		return ` NOT`
+	}
+	return ``
+}
+
+// walk calls visit for e and, while visit returns true, for the expressions it contains.
+func walk(e expr, visit func(expr) bool) {
+	if e == nil || !visit(e) {
+		return
+	}
+	switch e := e.(type) {
+	case unaryExpr:
+		walk(e.operand, visit)
+	case binaryExpr:
+		walk(e.left, visit)
+		walk(e.right, visit)
+	case isNullExpr:
+		walk(e.operand, visit)
+	case inExpr:
+		walk(e.operand, visit)
+		for _, item := range e.list {
+			walk(item, visit)
+		}
+	case betweenExpr:
+		walk(e.operand, visit)
+		walk(e.low, visit)
+		walk(e.high, visit)
+	case likeExpr:
+		walk(e.operand, visit)
+		walk(e.pattern, visit)
+	case callExpr:
+		for _, arg := range e.args {
+			walk(arg, visit)
+		}
+	case caseExpr:
+		walk(e.operand, visit)
+		for _, when := range e.whens {
+			walk(when.when, visit)
+			walk(when.then, visit)
+		}
+		walk(e.orElse, visit)
+	}
+}
@@ -0,0 +1,658 @@
+package yxquery
+
+import (
+	"errors"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"io"
+	"math/big"
+)
+
+// An operator produces the rows of a step of a query plan. Rows are pulled with next, which returns io.EOF after the
+// last row. Operators may reuse the slice of a row after the next call to next, so rows that are kept must be copied.
+type operator interface {
+	open() error
+	next() ([]any, error)
+	close() error
+}
+
+// scan reads the records of a .yxdb file. Rows have a value for every field of the file, but only the fields marked
+// in used are read; the others are null.
+type scan struct {
+	path   string
+	fields []metafield.MetaInfoField
+	used   []bool
+
+	// predicates are conditions of the WHERE clause evaluated by the reader.
+	predicates []yx.Predicate
+
+	// nullable is set for tables on the right of a LEFT JOIN, whose columns are null when no record matches, so
+	// that conditions on them cannot be evaluated by the reader.
+	nullable bool
+
+	reader yx.Reader
+	read   []int
+}
+
+func (s *scan) open() error {
+	names := []string{}
+	s.read = nil
+	for index, used := range s.used {
+		if used {
+			names = append(names, s.fields[index].Name)
+			s.read = append(s.read, index)
+		}
+	}
+	opts := []yx.Option{yx.WithColumns(names...)}
+	for _, predicate := range s.predicates {
+		opts = append(opts, yx.WithPredicate(predicate))
+	}
+	var err error
+	s.reader, err = yx.ReadFile(s.path, opts...)
+	return err
+}
+
+func (s *scan) next() ([]any, error) {
+	if !s.reader.Next() {
+		if err := s.reader.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	row := make([]any, len(s.fields))
+	for column, index := range s.read {
+		value, isNull := s.reader.ReadValueWithIndex(column)
+		if isNull {
+			continue
+		}
+		switch value := value.(type) {
+		case byte:
+			row[index] = int64(value)
+		case decimal.Decimal:
+			rat, err := value.Rat()
+			if err != nil {
+				return nil, fmt.Errorf(`field '%v': %w`, s.fields[index].Name, err)
+			}
+			row[index] = rat
+		default:
+			row[index] = value
+		}
+	}
+	return row, nil
+}
+
+func (s *scan) close() error {
+	if s.reader == nil {
+		return nil
+	}
+	err := s.reader.Close()
+	s.reader = nil
+	return err
+}
+
+// filter passes the rows for which every condition is true.
+type filter struct {
+	input      operator
+	conditions []compiled
+}
+
+func (f *filter) open() error {
+	return f.input.open()
+}
+
+func (f *filter) next() ([]any, error) {
+	for {
+		row, err := f.input.next()
+		if err != nil {
+			return nil, err
+		}
+		passed, err := evalConditions(f.conditions, row)
+		if err != nil {
+			return nil, err
+		}
+		if passed {
+			return row, nil
+		}
+	}
+}
+
+func (f *filter) close() error {
+	return f.input.close()
+}
+
+// project evaluates the select list.
+type project struct {
+	input operator
+	exprs []compiled
+}
+
+func (p *project) open() error {
+	return p.input.open()
+}
+
+func (p *project) next() ([]any, error) {
+	row, err := p.input.next()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, len(p.exprs))
+	for index, e := range p.exprs {
+		result[index], err = e.eval(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (p *project) close() error {
+	return p.input.close()
+}
+
+// join joins each row of left with the rows of right. The rows of right are held in memory, in a hash table keyed by
+// the equality conditions of the join if it has any.
+type join struct {
+	kind        joinKind
+	left, right operator
+	rightWidth  int
+
+	// leftKeys and rightKeys are the two sides of the equality conditions, compiled against the left and right
+	// schemas, and keyKinds the kinds they are compared as.
+	leftKeys, rightKeys []compiled
+	keyKinds            []kind
+
+	// residual are the other conditions of the join, compiled against the joined schema.
+	residual []compiled
+
+	table map[string][][]any
+	rows  [][]any
+
+	current []any
+	matches [][]any
+	pos     int
+	matched bool
+}
+
+func (j *join) open() error {
+	err := j.right.open()
+	if err != nil {
+		return err
+	}
+	j.table = map[string][][]any{}
+	j.rows = nil
+	for {
+		row, err := j.right.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = j.right.close()
+			return err
+		}
+		if len(j.rightKeys) == 0 {
+			j.rows = append(j.rows, row)
+			continue
+		}
+		key, ok, err := encodeKey(j.rightKeys, j.keyKinds, row)
+		if err != nil {
+			_ = j.right.close()
+			return err
+		}
+		// rows with a null key never match
+		if ok {
+			j.table[key] = append(j.table[key], row)
+		}
+	}
+	err = j.right.close()
+	if err != nil {
+		return err
+	}
+	j.current = nil
+	return j.left.open()
+}
+
+func (j *join) next() ([]any, error) {
+	for {
+		for j.pos < len(j.matches) {
+			row := joinRows(j.current, j.matches[j.pos], j.rightWidth)
+			j.pos++
+			passed, err := evalConditions(j.residual, row)
+			if err != nil {
+				return nil, err
+			}
+			if passed {
+				j.matched = true
+				return row, nil
+			}
+		}
+		if j.current != nil && j.kind == leftJoin && !j.matched {
+			row := joinRows(j.current, nil, j.rightWidth)
+			j.current = nil
+			return row, nil
+		}
+
+		left, err := j.left.next()
+		if err != nil {
+			return nil, err
+		}
+		j.current, j.matched, j.pos = left, false, 0
+		if len(j.leftKeys) == 0 {
+			j.matches = j.rows
+			continue
+		}
+		key, ok, err := encodeKey(j.leftKeys, j.keyKinds, left)
+		if err != nil {
+			return nil, err
+		}
+		j.matches = nil
+		if ok {
+			j.matches = j.table[key]
+		}
+	}
+}
+
+func (j *join) close() error {
+	j.table, j.rows, j.matches = nil, nil, nil
+	return j.left.close()
+}
+
+// joinRows appends right, or nulls if right is nil, to left.
+func joinRows(left, right []any, rightWidth int) []any {
+	row := make([]any, len(left), len(left)+rightWidth)
+	copy(row, left)
+	if right == nil {
+		return append(row, make([]any, rightWidth)...)
+	}
+	return append(row, right...)
+}
+
+func evalConditions(conditions []compiled, row []any) (bool, error) {
+	for _, condition := range conditions {
+		value, err := condition.eval(row)
+		if value != true || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// encodeKey encodes the values of keys, converted to kinds, as a string. It returns false if any value is null.
+func encodeKey(keys []compiled, kinds []kind, row []any) (string, bool, error) {
+	var key []byte
+	for index, k := range keys {
+		value, err := k.eval(row)
+		if value == nil || err != nil {
+			return ``, false, err
+		}
+		value = convert(value, kinds[index])
+		if value == 0.0 {
+			// -0 and 0 are equal
+			value = 0.0
+		}
+		key = appendValue(key, value)
+	}
+	return string(key), true, nil
+}
+
+// aggregate groups rows by the values of the GROUP BY expressions and computes aggregate functions for each group.
+// Its rows hold the values of the GROUP BY expressions followed by the results of the aggregate functions. Groups are
+// held in memory and produced in the order they were first seen.
+type aggregate struct {
+	input      operator
+	groups     []compiled
+	aggregates []aggregateCall
+
+	rows [][]any
+	pos  int
+}
+
+// aggregateCall is an aggregate function of a query. Arg is nil for COUNT(*).
+type aggregateCall struct {
+	name     string
+	arg      *compiled
+	distinct bool
+	kind     kind
+}
+
+type group struct {
+	values       []any
+	accumulators []accumulator
+}
+
+func (a *aggregate) open() error {
+	err := a.input.open()
+	if err != nil {
+		return err
+	}
+	groups := map[string]*group{}
+	var order []*group
+	for {
+		row, err := a.input.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = a.input.close()
+			return err
+		}
+		values := make([]any, len(a.groups))
+		var key []byte
+		for index, g := range a.groups {
+			values[index], err = g.eval(row)
+			if err != nil {
+				_ = a.input.close()
+				return err
+			}
+			key = appendValue(key, values[index])
+		}
+		current, ok := groups[string(key)]
+		if !ok {
+			current = a.newGroup(values)
+			groups[string(key)] = current
+			order = append(order, current)
+		}
+		for index, call := range a.aggregates {
+			value := any(true)
+			if call.arg != nil {
+				value, err = call.arg.eval(row)
+				if err != nil {
+					_ = a.input.close()
+					return err
+				}
+			}
+			err = current.accumulators[index].add(value)
+			if err != nil {
+				_ = a.input.close()
+				return fmt.Errorf(`%w in %v`, err, call.name)
+			}
+		}
+	}
+	// without GROUP BY, the aggregates of no rows are a single row
+	if len(a.groups) == 0 && len(order) == 0 {
+		order = append(order, a.newGroup(nil))
+	}
+	a.rows = make([][]any, len(order))
+	for index, g := range order {
+		row := g.values
+		for _, acc := range g.accumulators {
+			row = append(row, acc.result())
+		}
+		a.rows[index] = row
+	}
+	a.pos = 0
+	return a.input.close()
+}
+
+func (a *aggregate) newGroup(values []any) *group {
+	g := &group{values: values}
+	for _, call := range a.aggregates {
+		g.accumulators = append(g.accumulators, newAccumulator(call))
+	}
+	return g
+}
+
+func (a *aggregate) next() ([]any, error) {
+	if a.pos >= len(a.rows) {
+		return nil, io.EOF
+	}
+	a.pos++
+	return a.rows[a.pos-1], nil
+}
+
+func (a *aggregate) close() error {
+	a.rows = nil
+	return nil
+}
+
+// aggregates lists the aggregate functions.
+var aggregates = map[string]bool{`COUNT`: true, `SUM`: true, `AVG`: true, `MIN`: true, `MAX`: true}
+
+// An accumulator computes an aggregate function over the values added to it, ignoring nulls.
+type accumulator interface {
+	add(value any) error
+	result() any
+}
+
+func newAccumulator(call aggregateCall) accumulator {
+	var acc accumulator
+	switch call.name {
+	case `COUNT`:
+		acc = &countAccumulator{}
+	case `SUM`:
+		acc = &sumAccumulator{kind: call.kind}
+	case `AVG`:
+		acc = &avgAccumulator{}
+	case `MIN`:
+		acc = &extremeAccumulator{sign: -1}
+	default:
+		acc = &extremeAccumulator{sign: 1}
+	}
+	if call.distinct {
+		return &distinctAccumulator{seen: map[string]bool{}, inner: acc}
+	}
+	return acc
+}
+
+type countAccumulator struct {
+	count int64
+}
+
+func (c *countAccumulator) add(value any) error {
+	if value != nil {
+		c.count++
+	}
+	return nil
+}
+
+func (c *countAccumulator) result() any {
+	return c.count
+}
+
+// sumAccumulator sums values of kind, which are integers as int64, failing if the sum overflows, decimals exactly as
+// *big.Rat, and floats as float64. The sum of no values is null.
+type sumAccumulator struct {
+	kind     kind
+	ints     int64
+	decimals big.Rat
+	floats   float64
+	any      bool
+}
+
+func (s *sumAccumulator) add(value any) error {
+	if value == nil {
+		return nil
+	}
+	s.any = true
+	switch s.kind {
+	case kindInt:
+		sum, err := addInt(s.ints, value.(int64))
+		s.ints = sum
+		return err
+	case kindDecimal:
+		s.decimals.Add(&s.decimals, toRat(value))
+	default:
+		s.floats += toFloat(value)
+	}
+	return nil
+}
+
+func (s *sumAccumulator) result() any {
+	switch {
+	case !s.any:
+		return nil
+	case s.kind == kindInt:
+		return s.ints
+	case s.kind == kindDecimal:
+		return new(big.Rat).Set(&s.decimals)
+	default:
+		return s.floats
+	}
+}
+
+type avgAccumulator struct {
+	sum   float64
+	count int64
+}
+
+func (a *avgAccumulator) add(value any) error {
+	if value != nil {
+		a.sum += toFloat(value)
+		a.count++
+	}
+	return nil
+}
+
+func (a *avgAccumulator) result() any {
+	if a.count == 0 {
+		return nil
+	}
+	return a.sum / float64(a.count)
+}
+
+// extremeAccumulator keeps the smallest value if sign is -1, and the largest if sign is 1.
+type extremeAccumulator struct {
+	sign  int
+	value any
+}
+
+func (e *extremeAccumulator) add(value any) error {
+	if value != nil && (e.value == nil || compareValues(value, e.value)*e.sign > 0) {
+		e.value = value
+	}
+	return nil
+}
+
+func (e *extremeAccumulator) result() any {
+	return e.value
+}
+
+// distinctAccumulator passes each distinct value to inner once.
+type distinctAccumulator struct {
+	seen  map[string]bool
+	inner accumulator
+}
+
+func (d *distinctAccumulator) add(value any) error {
+	if value == nil {
+		return nil
+	}
+	key := string(appendValue(nil, value))
+	if d.seen[key] {
+		return nil
+	}
+	d.seen[key] = true
+	return d.inner.add(value)
+}
+
+func (d *distinctAccumulator) result() any {
+	return d.inner.result()
+}
+
+// distinct removes duplicate rows, keeping the first. The rows seen are held in memory.
+type distinct struct {
+	input operator
+	seen  map[string]bool
+}
+
+func (d *distinct) open() error {
+	d.seen = map[string]bool{}
+	return d.input.open()
+}
+
+func (d *distinct) next() ([]any, error) {
+	for {
+		row, err := d.input.next()
+		if err != nil {
+			return nil, err
+		}
+		var key []byte
+		for _, value := range row {
+			key = appendValue(key, value)
+		}
+		if !d.seen[string(key)] {
+			d.seen[string(key)] = true
+			return row, nil
+		}
+	}
+}
+
+func (d *distinct) close() error {
+	d.seen = nil
+	return d.input.close()
+}
+
+// limit skips offset rows and then passes at most count rows, or every row if count is negative.
+type limit struct {
+	input         operator
+	offset, count int64
+	passed        int64
+	exhausted     bool
+}
+
+func (l *limit) open() error {
+	l.passed, l.exhausted = 0, false
+	err := l.input.open()
+	if err != nil {
+		return err
+	}
+	for skipped := int64(0); skipped < l.offset; skipped++ {
+		_, err = l.input.next()
+		if errors.Is(err, io.EOF) {
+			l.exhausted = true
+			break
+		}
+		if err != nil {
+			_ = l.input.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *limit) next() ([]any, error) {
+	if l.exhausted || l.count >= 0 && l.passed >= l.count {
+		return nil, io.EOF
+	}
+	row, err := l.input.next()
+	if err == nil {
+		l.passed++
+	}
+	return row, err
+}
+
+func (l *limit) close() error {
+	return l.input.close()
+}
+
+// trim removes the columns added to the select list for ORDER BY.
+type trim struct {
+	input operator
+	width int
+}
+
+func (t *trim) open() error {
+	return t.input.open()
+}
+
+func (t *trim) next() ([]any, error) {
+	row, err := t.input.next()
+	if err != nil {
+		return nil, err
+	}
+	return row[:t.width], nil
+}
+
+func (t *trim) close() error {
+	return t.input.close()
+}
+
+// limitValue checks that the LIMIT or OFFSET value is a non-negative integer.
+func limitValue(clause string, value compiled) (int64, error) {
+	count, ok := value.value.(int64)
+	if !value.constant || !ok || count < 0 {
+		return 0, fmt.Errorf(`%v must be a non-negative integer`, clause)
+	}
+	return count, nil
+}
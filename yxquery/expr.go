@@ -0,0 +1,870 @@
+package yxquery
+
+import (
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// column is a column of the rows produced by an operator.
+type column struct {
+	// table is the name that qualifies the column: the alias of its table, or the name of the table or file.
+	table string
+	name  string
+	field metafield.MetaInfoField
+
+	// hidden columns are the right hand columns of a USING join, which can only be referred to by qualified names.
+	hidden bool
+
+	// source is the scan that reads the column, and index its field index in the scan, or nil for computed columns.
+	source *scan
+	index  int
+}
+
+// schema lists the columns of the rows produced by an operator.
+type schema struct {
+	columns []column
+}
+
+// resolve returns the index of the column a reference refers to. Names match exactly or, if no name matches exactly,
+// ignoring case.
+func (s *schema) resolve(ref columnExpr) (int, error) {
+	for _, equal := range []func(string, string) bool{func(a, b string) bool { return a == b }, strings.EqualFold} {
+		found := -1
+		for index, c := range s.columns {
+			if !equal(c.name, ref.name) {
+				continue
+			}
+			if ref.table == `` && c.hidden || ref.table != `` && !strings.EqualFold(c.table, ref.table) {
+				continue
+			}
+			if found >= 0 {
+				return 0, fmt.Errorf(`field '%v' is ambiguous; qualify it with the name of its table`, ref)
+			}
+			found = index
+		}
+		if found >= 0 {
+			return found, nil
+		}
+	}
+	return 0, fmt.Errorf(`field '%v' does not exist: %w`, ref, yx.ErrFieldNotFound)
+}
+
+// exprKey formats e with its column references replaced by their indexes in s, so that the same expression written
+// with and without table names has the same key.
+func exprKey(e expr, s *schema) string {
+	return formatExpr(e, func(ref columnExpr) string {
+		if index, err := s.resolve(ref); err == nil {
+			return fmt.Sprintf(`#%d`, index)
+		}
+		return ref.String()
+	})
+}
+
+type evaluator func(row []any) (any, error)
+
+// compiled is an expression compiled against a schema.
+type compiled struct {
+	eval evaluator
+	kind kind
+
+	// field is the type of the output field holding the values of the expression.
+	field metafield.MetaInfoField
+
+	// constant is set when the expression does not refer to any column, in which case value is its value.
+	constant bool
+	value    any
+}
+
+func constant(value any) compiled {
+	k := valueKind(value)
+	field := kindField(k)
+	if rat, ok := value.(*big.Rat); ok {
+		field = decimalField(0, ratScale(rat))
+	}
+	return compiled{
+		eval:     func([]any) (any, error) { return value, nil },
+		kind:     k,
+		field:    field,
+		constant: true,
+		value:    value,
+	}
+}
+
+// compiler compiles expressions against the columns of a schema.
+type compiler struct {
+	schema *schema
+
+	// clause names the clause being compiled in errors, such as "WHERE".
+	clause string
+
+	// computed is set when compiling expressions evaluated after aggregation. It maps the keys of the GROUP BY
+	// expressions and aggregate functions, computed against input, to their columns in schema.
+	computed map[string]int
+	input    *schema
+}
+
+func (c *compiler) compile(e expr) (compiled, error) {
+	if c.computed != nil {
+		if index, ok := c.computed[exprKey(e, c.input)]; ok {
+			return c.column(index), nil
+		}
+		if ref, ok := e.(columnExpr); ok {
+			if _, err := c.input.resolve(ref); err != nil {
+				return compiled{}, err
+			}
+			return compiled{}, fmt.Errorf(`field '%v' must appear in GROUP BY or be used in an aggregate function`, ref)
+		}
+	}
+
+	switch e := e.(type) {
+	case literalExpr:
+		return constant(e.value), nil
+	case columnExpr:
+		index, err := c.schema.resolve(e)
+		if err != nil {
+			return compiled{}, err
+		}
+		return c.column(index), nil
+	case unaryExpr:
+		return c.unary(e)
+	case binaryExpr:
+		return c.binary(e)
+	case isNullExpr:
+		operand, err := c.compile(e.operand)
+		if err != nil {
+			return compiled{}, err
+		}
+		return c.fold(compiled{eval: func(row []any) (any, error) {
+			value, err := operand.eval(row)
+			return (value == nil) != e.not, err
+		}, kind: kindBool}, operand)
+	case inExpr:
+		return c.in(e)
+	case betweenExpr:
+		// x BETWEEN a AND b is x >= a AND x <= b
+		var test expr = binaryExpr{
+			op:    `AND`,
+			left:  binaryExpr{op: `>=`, left: e.operand, right: e.low},
+			right: binaryExpr{op: `<=`, left: e.operand, right: e.high},
+		}
+		if e.not {
+			test = unaryExpr{op: `NOT`, operand: test}
+		}
+		return c.compile(test)
+	case likeExpr:
+		return c.like(e)
+	case callExpr:
+		if aggregates[e.name] {
+			return compiled{}, fmt.Errorf(`aggregate function %v is not allowed in %v`, e, c.clause)
+		}
+		return c.call(e)
+	case caseExpr:
+		return c.caseExpr(e)
+	}
+	return compiled{}, fmt.Errorf(`unsupported expression %v`, e)
+}
+
+func (c *compiler) column(index int) compiled {
+	col := c.schema.columns[index]
+	if col.source != nil {
+		col.source.used[col.index] = true
+	}
+	field := col.field
+	return compiled{
+		eval:  func(row []any) (any, error) { return row[index], nil },
+		kind:  fieldKind(field),
+		field: metafield.MetaInfoField{Type: field.Type, Size: field.Size, Scale: field.Scale},
+	}
+}
+
+// fold sets the kind field of result if it was not set and, if every operand is constant, evaluates it once.
+func (c *compiler) fold(result compiled, operands ...compiled) (compiled, error) {
+	if result.field.Type == `` {
+		result.field = kindField(result.kind)
+	}
+	for _, operand := range operands {
+		if !operand.constant {
+			return result, nil
+		}
+	}
+	value, err := result.eval(nil)
+	if err != nil {
+		return compiled{}, err
+	}
+	folded := constant(value)
+	folded.kind = result.kind
+	folded.field = result.field
+	return folded, nil
+}
+
+func (c *compiler) compileAll(exprs ...expr) ([]compiled, error) {
+	result := make([]compiled, len(exprs))
+	for index, e := range exprs {
+		var err error
+		result[index], err = c.compile(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (c *compiler) unary(e unaryExpr) (compiled, error) {
+	operand, err := c.compile(e.operand)
+	if err != nil {
+		return compiled{}, err
+	}
+	if e.op == `NOT` {
+		if operand.kind != kindBool && operand.kind != kindNull {
+			return compiled{}, fmt.Errorf(`NOT requires a boolean operand but %v is %v`, e.operand, operand.kind)
+		}
+		return c.fold(compiled{eval: func(row []any) (any, error) {
+			value, err := operand.eval(row)
+			if value == nil || err != nil {
+				return nil, err
+			}
+			return !value.(bool), nil
+		}, kind: kindBool}, operand)
+	}
+	if !operand.kind.numeric() && operand.kind != kindNull {
+		return compiled{}, fmt.Errorf(`- requires a number but %v is %v`, e.operand, operand.kind)
+	}
+	result := compiled{eval: func(row []any) (any, error) {
+		value, err := operand.eval(row)
+		if value == nil || err != nil {
+			return nil, err
+		}
+		return negate(value, e)
+	}, kind: operand.kind}
+	if operand.kind == kindDecimal {
+		result.field = operand.field
+	}
+	return c.fold(result, operand)
+}
+
+// negate returns the negative of a number, failing for the one integer whose negative does not fit in an int64.
+func negate(value any, e expr) (any, error) {
+	switch value := value.(type) {
+	case int64:
+		if value == math.MinInt64 {
+			return nil, fmt.Errorf(`%w in %v`, errOverflow, e)
+		}
+		return -value, nil
+	case *big.Rat:
+		return new(big.Rat).Neg(value), nil
+	}
+	return -value.(float64), nil
+}
+
+var comparisonTests = map[string]func(int) bool{
+	`=`:  func(result int) bool { return result == 0 },
+	`<>`: func(result int) bool { return result != 0 },
+	`<`:  func(result int) bool { return result < 0 },
+	`<=`: func(result int) bool { return result <= 0 },
+	`>`:  func(result int) bool { return result > 0 },
+	`>=`: func(result int) bool { return result >= 0 },
+}
+
+func (c *compiler) binary(e binaryExpr) (compiled, error) {
+	left, err := c.compile(e.left)
+	if err != nil {
+		return compiled{}, err
+	}
+	right, err := c.compile(e.right)
+	if err != nil {
+		return compiled{}, err
+	}
+	switch e.op {
+	case `AND`, `OR`:
+		return c.logical(e, left, right)
+	case `||`:
+		if left.kind == kindBlob || right.kind == kindBlob {
+			return compiled{}, fmt.Errorf(`cannot concatenate blobs in %v`, e)
+		}
+		return c.fold(compiled{eval: func(row []any) (any, error) {
+			a, err := left.eval(row)
+			if a == nil || err != nil {
+				return nil, err
+			}
+			b, err := right.eval(row)
+			if b == nil || err != nil {
+				return nil, err
+			}
+			return toText(a) + toText(b), nil
+		}, kind: kindString}, left, right)
+	case `+`, `-`, `*`, `/`, `%`:
+		return c.arithmetic(e, left, right)
+	}
+
+	test := comparisonTests[e.op]
+	left, right, err = c.comparable(e, left, right)
+	if err != nil {
+		return compiled{}, err
+	}
+	return c.fold(compiled{eval: func(row []any) (any, error) {
+		a, err := left.eval(row)
+		if a == nil || err != nil {
+			return nil, err
+		}
+		b, err := right.eval(row)
+		if b == nil || err != nil {
+			return nil, err
+		}
+		return test(compareValues(a, b)), nil
+	}, kind: kindBool}, left, right)
+}
+
+// comparable checks that left and right can be compared, converting a constant string compared with a date to a
+// time.Time.
+func (c *compiler) comparable(e expr, left, right compiled) (compiled, compiled, error) {
+	for _, pair := range [][2]*compiled{{&left, &right}, {&right, &left}} {
+		value, side := pair[0], pair[1]
+		if value.constant && value.kind == kindString && side.kind == kindTime {
+			moment, ok := parseTime(value.value.(string))
+			if !ok {
+				return left, right, fmt.Errorf(`'%v' is not a date in %v: %w`, value.value, e, yx.ErrTypeMismatch)
+			}
+			*value = constant(moment)
+		}
+	}
+	if _, ok := commonKind(left.kind, right.kind); !ok {
+		return left, right, fmt.Errorf(`cannot compare %v with %v in %v: %w`, left.kind, right.kind, e, yx.ErrTypeMismatch)
+	}
+	return left, right, nil
+}
+
+// logical compiles AND and OR with the three-valued logic of SQL: FALSE AND NULL is FALSE, TRUE OR NULL is TRUE, and
+// other combinations with NULL are NULL.
+func (c *compiler) logical(e binaryExpr, left, right compiled) (compiled, error) {
+	for _, operand := range []compiled{left, right} {
+		if operand.kind != kindBool && operand.kind != kindNull {
+			return compiled{}, fmt.Errorf(`%v requires boolean operands in %v`, e.op, e)
+		}
+	}
+	// decisive is the value of an operand that decides the result on its own
+	decisive := e.op == `OR`
+	return c.fold(compiled{eval: func(row []any) (any, error) {
+		a, err := left.eval(row)
+		if err != nil || a == decisive {
+			return a, err
+		}
+		b, err := right.eval(row)
+		if err != nil || b == decisive {
+			return b, err
+		}
+		if a == nil || b == nil {
+			return nil, nil
+		}
+		return !decisive, nil
+	}, kind: kindBool}, left, right)
+}
+
+// intOperations are the integer operations that can overflow.
+var intOperations = map[string]func(x, y int64) (int64, error){`+`: addInt, `-`: subtractInt, `*`: multiplyInt}
+
+// arithmetic compiles an operation on numbers. Integer operations give integers and fail if the result does not fit
+// in an int64. Addition, subtraction, and multiplication of decimals and integers give exact decimals, while division
+// and remainder of decimals, and any operation with a float operand, give floats. Division and remainder by zero are
+// null.
+func (c *compiler) arithmetic(e binaryExpr, left, right compiled) (compiled, error) {
+	k, ok := commonKind(left.kind, right.kind)
+	if !ok || !k.numeric() && k != kindNull {
+		return compiled{}, fmt.Errorf(`%v requires numbers in %v: %w`, e.op, e, yx.ErrTypeMismatch)
+	}
+	op := e.op
+	result := compiled{kind: k}
+	if k == kindDecimal {
+		scale := max(left.field.Scale, right.field.Scale)
+		switch op {
+		case `/`, `%`:
+			result.kind = kindFloat
+		case `*`:
+			result.field = decimalField(left.field.Size+right.field.Size, left.field.Scale+right.field.Scale)
+		default:
+			result.field = decimalField(max(left.field.Size, right.field.Size)+1, scale)
+		}
+	}
+	exact := result.kind == kindDecimal
+	result.eval = func(row []any) (any, error) {
+		a, err := left.eval(row)
+		if a == nil || err != nil {
+			return nil, err
+		}
+		b, err := right.eval(row)
+		if b == nil || err != nil {
+			return nil, err
+		}
+		x, xInt := a.(int64)
+		y, yInt := b.(int64)
+		if xInt && yInt {
+			if operation, ok := intOperations[op]; ok {
+				result, err := operation(x, y)
+				if err != nil {
+					return nil, fmt.Errorf(`%w in %v`, err, e)
+				}
+				return result, nil
+			}
+			if y == 0 {
+				return nil, nil
+			}
+			if op == `%` {
+				return x % y, nil
+			}
+			if x == math.MinInt64 && y == -1 {
+				return nil, fmt.Errorf(`%w in %v`, errOverflow, e)
+			}
+			return x / y, nil
+		}
+		if exact {
+			x, y := toRat(a), toRat(b)
+			switch op {
+			case `+`:
+				return new(big.Rat).Add(x, y), nil
+			case `-`:
+				return new(big.Rat).Sub(x, y), nil
+			}
+			return new(big.Rat).Mul(x, y), nil
+		}
+		f, g := toFloat(a), toFloat(b)
+		switch op {
+		case `+`:
+			return f + g, nil
+		case `-`:
+			return f - g, nil
+		case `*`:
+			return f * g, nil
+		}
+		if g == 0 {
+			return nil, nil
+		}
+		if op == `/` {
+			return f / g, nil
+		}
+		return math.Mod(f, g), nil
+	}
+	return c.fold(result, left, right)
+}
+
+func (c *compiler) in(e inExpr) (compiled, error) {
+	operand, err := c.compile(e.operand)
+	if err != nil {
+		return compiled{}, err
+	}
+	list, err := c.compileAll(e.list...)
+	if err != nil {
+		return compiled{}, err
+	}
+	for index := range list {
+		operand, list[index], err = c.comparable(e, operand, list[index])
+		if err != nil {
+			return compiled{}, err
+		}
+	}
+	// x IN (a, b) is TRUE if x equals a or b, NULL if x, a, or b is null, and otherwise FALSE
+	return c.fold(compiled{eval: func(row []any) (any, error) {
+		a, err := operand.eval(row)
+		if a == nil || err != nil {
+			return nil, err
+		}
+		sawNull := false
+		for _, item := range list {
+			b, err := item.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			if b == nil {
+				sawNull = true
+			} else if compareValues(a, b) == 0 {
+				return !e.not, nil
+			}
+		}
+		if sawNull {
+			return nil, nil
+		}
+		return e.not, nil
+	}, kind: kindBool}, append(list, operand)...)
+}
+
+// like compiles a LIKE test, where % in the pattern matches any text and _ matches any single character.
+func (c *compiler) like(e likeExpr) (compiled, error) {
+	operand, err := c.compile(e.operand)
+	if err != nil {
+		return compiled{}, err
+	}
+	pattern, err := c.compile(e.pattern)
+	if err != nil {
+		return compiled{}, err
+	}
+	for _, value := range []compiled{operand, pattern} {
+		if value.kind != kindString && value.kind != kindNull {
+			return compiled{}, fmt.Errorf(`LIKE requires strings in %v: %w`, e, yx.ErrTypeMismatch)
+		}
+	}
+	var last string
+	var matcher *regexp.Regexp
+	return c.fold(compiled{eval: func(row []any) (any, error) {
+		a, err := operand.eval(row)
+		if a == nil || err != nil {
+			return nil, err
+		}
+		b, err := pattern.eval(row)
+		if b == nil || err != nil {
+			return nil, err
+		}
+		if matcher == nil || b.(string) != last {
+			last = b.(string)
+			matcher = likeRegexp(last)
+		}
+		return matcher.MatchString(a.(string)) != e.not, nil
+	}, kind: kindBool}, operand, pattern)
+}
+
+func likeRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	for _, char := range pattern {
+		switch char {
+		case '%':
+			b.WriteString(`.*`)
+		case '_':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.MustCompile(b.String())
+}
+
+// caseExpr compiles a CASE expression. The simple form, CASE x WHEN a THEN ..., compares x with each value.
+func (c *compiler) caseExpr(e caseExpr) (compiled, error) {
+	var conditions, results []compiled
+	for _, when := range e.whens {
+		condition := when.when
+		if e.operand != nil {
+			condition = binaryExpr{op: `=`, left: e.operand, right: when.when}
+		}
+		compiledCondition, err := c.compile(condition)
+		if err != nil {
+			return compiled{}, err
+		}
+		if compiledCondition.kind != kindBool && compiledCondition.kind != kindNull {
+			return compiled{}, fmt.Errorf(`WHEN requires a boolean condition but %v is %v`, condition, compiledCondition.kind)
+		}
+		result, err := c.compile(when.then)
+		if err != nil {
+			return compiled{}, err
+		}
+		conditions = append(conditions, compiledCondition)
+		results = append(results, result)
+	}
+	orElse := constant(nil)
+	if e.orElse != nil {
+		var err error
+		orElse, err = c.compile(e.orElse)
+		if err != nil {
+			return compiled{}, err
+		}
+	}
+	results = append(results, orElse)
+	result, err := combine(e, results)
+	if err != nil {
+		return compiled{}, err
+	}
+	result.eval = func(row []any) (any, error) {
+		for index, condition := range conditions {
+			value, err := condition.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			if value == true {
+				return convertResult(results[index].eval(row))(result.kind)
+			}
+		}
+		return convertResult(orElse.eval(row))(result.kind)
+	}
+	return c.fold(result, append(conditions, results...)...)
+}
+
+// combine returns the kind and field of an expression that returns any of values, such as CASE and COALESCE. The
+// field is the field of the values if all of them that are not null have the same type, size, and scale.
+func combine(e expr, values []compiled) (compiled, error) {
+	k := kindNull
+	var field *metafield.MetaInfoField
+	same := true
+	for index, value := range values {
+		var ok bool
+		if k, ok = commonKind(k, value.kind); !ok {
+			return compiled{}, fmt.Errorf(`cannot combine %v with %v in %v: %w`, k, value.kind, e, yx.ErrTypeMismatch)
+		}
+		switch {
+		case value.kind == kindNull:
+		case field == nil:
+			field = &values[index].field
+		default:
+			same = same && sameType(*field, value.field)
+		}
+	}
+	if k == kindDecimal && (field == nil || !same) {
+		// decimals of different sizes and scales, or decimals and integers, are combined in a field wide enough for all
+		var size, scale int
+		for _, value := range values {
+			size, scale = max(size, value.field.Size), max(scale, value.field.Scale)
+		}
+		return compiled{kind: k, field: decimalField(size, scale)}, nil
+	}
+	if field == nil || !same || fieldKind(*field) != k {
+		return compiled{kind: k, field: kindField(k)}, nil
+	}
+	return compiled{kind: k, field: *field}, nil
+}
+
+func sameType(a, b metafield.MetaInfoField) bool {
+	return a.Type == b.Type && a.Size == b.Size && a.Scale == b.Scale
+}
+
+// convertResult converts the result of an evaluator to kind k.
+func convertResult(value any, err error) func(k kind) (any, error) {
+	return func(k kind) (any, error) {
+		if value == nil || err != nil {
+			return nil, err
+		}
+		return convert(value, k), nil
+	}
+}
+
+func (c *compiler) call(e callExpr) (compiled, error) {
+	function, ok := functions[e.name]
+	if !ok {
+		return compiled{}, fmt.Errorf(`unknown function %v`, e.name)
+	}
+	if e.star || e.distinct {
+		return compiled{}, fmt.Errorf(`%v cannot be used with * or DISTINCT`, e.name)
+	}
+	if len(e.args) < function.minArgs || len(e.args) > function.maxArgs {
+		return compiled{}, fmt.Errorf(`%v takes %v arguments but got %v`, e.name, argCount(function), len(e.args))
+	}
+	args, err := c.compileAll(e.args...)
+	if err != nil {
+		return compiled{}, err
+	}
+	result, err := function.compile(e, args)
+	if err != nil {
+		return compiled{}, err
+	}
+	return c.fold(result, args...)
+}
+
+func argCount(f function) string {
+	switch {
+	case f.maxArgs == math.MaxInt:
+		return fmt.Sprintf(`at least %v`, f.minArgs)
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprint(f.minArgs)
+	}
+	return fmt.Sprintf(`%v to %v`, f.minArgs, f.maxArgs)
+}
+
+// function is a scalar function. compile checks the kinds of the arguments and returns the evaluator and kind.
+type function struct {
+	minArgs, maxArgs int
+	compile          func(e callExpr, args []compiled) (compiled, error)
+}
+
+var functions = map[string]function{
+	`UPPER`:     {1, 1, stringFunction(strings.ToUpper)},
+	`LOWER`:     {1, 1, stringFunction(strings.ToLower)},
+	`TRIM`:      {1, 1, stringFunction(strings.TrimSpace)},
+	`LENGTH`:    {1, 1, lengthFunction},
+	`SUBSTR`:    {2, 3, substrFunction},
+	`SUBSTRING`: {2, 3, substrFunction},
+	`ABS`:       {1, 1, absFunction},
+	`ROUND`:     {1, 2, roundFunction},
+	`COALESCE`:  {1, math.MaxInt, coalesceFunction},
+	`YEAR`:      {1, 1, timePartFunction(func(t time.Time) int64 { return int64(t.Year()) })},
+	`MONTH`:     {1, 1, timePartFunction(func(t time.Time) int64 { return int64(t.Month()) })},
+	`DAY`:       {1, 1, timePartFunction(func(t time.Time) int64 { return int64(t.Day()) })},
+}
+
+func checkArgs(e callExpr, args []compiled, kinds ...kind) error {
+	for index, arg := range args {
+		expected := kinds[min(index, len(kinds)-1)]
+		if arg.kind != expected && arg.kind != kindNull && !(expected == kindFloat && arg.kind.numeric()) {
+			return fmt.Errorf(`%v requires argument %v to be %v but %v is %v: %w`, e.name, index+1, expected, e.args[index], arg.kind, yx.ErrTypeMismatch)
+		}
+	}
+	return nil
+}
+
+// evalArgs evaluates args, returning nil if any of them is null.
+func evalArgs(args []compiled, row []any) ([]any, error) {
+	values := make([]any, len(args))
+	for index, arg := range args {
+		value, err := arg.eval(row)
+		if value == nil || err != nil {
+			return nil, err
+		}
+		values[index] = value
+	}
+	return values, nil
+}
+
+func stringFunction(apply func(string) string) func(callExpr, []compiled) (compiled, error) {
+	return func(e callExpr, args []compiled) (compiled, error) {
+		if err := checkArgs(e, args, kindString); err != nil {
+			return compiled{}, err
+		}
+		field := args[0].field
+		if args[0].kind != kindString {
+			field = kindField(kindString)
+		}
+		return compiled{eval: func(row []any) (any, error) {
+			value, err := args[0].eval(row)
+			if value == nil || err != nil {
+				return nil, err
+			}
+			return apply(value.(string)), nil
+		}, kind: kindString, field: field}, nil
+	}
+}
+
+func lengthFunction(e callExpr, args []compiled) (compiled, error) {
+	if err := checkArgs(e, args, kindString); err != nil {
+		return compiled{}, err
+	}
+	return compiled{eval: func(row []any) (any, error) {
+		value, err := args[0].eval(row)
+		if value == nil || err != nil {
+			return nil, err
+		}
+		return int64(utf8.RuneCountInString(value.(string))), nil
+	}, kind: kindInt}, nil
+}
+
+// substrFunction compiles SUBSTR(text, start[, length]), where start is 1 for the first character.
+func substrFunction(e callExpr, args []compiled) (compiled, error) {
+	if err := checkArgs(e, args, kindString, kindInt, kindInt); err != nil {
+		return compiled{}, err
+	}
+	return compiled{eval: func(row []any) (any, error) {
+		values, err := evalArgs(args, row)
+		if values == nil || err != nil {
+			return nil, err
+		}
+		runes := []rune(values[0].(string))
+		start := max(values[1].(int64)-1, 0)
+		end := int64(len(runes))
+		if len(values) == 3 {
+			end = min(end, values[1].(int64)-1+max(values[2].(int64), 0))
+		}
+		if start >= end {
+			return ``, nil
+		}
+		return string(runes[start:end]), nil
+	}, kind: kindString, field: kindField(kindString)}, nil
+}
+
+func absFunction(e callExpr, args []compiled) (compiled, error) {
+	if err := checkArgs(e, args, kindFloat); err != nil {
+		return compiled{}, err
+	}
+	return compiled{eval: func(row []any) (any, error) {
+		value, err := args[0].eval(row)
+		if value == nil || err != nil {
+			return nil, err
+		}
+		if value, ok := value.(float64); ok {
+			return math.Abs(value), nil
+		}
+		if compareValues(value, int64(0)) < 0 {
+			return negate(value, e)
+		}
+		return value, nil
+	}, kind: args[0].kind, field: args[0].field}, nil
+}
+
+// roundFunction compiles ROUND(number[, digits]), which rounds halves away from zero.
+func roundFunction(e callExpr, args []compiled) (compiled, error) {
+	if err := checkArgs(e, args, kindFloat, kindInt); err != nil {
+		return compiled{}, err
+	}
+	return compiled{eval: func(row []any) (any, error) {
+		values, err := evalArgs(args, row)
+		if values == nil || err != nil {
+			return nil, err
+		}
+		digits := int64(0)
+		if len(values) == 2 {
+			digits = values[1].(int64)
+		}
+		switch value := values[0].(type) {
+		case int64:
+			return value, nil
+		case *big.Rat:
+			return roundRat(value, digits), nil
+		}
+		scale := math.Pow(10, float64(digits))
+		return math.Round(values[0].(float64)*scale) / scale, nil
+	}, kind: args[0].kind, field: roundField(args[0])}, nil
+}
+
+// roundField returns the field of the result of ROUND, which keeps the field of a decimal argument.
+func roundField(arg compiled) metafield.MetaInfoField {
+	if arg.kind == kindDecimal {
+		return arg.field
+	}
+	return kindField(arg.kind)
+}
+
+// roundRat rounds value to digits decimal places, or to a multiple of 10^-digits if digits is negative, rounding
+// halves away from zero.
+func roundRat(value *big.Rat, digits int64) *big.Rat {
+	if digits >= int64(ratScale(value)) {
+		return value
+	}
+	// beyond this many digits before the decimal point, every decimal rounds to zero
+	const maxDigits = 1000
+	shift := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(min(max(-digits, 0), maxDigits)), nil))
+	shifted := new(big.Rat).Quo(value, shift)
+	rounded, _ := new(big.Rat).SetString(shifted.FloatString(int(max(digits, 0))))
+	return rounded.Mul(rounded, shift)
+}
+
+func coalesceFunction(e callExpr, args []compiled) (compiled, error) {
+	result, err := combine(e, args)
+	if err != nil {
+		return compiled{}, err
+	}
+	result.eval = func(row []any) (any, error) {
+		for _, arg := range args {
+			value, err := arg.eval(row)
+			if value != nil || err != nil {
+				return convertResult(value, err)(result.kind)
+			}
+		}
+		return nil, nil
+	}
+	return result, nil
+}
+
+func timePartFunction(part func(time.Time) int64) func(callExpr, []compiled) (compiled, error) {
+	return func(e callExpr, args []compiled) (compiled, error) {
+		if err := checkArgs(e, args, kindTime); err != nil {
+			return compiled{}, err
+		}
+		return compiled{eval: func(row []any) (any, error) {
+			value, err := args[0].eval(row)
+			if value == nil || err != nil {
+				return nil, err
+			}
+			return part(value.(time.Time)), nil
+		}, kind: kindInt}, nil
+	}
+}
@@ -0,0 +1,579 @@
+package yxquery
+
+import (
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/internal/sqlscan"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keywords cannot be used as unquoted names or aliases.
+var keywords = map[string]bool{
+	`SELECT`: true, `DISTINCT`: true, `FROM`: true, `AS`: true, `JOIN`: true, `INNER`: true, `LEFT`: true,
+	`OUTER`: true, `CROSS`: true, `ON`: true, `USING`: true, `WHERE`: true, `GROUP`: true, `BY`: true, `HAVING`: true,
+	`ORDER`: true, `ASC`: true, `DESC`: true, `LIMIT`: true, `OFFSET`: true, `AND`: true, `OR`: true, `NOT`: true,
+	`IS`: true, `NULL`: true, `IN`: true, `BETWEEN`: true, `LIKE`: true, `TRUE`: true, `FALSE`: true, `CASE`: true,
+	`WHEN`: true, `THEN`: true, `ELSE`: true, `END`: true, `RIGHT`: true, `FULL`: true, `UNION`: true,
+}
+
+type parser struct {
+	tokens []sqlscan.Token
+	pos    int
+	args   []any
+	used   int
+}
+
+// parse parses a statement of the form
+//
+//	SELECT [DISTINCT] * | table.* | expression [[AS] alias], ...
+//	FROM table [[AS] alias]
+//	    [[INNER | LEFT [OUTER] | CROSS] JOIN table [[AS] alias] [ON condition | USING (column, ...)]] ...
+//	[WHERE condition] [GROUP BY expression, ...] [HAVING condition]
+//	[ORDER BY expression [ASC | DESC], ...] [LIMIT count [OFFSET skip]]
+//
+// replacing ? parameters with args.
+func parse(sql string, args []any) (*statement, error) {
+	tokens, err := sqlscan.Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, args: args}
+	s, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+	if p.used != len(args) {
+		return nil, fmt.Errorf(`the statement has %v parameters but %v arguments were given`, p.used, len(args))
+	}
+	return s, nil
+}
+
+func (p *parser) statement() (*statement, error) {
+	s := &statement{}
+	err := p.expect(`SELECT`)
+	if err != nil {
+		return nil, err
+	}
+	s.distinct = p.accept(`DISTINCT`)
+	for {
+		item, err := p.selectItem()
+		if err != nil {
+			return nil, err
+		}
+		s.items = append(s.items, item)
+		if !p.accept(`,`) {
+			break
+		}
+	}
+
+	err = p.expect(`FROM`)
+	if err != nil {
+		return nil, err
+	}
+	s.from, err = p.tableRef()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		join, ok, err := p.join()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		s.joins = append(s.joins, join)
+	}
+
+	if p.accept(`WHERE`) {
+		if s.where, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(`GROUP`) {
+		if s.groupBy, err = p.exprList(`BY`); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(`HAVING`) {
+		if s.having, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(`ORDER`) {
+		if err = p.expect(`BY`); err != nil {
+			return nil, err
+		}
+		for {
+			value, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			item := orderItem{expr: value, desc: p.accept(`DESC`)}
+			if !item.desc {
+				p.accept(`ASC`)
+			}
+			s.orderBy = append(s.orderBy, item)
+			if !p.accept(`,`) {
+				break
+			}
+		}
+	}
+	if p.accept(`LIMIT`) {
+		if s.limit, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if p.accept(`OFFSET`) {
+			if s.offset, err = p.expr(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p.accept(`;`)
+	if next := p.peek(); next.Kind != sqlscan.EOF {
+		return nil, p.unexpected(next)
+	}
+	return s, nil
+}
+
+func (p *parser) selectItem() (selectItem, error) {
+	if p.accept(`*`) {
+		return selectItem{star: true}, nil
+	}
+	// table.*
+	if next := p.peek(); p.isName(next) && p.tokens[p.pos+1].Is(`.`) && p.tokens[p.pos+2].Is(`*`) {
+		p.pos += 3
+		return selectItem{star: true, table: next.Text}, nil
+	}
+	value, err := p.expr()
+	if err != nil {
+		return selectItem{}, err
+	}
+	item := selectItem{expr: value}
+	item.alias, err = p.alias()
+	return item, err
+}
+
+// alias parses an optional [AS] alias.
+func (p *parser) alias() (string, error) {
+	if p.accept(`AS`) {
+		return p.name()
+	}
+	if p.isName(p.peek()) {
+		return p.next().Text, nil
+	}
+	return ``, nil
+}
+
+func (p *parser) tableRef() (tableRef, error) {
+	var table tableRef
+	next := p.next()
+	switch {
+	case next.Kind == sqlscan.String:
+		table.path = next.Text
+	case p.isName(next):
+		table.name = next.Text
+	default:
+		return table, fmt.Errorf(`expected a table but got %w`, p.unexpected(next))
+	}
+	var err error
+	table.alias, err = p.alias()
+	return table, err
+}
+
+// join parses a JOIN clause, or a comma followed by a table, which is a cross join. It returns false if the next
+// token does not start a join.
+func (p *parser) join() (joinClause, bool, error) {
+	var join joinClause
+	switch {
+	case p.accept(`,`):
+		join.kind = crossJoin
+	case p.accept(`CROSS`):
+		join.kind = crossJoin
+		if err := p.expect(`JOIN`); err != nil {
+			return join, false, err
+		}
+	case p.accept(`LEFT`):
+		join.kind = leftJoin
+		p.accept(`OUTER`)
+		if err := p.expect(`JOIN`); err != nil {
+			return join, false, err
+		}
+	case p.accept(`INNER`):
+		if err := p.expect(`JOIN`); err != nil {
+			return join, false, err
+		}
+	case p.accept(`JOIN`):
+	case p.peek().Is(`RIGHT`), p.peek().Is(`FULL`):
+		return join, false, fmt.Errorf(`%v joins are not supported; swap the tables and use a LEFT JOIN`, strings.ToUpper(p.peek().Text))
+	default:
+		return join, false, nil
+	}
+
+	var err error
+	join.table, err = p.tableRef()
+	if err != nil {
+		return join, false, err
+	}
+	if join.kind == crossJoin {
+		return join, true, nil
+	}
+	if p.accept(`USING`) {
+		if err = p.expect(`(`); err != nil {
+			return join, false, err
+		}
+		for {
+			name, err := p.name()
+			if err != nil {
+				return join, false, err
+			}
+			join.using = append(join.using, name)
+			if !p.accept(`,`) {
+				break
+			}
+		}
+		return join, true, p.expect(`)`)
+	}
+	if err = p.expect(`ON`); err != nil {
+		return join, false, err
+	}
+	join.on, err = p.expr()
+	return join, true, err
+}
+
+// exprList parses a comma separated list of expressions following the keyword.
+func (p *parser) exprList(keyword string) ([]expr, error) {
+	if err := p.expect(keyword); err != nil {
+		return nil, err
+	}
+	var list []expr
+	for {
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		if !p.accept(`,`) {
+			return list, nil
+		}
+	}
+}
+
+func (p *parser) expr() (expr, error) {
+	return p.or()
+}
+
+func (p *parser) or() (expr, error) {
+	left, err := p.and()
+	for err == nil && p.accept(`OR`) {
+		var right expr
+		right, err = p.and()
+		left = binaryExpr{op: `OR`, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) and() (expr, error) {
+	left, err := p.not()
+	for err == nil && p.accept(`AND`) {
+		var right expr
+		right, err = p.not()
+		left = binaryExpr{op: `AND`, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) not() (expr, error) {
+	if p.accept(`NOT`) {
+		operand, err := p.not()
+		return unaryExpr{op: `NOT`, operand: operand}, err
+	}
+	return p.predicate()
+}
+
+var comparisons = map[string]string{`=`: `=`, `<>`: `<>`, `!=`: `<>`, `<`: `<`, `<=`: `<=`, `>`: `>`, `>=`: `>=`}
+
+func (p *parser) predicate() (expr, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.Kind == sqlscan.Symbol && comparisons[next.Text] != `` {
+		p.pos++
+		right, err := p.additive()
+		return binaryExpr{op: comparisons[next.Text], left: left, right: right}, err
+	}
+	if p.accept(`IS`) {
+		negated := p.accept(`NOT`)
+		return isNullExpr{operand: left, not: negated}, p.expect(`NULL`)
+	}
+	negated := p.accept(`NOT`)
+	switch {
+	case p.accept(`IN`):
+		if err = p.expect(`(`); err != nil {
+			return nil, err
+		}
+		test := inExpr{operand: left, not: negated}
+		for {
+			value, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			test.list = append(test.list, value)
+			if !p.accept(`,`) {
+				break
+			}
+		}
+		return test, p.expect(`)`)
+	case p.accept(`BETWEEN`):
+		test := betweenExpr{operand: left, not: negated}
+		if test.low, err = p.additive(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(`AND`); err != nil {
+			return nil, err
+		}
+		test.high, err = p.additive()
+		return test, err
+	case p.accept(`LIKE`):
+		pattern, err := p.additive()
+		return likeExpr{operand: left, pattern: pattern, not: negated}, err
+	case negated:
+		return nil, fmt.Errorf(`expected IN, BETWEEN, or LIKE but got %w`, p.unexpected(p.peek()))
+	}
+	return left, nil
+}
+
+func (p *parser) additive() (expr, error) {
+	left, err := p.multiplicative()
+	for err == nil && (p.peek().Is(`+`) || p.peek().Is(`-`) || p.peek().Is(`||`)) {
+		op := p.next().Text
+		var right expr
+		right, err = p.multiplicative()
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) multiplicative() (expr, error) {
+	left, err := p.unary()
+	for err == nil && (p.peek().Is(`*`) || p.peek().Is(`/`) || p.peek().Is(`%`)) {
+		op := p.next().Text
+		var right expr
+		right, err = p.unary()
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (expr, error) {
+	if p.accept(`-`) {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		// negative numbers are literals, so that they can be used in LIMIT and pushed down to the reader
+		if number, ok := operand.(literalExpr); ok {
+			switch value := number.value.(type) {
+			case int64:
+				return literalExpr{value: -value}, nil
+			case float64:
+				return literalExpr{value: -value}, nil
+			}
+		}
+		return unaryExpr{op: `-`, operand: operand}, nil
+	}
+	if p.accept(`+`) {
+		return p.unary()
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (expr, error) {
+	next := p.next()
+	switch {
+	case next.Kind == sqlscan.Number:
+		return parseNumber(next)
+	case next.Kind == sqlscan.String:
+		return literalExpr{value: next.Text}, nil
+	case next.Is(`NULL`):
+		return literalExpr{}, nil
+	case next.Is(`TRUE`), next.Is(`FALSE`):
+		return literalExpr{value: next.Is(`TRUE`)}, nil
+	case next.Is(`?`):
+		return p.param(next)
+	case next.Is(`(`):
+		inner, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(`)`)
+	case next.Is(`CASE`):
+		return p.caseExpr()
+	case p.isName(next):
+		if next.Kind == sqlscan.Ident && p.accept(`(`) {
+			return p.call(strings.ToUpper(next.Text))
+		}
+		if p.accept(`.`) {
+			name, err := p.name()
+			return columnExpr{table: next.Text, name: name}, err
+		}
+		return columnExpr{name: next.Text}, nil
+	}
+	return nil, fmt.Errorf(`expected an expression but got %w`, p.unexpected(next))
+}
+
+// param returns the next argument as a literal. Integers are converted to int64, floats to float64, and decimals to
+// *big.Rat.
+func (p *parser) param(t sqlscan.Token) (expr, error) {
+	if p.used >= len(p.args) {
+		return nil, fmt.Errorf(`no argument was given for the ? at position %v`, t.Pos+1)
+	}
+	value := p.args[p.used]
+	p.used++
+	switch arg := value.(type) {
+	case nil, bool, int64, float64, string, time.Time, []byte:
+		return literalExpr{value: arg}, nil
+	case int:
+		return literalExpr{value: int64(arg)}, nil
+	case int8:
+		return literalExpr{value: int64(arg)}, nil
+	case int16:
+		return literalExpr{value: int64(arg)}, nil
+	case int32:
+		return literalExpr{value: int64(arg)}, nil
+	case uint8:
+		return literalExpr{value: int64(arg)}, nil
+	case uint16:
+		return literalExpr{value: int64(arg)}, nil
+	case uint32:
+		return literalExpr{value: int64(arg)}, nil
+	case float32:
+		return literalExpr{value: float64(arg)}, nil
+	case decimal.Decimal:
+		value, err := arg.Rat()
+		if err != nil {
+			return nil, fmt.Errorf(`argument %v: %w`, p.used, err)
+		}
+		return literalExpr{value: value}, nil
+	}
+	return nil, fmt.Errorf(`argument %v has unsupported type %T`, p.used, value)
+}
+
+func (p *parser) call(name string) (expr, error) {
+	call := callExpr{name: name}
+	if p.accept(`*`) {
+		call.star = true
+		return call, p.expect(`)`)
+	}
+	call.distinct = p.accept(`DISTINCT`)
+	if p.accept(`)`) {
+		return call, nil
+	}
+	for {
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+		if !p.accept(`,`) {
+			break
+		}
+	}
+	return call, p.expect(`)`)
+}
+
+func (p *parser) caseExpr() (expr, error) {
+	var c caseExpr
+	var err error
+	if !p.peek().Is(`WHEN`) {
+		if c.operand, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.accept(`WHEN`) {
+		var when whenClause
+		if when.when, err = p.expr(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(`THEN`); err != nil {
+			return nil, err
+		}
+		if when.then, err = p.expr(); err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, when)
+	}
+	if len(c.whens) == 0 {
+		return nil, fmt.Errorf(`expected WHEN but got %w`, p.unexpected(p.peek()))
+	}
+	if p.accept(`ELSE`) {
+		if c.orElse, err = p.expr(); err != nil {
+			return nil, err
+		}
+	}
+	return c, p.expect(`END`)
+}
+
+// parseNumber parses integers as int64 and other numbers as float64.
+func parseNumber(t sqlscan.Token) (expr, error) {
+	if integer, err := strconv.ParseInt(t.Text, 10, 64); err == nil {
+		return literalExpr{value: integer}, nil
+	}
+	number, err := strconv.ParseFloat(t.Text, 64)
+	if err != nil {
+		return nil, fmt.Errorf(`invalid number '%v' at position %v`, t.Text, t.Pos+1)
+	}
+	return literalExpr{value: number}, nil
+}
+
+func (p *parser) peek() sqlscan.Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() sqlscan.Token {
+	next := p.tokens[p.pos]
+	if next.Kind != sqlscan.EOF {
+		p.pos++
+	}
+	return next
+}
+
+// accept consumes the next token if it is the keyword or symbol text.
+func (p *parser) accept(text string) bool {
+	if p.peek().Is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return fmt.Errorf(`expected %v but got %w`, text, p.unexpected(p.peek()))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(t sqlscan.Token) error {
+	if t.Kind == sqlscan.EOF {
+		return fmt.Errorf(`unexpected end of statement`)
+	}
+	return fmt.Errorf(`unexpected '%v' at position %v`, t.Text, t.Pos+1)
+}
+
+// isName reports whether the token is a quoted name or an unquoted name that is not a keyword.
+func (p *parser) isName(t sqlscan.Token) bool {
+	return t.Kind == sqlscan.QuotedIdent || (t.Kind == sqlscan.Ident && !keywords[strings.ToUpper(t.Text)])
+}
+
+func (p *parser) name() (string, error) {
+	next := p.peek()
+	if !p.isName(next) {
+		return ``, fmt.Errorf(`expected a name but got %w`, p.unexpected(next))
+	}
+	p.pos++
+	return next.Text, nil
+}
@@ -0,0 +1,729 @@
+package yxquery
+
+import (
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"path/filepath"
+	"strings"
+)
+
+// plan is the operator producing the rows of a query and the fields of its rows.
+type plan struct {
+	root   operator
+	fields []metafield.MetaInfoField
+}
+
+// output is a column of the select list, or a column added to it for ORDER BY.
+type output struct {
+	name  string
+	value compiled
+
+	// key identifies the expression of the column for matching ORDER BY expressions.
+	key string
+}
+
+type planner struct {
+	options *options
+}
+
+// newPlan builds the operators of a statement. The plan reads the files of the statement as they are pulled through
+// it: the WHERE conditions that compare a field with a literal are evaluated by the readers, the others are evaluated
+// as the rows are joined, and the fields that the statement does not refer to are not read.
+func newPlan(s *statement, o *options) (*plan, error) {
+	p := &planner{options: o}
+	input, inputSchema, err := p.from(s)
+	if err != nil {
+		return nil, err
+	}
+	input, err = p.where(s, input, inputSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	selectCompiler := &compiler{schema: inputSchema, clause: `SELECT`}
+	if isAggregate(s) {
+		input, selectCompiler, err = p.aggregate(s, input, inputSchema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outputs, err := p.selectList(s, inputSchema, selectCompiler)
+	if err != nil {
+		return nil, err
+	}
+	width := len(outputs)
+	keys, outputs, err := p.orderBy(s, outputs, inputSchema, selectCompiler)
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := make([]compiled, len(outputs))
+	for index, out := range outputs {
+		exprs[index] = out.value
+	}
+	var root operator = &project{input: input, exprs: exprs}
+	if s.distinct {
+		root = &distinct{input: root}
+	}
+	if len(keys) > 0 {
+		root = &sorter{input: root, keys: keys, memory: o.sortMemory, tempDir: o.tempDir}
+	}
+	root, err = p.limit(s, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(outputs) > width {
+		root = &trim{input: root, width: width}
+	}
+	return &plan{root: root, fields: outputFields(outputs[:width])}, nil
+}
+
+// from builds the scans and joins of the FROM clause. Joins are evaluated from left to right, each joining the rows
+// of the tables before it with the records of its table.
+func (p *planner) from(s *statement) (operator, *schema, error) {
+	var input operator
+	input, inputSchema, table, err := p.table(s.from)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables := []string{table}
+	for _, clause := range s.joins {
+		right, rightSchema, table, err := p.table(clause.table)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, existing := range tables {
+			if strings.EqualFold(existing, table) {
+				return nil, nil, fmt.Errorf(`table '%v' appears more than once; give it an alias`, table)
+			}
+		}
+		tables = append(tables, table)
+		if clause.kind == leftJoin {
+			right.nullable = true
+		}
+		joined := &schema{columns: append(append([]column{}, inputSchema.columns...), rightSchema.columns...)}
+		j := &join{kind: clause.kind, left: input, right: right, rightWidth: len(rightSchema.columns)}
+		if clause.using != nil {
+			err = p.using(j, clause.using, inputSchema, rightSchema, joined)
+		} else if clause.on != nil {
+			err = p.on(j, clause.on, inputSchema, rightSchema, joined)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		input, inputSchema = j, joined
+	}
+	return input, inputSchema, nil
+}
+
+// table creates the scan of a table of the FROM clause and returns the name that qualifies its columns: its alias, the
+// name it was registered with, or the name of its file without the extension.
+func (p *planner) table(ref tableRef) (*scan, *schema, string, error) {
+	path, qualifier := ref.path, ref.name
+	if path == `` {
+		var ok bool
+		path, ok = p.options.tables[ref.name]
+		if !ok {
+			for name, registered := range p.options.tables {
+				if strings.EqualFold(name, ref.name) {
+					path, ok = registered, true
+					break
+				}
+			}
+		}
+		if !ok {
+			return nil, nil, ``, fmt.Errorf(`table '%v' is not registered; quote file paths with single quotes`, ref.name)
+		}
+	} else {
+		qualifier = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if ref.alias != `` {
+		qualifier = ref.alias
+	}
+
+	reader, err := yx.ReadFile(path)
+	if err != nil {
+		return nil, nil, ``, err
+	}
+	fields := reader.FieldInfo()
+	err = reader.Close()
+	if err != nil {
+		return nil, nil, ``, err
+	}
+	source := &scan{path: path, fields: fields, used: make([]bool, len(fields))}
+	s := &schema{columns: make([]column, len(fields))}
+	for index, field := range fields {
+		s.columns[index] = column{table: qualifier, name: field.Name, field: field, source: source, index: index}
+	}
+	return source, s, qualifier, nil
+}
+
+// using adds the keys of a USING join. The columns of the right table that are joined on are hidden, so that
+// unqualified references to them refer to the columns of the left table.
+func (p *planner) using(j *join, names []string, left, right, joined *schema) error {
+	leftCompiler := &compiler{schema: left, clause: `USING`}
+	rightCompiler := &compiler{schema: right, clause: `USING`}
+	for _, name := range names {
+		leftIndex, err := left.resolve(columnExpr{name: name})
+		if err != nil {
+			return err
+		}
+		rightIndex, err := right.resolve(columnExpr{name: name})
+		if err != nil {
+			return err
+		}
+		err = p.addKey(j, leftCompiler.column(leftIndex), rightCompiler.column(rightIndex), name)
+		if err != nil {
+			return err
+		}
+		joined.columns[len(left.columns)+rightIndex].hidden = true
+	}
+	return nil
+}
+
+// on adds the conditions of an ON join. Equalities between an expression of the left tables and an expression of the
+// right table become the keys of the join, and the other conditions are evaluated for each pair of matching rows.
+func (p *planner) on(j *join, condition expr, left, right, joined *schema) error {
+	for _, conjunct := range conjuncts(condition) {
+		if equality, ok := conjunct.(binaryExpr); ok && equality.op == `=` {
+			leftKey, rightKey, ok := p.equiKey(equality, left, right)
+			if ok {
+				err := p.addKey(j, leftKey, rightKey, conjunct)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		residual, err := compileCondition(&compiler{schema: joined, clause: `ON`}, conjunct)
+		if err != nil {
+			return err
+		}
+		j.residual = append(j.residual, residual)
+	}
+	return nil
+}
+
+// equiKey compiles the sides of an equality, returning false unless one side refers only to the left schema and the
+// other only to the right schema.
+func (p *planner) equiKey(e binaryExpr, left, right *schema) (compiled, compiled, bool) {
+	for _, sides := range [][2]expr{{e.left, e.right}, {e.right, e.left}} {
+		leftKey, err := (&compiler{schema: left, clause: `ON`}).compile(sides[0])
+		if err != nil || leftKey.constant {
+			continue
+		}
+		rightKey, err := (&compiler{schema: right, clause: `ON`}).compile(sides[1])
+		if err != nil || rightKey.constant {
+			continue
+		}
+		return leftKey, rightKey, true
+	}
+	return compiled{}, compiled{}, false
+}
+
+func (p *planner) addKey(j *join, left, right compiled, on any) error {
+	k, ok := commonKind(left.kind, right.kind)
+	if !ok {
+		return fmt.Errorf(`cannot join %v with %v on %v: %w`, left.kind, right.kind, on, yx.ErrTypeMismatch)
+	}
+	j.leftKeys = append(j.leftKeys, left)
+	j.rightKeys = append(j.rightKeys, right)
+	j.keyKinds = append(j.keyKinds, k)
+	return nil
+}
+
+// where filters the rows with the WHERE clause. Conditions on a single field that the reader of its table can
+// evaluate are passed to the reader, except for tables on the right of a LEFT JOIN.
+func (p *planner) where(s *statement, input operator, inputSchema *schema) (operator, error) {
+	if s.where == nil {
+		return input, nil
+	}
+	c := &compiler{schema: inputSchema, clause: `WHERE`}
+	var conditions []compiled
+	for _, conjunct := range conjuncts(s.where) {
+		if pushDown(conjunct, inputSchema) {
+			continue
+		}
+		condition, err := compileCondition(c, conjunct)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	if len(conditions) == 0 {
+		return input, nil
+	}
+	return &filter{input: input, conditions: conditions}, nil
+}
+
+// conjuncts splits a condition into the conditions joined by AND.
+func conjuncts(e expr) []expr {
+	if and, ok := e.(binaryExpr); ok && and.op == `AND` {
+		return append(conjuncts(and.left), conjuncts(and.right)...)
+	}
+	return []expr{e}
+}
+
+func compileCondition(c *compiler, e expr) (compiled, error) {
+	condition, err := c.compile(e)
+	if err != nil {
+		return compiled{}, err
+	}
+	if condition.kind != kindBool && condition.kind != kindNull {
+		return compiled{}, fmt.Errorf(`%v requires a boolean condition but %v is %v`, c.clause, e, condition.kind)
+	}
+	return condition, nil
+}
+
+var pushedOperators = map[string]yx.Operator{
+	`=`:  yx.Equal,
+	`<>`: yx.NotEqual,
+	`<`:  yx.Less,
+	`<=`: yx.LessOrEqual,
+	`>`:  yx.Greater,
+	`>=`: yx.GreaterOrEqual,
+}
+
+// flipped holds the operators that give the same result with their operands swapped.
+var flipped = map[yx.Operator]yx.Operator{
+	yx.Equal:          yx.Equal,
+	yx.NotEqual:       yx.NotEqual,
+	yx.Less:           yx.Greater,
+	yx.LessOrEqual:    yx.GreaterOrEqual,
+	yx.Greater:        yx.Less,
+	yx.GreaterOrEqual: yx.LessOrEqual,
+}
+
+// pushDown passes a condition to the scan of the field it tests, returning false if the condition is not a comparison
+// of a field with literals that the reader can evaluate.
+func pushDown(e expr, s *schema) bool {
+	var ref expr
+	var predicate func(name string, field metafield.MetaInfoField) (yx.Predicate, bool)
+	switch e := e.(type) {
+	case binaryExpr:
+		op, ok := pushedOperators[e.op]
+		if !ok {
+			return false
+		}
+		ref = e.left
+		value := e.right
+		if _, isLiteral := e.left.(literalExpr); isLiteral {
+			ref, value, op = e.right, e.left, flipped[op]
+		}
+		predicate = func(name string, field metafield.MetaInfoField) (yx.Predicate, bool) {
+			compareValue, ok := predicateValue(field, value)
+			return yx.Compare(name, op, compareValue), ok
+		}
+	case isNullExpr:
+		ref = e.operand
+		predicate = func(name string, _ metafield.MetaInfoField) (yx.Predicate, bool) {
+			if e.not {
+				return yx.Not(yx.IsNull(name)), true
+			}
+			return yx.IsNull(name), true
+		}
+	case inExpr:
+		if e.not {
+			return false
+		}
+		ref = e.operand
+		predicate = func(name string, field metafield.MetaInfoField) (yx.Predicate, bool) {
+			predicates := make([]yx.Predicate, len(e.list))
+			for index, item := range e.list {
+				value, ok := predicateValue(field, item)
+				if !ok {
+					return yx.Predicate{}, false
+				}
+				predicates[index] = yx.Compare(name, yx.Equal, value)
+			}
+			return yx.Or(predicates...), true
+		}
+	case betweenExpr:
+		if e.not {
+			return false
+		}
+		ref = e.operand
+		predicate = func(name string, field metafield.MetaInfoField) (yx.Predicate, bool) {
+			low, lowOk := predicateValue(field, e.low)
+			high, highOk := predicateValue(field, e.high)
+			return yx.And(yx.Compare(name, yx.GreaterOrEqual, low), yx.Compare(name, yx.LessOrEqual, high)), lowOk && highOk
+		}
+	default:
+		return false
+	}
+	return pushPredicate(s, ref, predicate)
+}
+
+// pushPredicate adds the predicate built for the field that ref refers to to its scan.
+func pushPredicate(s *schema, ref expr, predicate func(name string, field metafield.MetaInfoField) (yx.Predicate, bool)) bool {
+	columnRef, ok := ref.(columnExpr)
+	if !ok {
+		return false
+	}
+	index, err := s.resolve(columnRef)
+	if err != nil {
+		return false
+	}
+	col := s.columns[index]
+	if col.source == nil || col.source.nullable {
+		return false
+	}
+	pushed, ok := predicate(col.field.Name, col.field)
+	if !ok {
+		return false
+	}
+	col.source.predicates = append(col.source.predicates, pushed)
+	return true
+}
+
+// predicateValue returns the value of a literal for comparison with field by a yx.Predicate. It returns false if the
+// literal is null or does not have a kind that the predicate compares the same way as the query would.
+func predicateValue(field metafield.MetaInfoField, e expr) (any, bool) {
+	literal, ok := e.(literalExpr)
+	if !ok || literal.value == nil {
+		return nil, false
+	}
+	valueKind := valueKind(literal.value)
+	switch fieldKind(field) {
+	case kindInt, kindFloat:
+		// the reader compares decimals as floats, so they are compared exactly by the query instead
+		return literal.value, valueKind == kindInt || valueKind == kindFloat
+	case kindTime:
+		if text, ok := literal.value.(string); ok {
+			moment, ok := parseTime(text)
+			return moment, ok
+		}
+		return literal.value, valueKind == kindTime
+	case kindString:
+		return literal.value, valueKind == kindString
+	case kindBool:
+		return literal.value, valueKind == kindBool
+	}
+	return nil, false
+}
+
+// isAggregate reports whether a statement groups its rows.
+func isAggregate(s *statement) bool {
+	if len(s.groupBy) > 0 || s.having != nil {
+		return true
+	}
+	found := false
+	check := func(e expr) bool {
+		if call, ok := e.(callExpr); ok && aggregates[call.name] {
+			found = true
+		}
+		return !found
+	}
+	for _, item := range s.items {
+		walk(item.expr, check)
+	}
+	for _, item := range s.orderBy {
+		walk(item.expr, check)
+	}
+	return found
+}
+
+// aggregate groups the rows by the GROUP BY expressions and computes the aggregate functions of the statement. It
+// returns the compiler for the expressions evaluated after grouping, which can refer only to the GROUP BY expressions
+// and the aggregate functions.
+func (p *planner) aggregate(s *statement, input operator, inputSchema *schema) (operator, *compiler, error) {
+	op := &aggregate{input: input}
+	grouped := &schema{}
+	computed := map[string]int{}
+
+	groupCompiler := &compiler{schema: inputSchema, clause: `GROUP BY`}
+	for _, e := range s.groupBy {
+		e, err := groupExpr(s, e, inputSchema)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := groupCompiler.compile(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := exprKey(e, inputSchema)
+		if _, ok := computed[key]; ok {
+			continue
+		}
+		computed[key] = len(grouped.columns)
+		op.groups = append(op.groups, value)
+		grouped.columns = append(grouped.columns, column{name: defaultName(e), field: value.field})
+	}
+
+	var calls []callExpr
+	collect := func(e expr) bool {
+		if call, ok := e.(callExpr); ok && aggregates[call.name] {
+			calls = append(calls, call)
+			return false
+		}
+		return true
+	}
+	for _, item := range s.items {
+		walk(item.expr, collect)
+	}
+	walk(s.having, collect)
+	for _, item := range s.orderBy {
+		walk(item.expr, collect)
+	}
+	// the columns of the aggregates follow the columns of the groups in the rows of the aggregate operator
+	var aggregateColumns []column
+	for _, call := range calls {
+		key := exprKey(call, inputSchema)
+		if _, ok := computed[key]; ok {
+			continue
+		}
+		aggregateCall, field, err := compileAggregate(call, inputSchema)
+		if err != nil {
+			return nil, nil, err
+		}
+		computed[key] = len(op.groups) + len(op.aggregates)
+		op.aggregates = append(op.aggregates, aggregateCall)
+		aggregateColumns = append(aggregateColumns, column{name: defaultName(call), field: field})
+	}
+	grouped.columns = append(grouped.columns, aggregateColumns...)
+
+	var result operator = op
+	if s.having != nil {
+		having, err := compileCondition(&compiler{schema: grouped, clause: `HAVING`, computed: computed, input: inputSchema}, s.having)
+		if err != nil {
+			return nil, nil, err
+		}
+		result = &filter{input: result, conditions: []compiled{having}}
+	}
+	return result, &compiler{schema: grouped, clause: `SELECT`, computed: computed, input: inputSchema}, nil
+}
+
+// groupExpr returns the expression of a GROUP BY item, which may also be the position or alias of an item of the
+// select list. The names of input columns take precedence over aliases.
+func groupExpr(s *statement, e expr, inputSchema *schema) (expr, error) {
+	switch e := e.(type) {
+	case literalExpr:
+		position, ok := e.value.(int64)
+		if !ok {
+			return e, nil
+		}
+		if position < 1 || position > int64(len(s.items)) || s.items[position-1].star {
+			return nil, fmt.Errorf(`GROUP BY position %v is not in the select list`, position)
+		}
+		return s.items[position-1].expr, nil
+	case columnExpr:
+		if _, err := inputSchema.resolve(e); err == nil || e.table != `` {
+			return e, nil
+		}
+		for _, item := range s.items {
+			if item.alias != `` && strings.EqualFold(item.alias, e.name) {
+				return item.expr, nil
+			}
+		}
+	}
+	return e, nil
+}
+
+// compileAggregate compiles the argument of an aggregate function and returns the field of its result. SUM returns
+// integers for integer arguments and decimals for decimal arguments, and AVG always returns a float.
+func compileAggregate(call callExpr, inputSchema *schema) (aggregateCall, metafield.MetaInfoField, error) {
+	result := aggregateCall{name: call.name, distinct: call.distinct}
+	if call.star {
+		if call.name != `COUNT` || call.distinct {
+			return result, metafield.MetaInfoField{}, fmt.Errorf(`%v(*) is not supported; only COUNT(*) is`, call.name)
+		}
+		result.kind = kindInt
+		return result, kindField(kindInt), nil
+	}
+	if len(call.args) != 1 {
+		return result, metafield.MetaInfoField{}, fmt.Errorf(`%v takes 1 argument but got %v`, call.name, len(call.args))
+	}
+	arg, err := (&compiler{schema: inputSchema, clause: `the argument of ` + call.name}).compile(call.args[0])
+	if err != nil {
+		return result, metafield.MetaInfoField{}, err
+	}
+	result.arg = &arg
+	switch call.name {
+	case `COUNT`:
+		result.kind = kindInt
+		return result, kindField(kindInt), nil
+	case `SUM`, `AVG`:
+		if !arg.kind.numeric() && arg.kind != kindNull {
+			return result, metafield.MetaInfoField{}, fmt.Errorf(`%v requires a number but %v is %v: %w`, call.name, call.args[0], arg.kind, yx.ErrTypeMismatch)
+		}
+		result.kind = kindFloat
+		if call.name == `SUM` && (arg.kind == kindInt || arg.kind == kindDecimal) {
+			result.kind = arg.kind
+		}
+		if result.kind == kindDecimal {
+			return result, decimalField(arg.field.Size, arg.field.Scale), nil
+		}
+		return result, kindField(result.kind), nil
+	default:
+		result.kind = arg.kind
+		return result, arg.field, nil
+	}
+}
+
+// aggregateNames holds the prefixes of the default names of aggregate functions, which follow the names given by the
+// Summarize tool of Alteryx.
+var aggregateNames = map[string]string{`COUNT`: `Count`, `SUM`: `Sum`, `AVG`: `Avg`, `MIN`: `Min`, `MAX`: `Max`}
+
+// defaultName returns the name of the output field of an expression without an alias: the name of the field for
+// field references, names such as Sum_Amount for aggregates of a field, and the text of the expression otherwise.
+func defaultName(e expr) string {
+	switch e := e.(type) {
+	case columnExpr:
+		return e.name
+	case callExpr:
+		if !aggregates[e.name] {
+			break
+		}
+		if e.star {
+			return `Count`
+		}
+		if ref, ok := e.args[0].(columnExpr); ok && len(e.args) == 1 {
+			prefix := aggregateNames[e.name]
+			if e.distinct {
+				prefix += `Distinct`
+			}
+			return prefix + `_` + ref.name
+		}
+	}
+	return e.String()
+}
+
+// selectList compiles the select list, expanding * and table.* to the visible columns of the input.
+func (p *planner) selectList(s *statement, inputSchema *schema, c *compiler) ([]output, error) {
+	var outputs []output
+	for _, item := range s.items {
+		if !item.star {
+			value, err := c.compile(item.expr)
+			if err != nil {
+				return nil, err
+			}
+			name := item.alias
+			if name == `` {
+				name = defaultName(item.expr)
+			}
+			outputs = append(outputs, output{name: name, value: value, key: exprKey(item.expr, inputSchema)})
+			continue
+		}
+		if c.computed != nil {
+			return nil, fmt.Errorf(`* cannot be used with GROUP BY or aggregate functions`)
+		}
+		found := false
+		for index, col := range inputSchema.columns {
+			if item.table == `` && col.hidden || item.table != `` && !strings.EqualFold(item.table, col.table) {
+				continue
+			}
+			found = true
+			outputs = append(outputs, output{name: col.name, value: c.column(index), key: fmt.Sprintf(`#%d`, index)})
+		}
+		if !found && item.table != `` {
+			return nil, fmt.Errorf(`table '%v' does not exist in the FROM clause`, item.table)
+		}
+	}
+	used := map[string]bool{}
+	for index := range outputs {
+		name := outputs[index].name
+		for suffix := 2; used[strings.ToLower(name)]; suffix++ {
+			name = fmt.Sprintf(`%v_%v`, outputs[index].name, suffix)
+		}
+		used[strings.ToLower(name)] = true
+		outputs[index].name = name
+	}
+	return outputs, nil
+}
+
+// orderBy returns the sort keys of the ORDER BY clause. An item may be the position or name of an output column, an
+// expression of the select list, or another expression, which is added to the outputs and removed after sorting.
+func (p *planner) orderBy(s *statement, outputs []output, inputSchema *schema, c *compiler) ([]sortKey, []output, error) {
+	width := len(outputs)
+	var keys []sortKey
+	for _, item := range s.orderBy {
+		index, err := orderIndex(item.expr, outputs[:width], inputSchema)
+		if err != nil {
+			return nil, nil, err
+		}
+		if index < 0 {
+			if s.distinct {
+				return nil, nil, fmt.Errorf(`ORDER BY %v must be in the select list of SELECT DISTINCT`, item.expr)
+			}
+			value, err := (&compiler{schema: c.schema, clause: `ORDER BY`, computed: c.computed, input: c.input}).compile(item.expr)
+			if err != nil {
+				return nil, nil, err
+			}
+			index = len(outputs)
+			outputs = append(outputs, output{name: item.expr.String(), value: value})
+		}
+		keys = append(keys, sortKey{index: index, desc: item.desc})
+	}
+	return keys, outputs, nil
+}
+
+// orderIndex returns the index of the output column an ORDER BY item refers to, or -1 if it is not an output column.
+func orderIndex(e expr, outputs []output, inputSchema *schema) (int, error) {
+	if literal, ok := e.(literalExpr); ok {
+		position, ok := literal.value.(int64)
+		if !ok {
+			return -1, nil
+		}
+		if position < 1 || position > int64(len(outputs)) {
+			return 0, fmt.Errorf(`ORDER BY position %v is not in the select list`, position)
+		}
+		return int(position - 1), nil
+	}
+	if ref, ok := e.(columnExpr); ok && ref.table == `` {
+		for _, equal := range []func(string, string) bool{func(a, b string) bool { return a == b }, strings.EqualFold} {
+			for index, out := range outputs {
+				if equal(out.name, ref.name) {
+					return index, nil
+				}
+			}
+		}
+	}
+	key := exprKey(e, inputSchema)
+	for index, out := range outputs {
+		if out.key == key {
+			return index, nil
+		}
+	}
+	return -1, nil
+}
+
+// limit applies the LIMIT and OFFSET clauses, whose values must be constants.
+func (p *planner) limit(s *statement, input operator) (operator, error) {
+	if s.limit == nil && s.offset == nil {
+		return input, nil
+	}
+	result := &limit{input: input, count: -1}
+	for _, clause := range []struct {
+		name  string
+		value expr
+		dst   *int64
+	}{{`LIMIT`, s.limit, &result.count}, {`OFFSET`, s.offset, &result.offset}} {
+		if clause.value == nil {
+			continue
+		}
+		value, err := (&compiler{schema: &schema{}, clause: clause.name}).compile(clause.value)
+		if err != nil {
+			return nil, err
+		}
+		*clause.dst, err = limitValue(clause.name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func outputFields(outputs []output) []metafield.MetaInfoField {
+	fields := make([]metafield.MetaInfoField, len(outputs))
+	for index, out := range outputs {
+		fields[index] = metafield.MetaInfoField{
+			Name:  out.name,
+			Type:  out.value.field.Type,
+			Size:  out.value.field.Size,
+			Scale: out.value.field.Scale,
+		}
+	}
+	return fields
+}
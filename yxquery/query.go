@@ -0,0 +1,335 @@
+// Package yxquery runs SQL queries over one or more .yxdb files.
+//
+// Tables are the paths of .yxdb files in single quotes, or names registered with WithTable:
+//
+//	rows, err := yxquery.Query(`SELECT State, SUM(Amount) FROM 'sales.yxdb' JOIN 'regions.yxdb' USING (RegionId)
+//		GROUP BY State ORDER BY 2 DESC`)
+//
+// Queries have the form
+//
+//	SELECT [DISTINCT] * | table.* | expression [[AS] alias], ...
+//	FROM table [[AS] alias]
+//	    [[INNER | LEFT [OUTER] | CROSS] JOIN table [[AS] alias] [ON condition | USING (field, ...)]] ...
+//	[WHERE condition]
+//	[GROUP BY expression, ...] [HAVING condition]
+//	[ORDER BY expression [ASC | DESC], ...]
+//	[LIMIT count [OFFSET skip]]
+//
+// Expressions combine fields, literals, and ? parameters with arithmetic (+, -, *, /, %), string concatenation (||),
+// comparisons, IS [NOT] NULL, [NOT] IN, [NOT] BETWEEN, [NOT] LIKE, AND, OR, NOT, CASE, the scalar functions UPPER,
+// LOWER, TRIM, LENGTH, SUBSTR, ABS, ROUND, COALESCE, YEAR, MONTH, and DAY, and the aggregate functions COUNT, SUM,
+// AVG, MIN, and MAX, which accept DISTINCT. Fields are qualified with the alias of their table, or the name of their
+// file without the extension. Names containing spaces or keywords are quoted with double quotes, brackets, or
+// backticks. GROUP BY and ORDER BY accept the positions of items of the select list, and ORDER BY their aliases. Nulls
+// follow the rules of SQL, and sort before other values.
+//
+// FixedDecimal values are exact: comparisons, SUM, and addition, subtraction, and multiplication with integers and
+// other decimals keep every digit. Division, AVG, and arithmetic with floats give floats. Integer arithmetic and SUM
+// fail with an error instead of overflowing an int64.
+//
+// Queries are evaluated as their rows are read. Only the fields a query refers to are read, and WHERE conditions that
+// compare a field with a literal are evaluated as yxdb.Predicate values, so records that do not match are not decoded.
+// The right side of each join and the groups of GROUP BY and DISTINCT are held in memory. ORDER BY sorts in memory up
+// to the limit set by WithSortMemory and spills sorted runs to temporary files beyond it.
+//
+// Rows can be read value by value, as batches in the format of yxdb.Reader.NextBatch, copied to the Writer of any of
+// the converter packages, or written to a new .yxdb file.
+package yxquery
+
+import (
+	"errors"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"io"
+	"math/big"
+	"time"
+)
+
+// DefaultSortMemory is the number of bytes of rows that ORDER BY sorts in memory before spilling them to disk.
+const DefaultSortMemory = 64 << 20
+
+const batchSize = 4096
+
+type options struct {
+	tables     map[string]string
+	args       []any
+	tempDir    string
+	sortMemory int64
+}
+
+// An Option configures a query.
+type Option func(*options)
+
+// WithTable registers the .yxdb file at path as a table that queries can refer to by name.
+func WithTable(name string, path string) Option {
+	return func(o *options) {
+		o.tables[name] = path
+	}
+}
+
+// WithArgs sets the values of the ? parameters of the query, in order. Values may be nil, bool, any Go integer or
+// float, decimal.Decimal, string, time.Time, or []byte.
+func WithArgs(args ...any) Option {
+	return func(o *options) {
+		o.args = args
+	}
+}
+
+// WithTempDir sets the directory for the temporary files of ORDER BY. The default is os.TempDir.
+func WithTempDir(dir string) Option {
+	return func(o *options) {
+		o.tempDir = dir
+	}
+}
+
+// WithSortMemory sets the number of bytes of rows that ORDER BY sorts in memory before spilling them to disk. The
+// default is DefaultSortMemory.
+func WithSortMemory(bytes int64) Option {
+	return func(o *options) {
+		o.sortMemory = bytes
+	}
+}
+
+// BatchWriter writes batches of records. The Writer types of the arrowconv, csvconv, jsonlconv, and parquetconv
+// packages are BatchWriters.
+type BatchWriter interface {
+	Write(batch *yx.Batch) error
+	Close() error
+}
+
+// Rows is the result of a query. Call Next to advance through the rows and Close when done.
+type Rows struct {
+	root   operator
+	fields []metafield.MetaInfoField
+	list   []yxrecord.YxdbField
+	row    []any
+	err    error
+	closed bool
+}
+
+// Query parses and plans a query and starts running it. Errors in the query, such as references to fields that do
+// not exist or comparisons of values that cannot be compared, are returned by Query and wrap yxdb.ErrFieldNotFound or
+// yxdb.ErrTypeMismatch where appropriate.
+func Query(sql string, opts ...Option) (*Rows, error) {
+	o := &options{tables: map[string]string{}, sortMemory: DefaultSortMemory}
+	for _, opt := range opts {
+		opt(o)
+	}
+	s, err := parse(sql, o.args)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newPlan(s, o)
+	if err != nil {
+		return nil, err
+	}
+	record, err := yxrecord.FromFieldList(p.fields)
+	if err != nil {
+		return nil, err
+	}
+	err = p.root.open()
+	if err != nil {
+		// operators may have opened files or written sorted runs before failing
+		_ = p.root.close()
+		return nil, err
+	}
+	return &Rows{root: p.root, fields: p.fields, list: record.Fields}, nil
+}
+
+// Fields returns the fields of the rows. Fields that are read from a file keep the type, size, and scale of the file;
+// computed fields are Bool, Int64, Double, FixedDecimal, DateTime, Blob, or V_WString.
+func (r *Rows) Fields() []metafield.MetaInfoField {
+	return r.fields
+}
+
+// ListFields returns the fields of the rows and their data types.
+func (r *Rows) ListFields() []yxrecord.YxdbField {
+	return r.list
+}
+
+// Next advances to the next row, returning false when there are no more rows or an error occurred. Call Err to
+// distinguish between the two.
+func (r *Rows) Next() bool {
+	if r.closed {
+		return false
+	}
+	row, err := r.root.next()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			r.err = err
+		}
+		closeErr := r.Close()
+		if r.err == nil {
+			r.err = closeErr
+		}
+		return false
+	}
+	r.row = row
+	return true
+}
+
+// Err returns the error that ended iteration, or nil if every row was read.
+func (r *Rows) Err() error {
+	return r.err
+}
+
+// Values returns the values of the current row, with the types returned by yxdb.Reader.ReadValueWithIndex: nil for
+// null, byte for Byte fields, int64 for other integers, float64 for Float and Double fields, decimal.Decimal for
+// FixedDecimal fields, string, bool, time.Time, or []byte.
+func (r *Rows) Values() []any {
+	values := make([]any, len(r.row))
+	for index, value := range r.row {
+		if value == nil {
+			continue
+		}
+		field := r.list[index]
+		switch {
+		case field.Type == yxrecord.Byte:
+			values[index] = byte(value.(int64))
+		case field.FieldType == yxrecord.TypeFixedDecimal:
+			values[index] = toDecimal(value.(*big.Rat), field.Scale)
+		default:
+			values[index] = value
+		}
+	}
+	return values
+}
+
+func toDecimal(value *big.Rat, scale int) decimal.Decimal {
+	return decimal.Decimal{Text: value.FloatString(scale), Scale: scale}
+}
+
+// Close stops the query and removes its temporary files. It is called by Next after the last row.
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.row = nil
+	return r.root.close()
+}
+
+// NextBatch returns the next n rows, or fewer at the end of the rows, as a batch in the format of
+// yxdb.Reader.NextBatch. It returns io.EOF when there are no more rows.
+func (r *Rows) NextBatch(n int) (*yx.Batch, error) {
+	if n < 1 {
+		n = 1
+	}
+	batch := &yx.Batch{Columns: make([]yx.Column, len(r.list))}
+	for index, field := range r.list {
+		batch.Columns[index] = yx.Column{Field: field, Nulls: make([]uint64, (n+63)/64)}
+	}
+	for batch.Len < n && r.Next() {
+		for index, value := range r.row {
+			appendColumn(&batch.Columns[index], batch.Len, value)
+		}
+		batch.Len++
+	}
+	if r.err != nil {
+		return batch, r.err
+	}
+	if batch.Len == 0 {
+		return nil, io.EOF
+	}
+	return batch, nil
+}
+
+// appendColumn appends value, which is null or of the kind of the field of the column, to the column.
+func appendColumn(column *yx.Column, row int, value any) {
+	if value == nil {
+		column.Nulls[row/64] |= 1 << (row % 64)
+	}
+	switch column.Field.Type {
+	case yxrecord.Boolean:
+		v, _ := value.(bool)
+		column.Bools = append(column.Bools, v)
+	case yxrecord.Byte:
+		v, _ := value.(int64)
+		column.Bytes = append(column.Bytes, byte(v))
+	case yxrecord.Int64:
+		v, _ := value.(int64)
+		column.Int64s = append(column.Int64s, v)
+	case yxrecord.Float64:
+		if column.Field.FieldType != yxrecord.TypeFixedDecimal {
+			v, _ := value.(float64)
+			column.Float64s = append(column.Float64s, v)
+			break
+		}
+		v, ok := value.(*big.Rat)
+		if !ok {
+			v = new(big.Rat)
+		}
+		column.Float64s = append(column.Float64s, toFloat(v))
+		column.Decimals = append(column.Decimals, toDecimal(v, column.Field.Scale))
+	case yxrecord.String:
+		v, _ := value.(string)
+		column.Strings = append(column.Strings, v)
+	case yxrecord.Date:
+		v, _ := value.(time.Time)
+		column.Times = append(column.Times, v)
+	default:
+		v, _ := value.([]byte)
+		column.Blobs = append(column.Blobs, v)
+	}
+}
+
+// Copy writes the remaining rows to w in batches and closes w.
+func (r *Rows) Copy(w BatchWriter) error {
+	for {
+		batch, err := r.NextBatch(batchSize)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		err = w.Write(batch)
+		if err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// WriteFile writes the remaining rows to a new .yxdb file at path, replacing it if it exists.
+func (r *Rows) WriteFile(path string) error {
+	writer, err := yx.CreateFile(path, r.fields)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+		for index, value := range r.row {
+			if value == nil {
+				continue
+			}
+			switch r.list[index].Type {
+			case yxrecord.Boolean:
+				writer.WriteBoolWithIndex(index, value.(bool))
+			case yxrecord.Byte:
+				writer.WriteByteWithIndex(index, byte(value.(int64)))
+			case yxrecord.Int64:
+				writer.WriteInt64WithIndex(index, value.(int64))
+			case yxrecord.Float64:
+				writer.WriteFloat64WithIndex(index, toFloat(value))
+			case yxrecord.String:
+				writer.WriteStringWithIndex(index, value.(string))
+			case yxrecord.Date:
+				writer.WriteTimeWithIndex(index, value.(time.Time))
+			default:
+				writer.WriteBlobWithIndex(index, value.([]byte))
+			}
+		}
+		err = writer.WriteRecord()
+		if err != nil {
+			_ = writer.Close()
+			return err
+		}
+	}
+	closeErr := writer.Close()
+	if err = r.Err(); err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,326 @@
+package yxquery
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"slices"
+)
+
+// maxMergeRuns is the number of sorted runs merged at once, which bounds the number of open files.
+const maxMergeRuns = 64
+
+// sortKey is a column of the rows being sorted. Nulls sort before other values in ascending order.
+type sortKey struct {
+	index int
+	desc  bool
+}
+
+// sorter sorts its input with an external merge sort. Rows are buffered until their estimated size exceeds memory,
+// then sorted and written to a temporary file as a run, and the runs are merged when the input ends. Inputs that fit
+// in memory are sorted without touching the disk. The sort is stable.
+type sorter struct {
+	input   operator
+	keys    []sortKey
+	memory  int64
+	tempDir string
+
+	rows  [][]any
+	pos   int
+	runs  []string
+	merge *merger
+}
+
+func (s *sorter) compare(a, b []any) int {
+	for _, key := range s.keys {
+		result := compareNullsFirst(a[key.index], b[key.index])
+		if key.desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func (s *sorter) open() error {
+	err := s.input.open()
+	if err != nil {
+		return err
+	}
+	s.rows, s.pos, s.runs = nil, 0, nil
+	var size int64
+	for {
+		row, err := s.input.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = s.input.close()
+			return err
+		}
+		s.rows = append(s.rows, row)
+		size += 24
+		for _, value := range row {
+			size += valueSize(value)
+		}
+		if size > s.memory && len(s.rows) > 1 {
+			if err = s.spill(); err != nil {
+				_ = s.input.close()
+				return err
+			}
+			size = 0
+		}
+	}
+	if err = s.input.close(); err != nil {
+		return err
+	}
+	if len(s.runs) == 0 {
+		slices.SortStableFunc(s.rows, s.compare)
+		return nil
+	}
+	if err = s.spill(); err != nil {
+		return err
+	}
+	// merge consecutive runs, which keeps the sort stable, until they can be merged at once
+	for len(s.runs) > maxMergeRuns {
+		path, err := s.mergeRuns(s.runs[:maxMergeRuns])
+		if err != nil {
+			return err
+		}
+		s.runs = append([]string{path}, s.runs[maxMergeRuns:]...)
+	}
+	s.merge, err = s.newMerger(s.runs)
+	return err
+}
+
+// spill sorts the buffered rows and writes them to a new run.
+func (s *sorter) spill() error {
+	slices.SortStableFunc(s.rows, s.compare)
+	path, err := s.writeRun(func(yield func([]any) error) error {
+		for _, row := range s.rows {
+			if err := yield(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.rows = nil
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, path)
+	return nil
+}
+
+// writeRun writes the rows produced by rows to a temporary file. Each row is written as the length of its encoding
+// followed by its encoded values.
+func (s *sorter) writeRun(rows func(yield func([]any) error) error) (string, error) {
+	file, err := os.CreateTemp(s.tempDir, `yxquery-*.run`)
+	if err != nil {
+		return ``, err
+	}
+	out := bufio.NewWriter(file)
+	var encoded []byte
+	err = rows(func(row []any) error {
+		encoded = encoded[:0]
+		for _, value := range row {
+			encoded = appendValue(encoded, value)
+		}
+		_, err := out.Write(binary.AppendUvarint(nil, uint64(len(encoded))))
+		if err == nil {
+			_, err = out.Write(encoded)
+		}
+		return err
+	})
+	if err == nil {
+		err = out.Flush()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return ``, err
+	}
+	return file.Name(), nil
+}
+
+// mergeRuns merges runs into a new run and removes them.
+func (s *sorter) mergeRuns(runs []string) (string, error) {
+	m, err := s.newMerger(runs)
+	if err != nil {
+		return ``, err
+	}
+	path, err := s.writeRun(func(yield func([]any) error) error {
+		for {
+			row, err := m.next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err = yield(row); err != nil {
+				return err
+			}
+		}
+	})
+	closeErr := m.close()
+	if err == nil {
+		err = closeErr
+	}
+	for _, run := range runs {
+		_ = os.Remove(run)
+	}
+	return path, err
+}
+
+func (s *sorter) next() ([]any, error) {
+	if s.merge != nil {
+		return s.merge.next()
+	}
+	if s.pos >= len(s.rows) {
+		return nil, io.EOF
+	}
+	s.pos++
+	return s.rows[s.pos-1], nil
+}
+
+func (s *sorter) close() error {
+	var err error
+	if s.merge != nil {
+		err = s.merge.close()
+		s.merge = nil
+	}
+	for _, run := range s.runs {
+		_ = os.Remove(run)
+	}
+	s.rows, s.runs = nil, nil
+	return err
+}
+
+// runReader reads the rows of a run.
+type runReader struct {
+	file    *os.File
+	in      *bufio.Reader
+	order   int
+	current []any
+	buffer  []byte
+}
+
+func (r *runReader) read() error {
+	length, err := binary.ReadUvarint(r.in)
+	if err != nil {
+		return err
+	}
+	if uint64(cap(r.buffer)) < length {
+		r.buffer = make([]byte, length)
+	}
+	r.buffer = r.buffer[:length]
+	if _, err = io.ReadFull(r.in, r.buffer); err != nil {
+		return errCorruptRun
+	}
+	var row []any
+	for data := r.buffer; len(data) > 0; {
+		value, n, err := readValue(data)
+		if err != nil {
+			return err
+		}
+		row = append(row, value)
+		data = data[n:]
+	}
+	r.current = row
+	return nil
+}
+
+// merger merges sorted runs with a heap of their next rows. Rows that compare equal are taken from the earlier run.
+type merger struct {
+	sorter  *sorter
+	readers []*runReader
+	heap    []*runReader
+}
+
+func (s *sorter) newMerger(runs []string) (*merger, error) {
+	m := &merger{sorter: s}
+	for order, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			_ = m.close()
+			return nil, err
+		}
+		reader := &runReader{file: file, in: bufio.NewReader(file), order: order}
+		m.readers = append(m.readers, reader)
+		err = reader.read()
+		if errors.Is(err, io.EOF) {
+			continue
+		}
+		if err != nil {
+			_ = m.close()
+			return nil, err
+		}
+		m.heap = append(m.heap, reader)
+	}
+	heap.Init(m)
+	return m, nil
+}
+
+func (m *merger) next() ([]any, error) {
+	if len(m.heap) == 0 {
+		return nil, io.EOF
+	}
+	reader := m.heap[0]
+	row := reader.current
+	err := reader.read()
+	switch {
+	case errors.Is(err, io.EOF):
+		heap.Pop(m)
+	case err != nil:
+		return nil, err
+	default:
+		heap.Fix(m, 0)
+	}
+	return row, nil
+}
+
+func (m *merger) close() error {
+	var err error
+	for _, reader := range m.readers {
+		if closeErr := reader.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	m.readers, m.heap = nil, nil
+	return err
+}
+
+func (m *merger) Len() int {
+	return len(m.heap)
+}
+
+func (m *merger) Less(i, j int) bool {
+	a, b := m.heap[i], m.heap[j]
+	if result := m.sorter.compare(a.current, b.current); result != 0 {
+		return result < 0
+	}
+	return a.order < b.order
+}
+
+func (m *merger) Swap(i, j int) {
+	m.heap[i], m.heap[j] = m.heap[j], m.heap[i]
+}
+
+func (m *merger) Push(x any) {
+	m.heap = append(m.heap, x.(*runReader))
+}
+
+func (m *merger) Pop() any {
+	last := m.heap[len(m.heap)-1]
+	m.heap = m.heap[:len(m.heap)-1]
+	return last
+}
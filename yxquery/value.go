@@ -0,0 +1,432 @@
+package yxquery
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values flow through a query as nil for null, bool, int64, float64, *big.Rat, string, time.Time, or []byte. Byte and
+// integer fields are read as int64, and FixedDecimal fields as *big.Rat so that they keep every digit. Rats are never
+// modified once created.
+
+// kind is the type of the values of an expression.
+type kind int
+
+const (
+	// kindNull is the kind of the NULL literal, which can be used wherever a value of any kind is expected.
+	kindNull kind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindDecimal
+	kindString
+	kindTime
+	kindBlob
+)
+
+var kindNames = []string{`null`, `bool`, `integer`, `float`, `decimal`, `string`, `date`, `blob`}
+
+func (k kind) String() string {
+	return kindNames[k]
+}
+
+func (k kind) numeric() bool {
+	return k == kindInt || k == kindFloat || k == kindDecimal
+}
+
+// fieldKind returns the kind of the values of a field.
+func fieldKind(field metafield.MetaInfoField) kind {
+	fieldType, err := yxrecord.ParseFieldType(field.Type)
+	if err != nil {
+		return kindBlob
+	}
+	if fieldType == yxrecord.TypeFixedDecimal {
+		return kindDecimal
+	}
+	switch fieldType.DataType() {
+	case yxrecord.Boolean:
+		return kindBool
+	case yxrecord.Byte, yxrecord.Int64:
+		return kindInt
+	case yxrecord.Float64:
+		return kindFloat
+	case yxrecord.String:
+		return kindString
+	case yxrecord.Date:
+		return kindTime
+	default:
+		return kindBlob
+	}
+}
+
+// kindField returns the type of the output field for a computed value of kind k. Decimals get a scale of 0; callers
+// that compute decimals set the field from the scales of their operands with decimalField.
+func kindField(k kind) metafield.MetaInfoField {
+	switch k {
+	case kindBool:
+		return metafield.MetaInfoField{Type: `Bool`}
+	case kindInt:
+		return metafield.MetaInfoField{Type: `Int64`}
+	case kindFloat:
+		return metafield.MetaInfoField{Type: `Double`}
+	case kindDecimal:
+		return decimalField(0, 0)
+	case kindTime:
+		return metafield.MetaInfoField{Type: `DateTime`}
+	case kindBlob:
+		return metafield.MetaInfoField{Type: `Blob`}
+	default:
+		return metafield.MetaInfoField{Type: `V_WString`}
+	}
+}
+
+// decimalField returns the type of a FixedDecimal output field with at least size digits and scale decimal places.
+func decimalField(size int, scale int) metafield.MetaInfoField {
+	return metafield.MetaInfoField{Type: `FixedDecimal`, Size: max(size, 38), Scale: scale}
+}
+
+// ratScale returns the number of decimal places needed to write value exactly, or maxScale if value is not a
+// terminating decimal.
+func ratScale(value *big.Rat) int {
+	const maxScale = 38
+	power := big.NewInt(1)
+	remainder := new(big.Int)
+	for scale := 0; scale < maxScale; scale++ {
+		if remainder.Mod(power, value.Denom()).Sign() == 0 {
+			return scale
+		}
+		power.Mul(power, big.NewInt(10))
+	}
+	return maxScale
+}
+
+// valueKind returns the kind of a value.
+func valueKind(value any) kind {
+	switch value.(type) {
+	case bool:
+		return kindBool
+	case int64:
+		return kindInt
+	case float64:
+		return kindFloat
+	case *big.Rat:
+		return kindDecimal
+	case string:
+		return kindString
+	case time.Time:
+		return kindTime
+	case []byte:
+		return kindBlob
+	default:
+		return kindNull
+	}
+}
+
+// commonKind returns the kind that values of kinds a and b are converted to when they are compared or combined.
+// Integers combine with decimals as decimals, floats combine with other numbers as floats, and null combines with any
+// kind.
+func commonKind(a, b kind) (kind, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == kindNull:
+		return b, true
+	case b == kindNull:
+		return a, true
+	case a == kindFloat && b.numeric() || b == kindFloat && a.numeric():
+		return kindFloat, true
+	case a.numeric() && b.numeric():
+		return kindDecimal, true
+	}
+	return kindNull, false
+}
+
+// compareValues compares two values that are not null and have kinds that can be combined, returning a negative
+// number, zero, or a positive number as in cmp.Compare. Numbers compare by their numeric value, exactly unless one of
+// them is a float.
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return cmp.Compare(a, b)
+		case *big.Rat:
+			return new(big.Rat).SetInt64(a).Cmp(b)
+		}
+		return cmp.Compare(float64(a), toFloat(b))
+	case *big.Rat:
+		switch b := b.(type) {
+		case int64:
+			return a.Cmp(new(big.Rat).SetInt64(b))
+		case *big.Rat:
+			return a.Cmp(b)
+		}
+		return cmp.Compare(toFloat(a), toFloat(b))
+	case float64:
+		return cmp.Compare(a, toFloat(b))
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		b := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case b:
+			return -1
+		default:
+			return 1
+		}
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case []byte:
+		return bytes.Compare(a, b.([]byte))
+	}
+	return 0
+}
+
+// compareNullsFirst compares values like compareValues, ordering nulls before every other value.
+func compareNullsFirst(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return compareValues(a, b)
+}
+
+func toFloat(value any) float64 {
+	switch value := value.(type) {
+	case int64:
+		return float64(value)
+	case *big.Rat:
+		result, _ := value.Float64()
+		return result
+	}
+	return value.(float64)
+}
+
+func toRat(value any) *big.Rat {
+	if integer, ok := value.(int64); ok {
+		return new(big.Rat).SetInt64(integer)
+	}
+	return value.(*big.Rat)
+}
+
+// convert converts a value that is not null to kind k, which is the same kind or, for numbers, a kind that they
+// combine with.
+func convert(value any, k kind) any {
+	switch k {
+	case kindFloat:
+		return toFloat(value)
+	case kindDecimal:
+		return toRat(value)
+	}
+	return value
+}
+
+// errOverflow is returned by integer arithmetic whose result does not fit in an int64.
+var errOverflow = errors.New(`integer overflow`)
+
+func addInt(x, y int64) (int64, error) {
+	sum := x + y
+	if y > 0 && sum < x || y < 0 && sum > x {
+		return 0, errOverflow
+	}
+	return sum, nil
+}
+
+func subtractInt(x, y int64) (int64, error) {
+	difference := x - y
+	if y > 0 && difference > x || y < 0 && difference < x {
+		return 0, errOverflow
+	}
+	return difference, nil
+}
+
+func multiplyInt(x, y int64) (int64, error) {
+	if x == 0 || y == 0 {
+		return 0, nil
+	}
+	product := x * y
+	if product/y != x || x == -1 && y == math.MinInt64 || y == -1 && x == math.MinInt64 {
+		return 0, errOverflow
+	}
+	return product, nil
+}
+
+// toText formats a value for string concatenation.
+func toText(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	case *big.Rat:
+		return value.FloatString(ratScale(value))
+	case bool:
+		return strconv.FormatBool(value)
+	case time.Time:
+		return formatTime(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+const (
+	dateLayout     = `2006-01-02`
+	dateTimeLayout = `2006-01-02 15:04:05`
+)
+
+// formatTime formats dates without a time of day as dates, and other times as date times.
+func formatTime(value time.Time) string {
+	if value.Hour() == 0 && value.Minute() == 0 && value.Second() == 0 && value.Nanosecond() == 0 {
+		return value.Format(dateLayout)
+	}
+	return value.Format(dateTimeLayout)
+}
+
+// timeLayouts are the layouts accepted for strings compared with Date and DateTime values.
+var timeLayouts = []string{dateLayout, dateTimeLayout, `2006-01-02T15:04:05`, time.RFC3339Nano}
+
+func parseTime(text string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if value, err := time.Parse(layout, text); err == nil {
+			return value.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Rows are encoded as a sequence of values, each a tag byte followed by the value, for spilling sorted runs to disk
+// and as the keys of joins, groups, and distinct rows.
+const (
+	tagNull byte = iota
+	tagFalse
+	tagTrue
+	tagInt
+	tagFloat
+	tagString
+	tagTime
+	tagBlob
+	tagDecimal
+)
+
+var errCorruptRun = errors.New(`the sort run file is corrupt`)
+
+// appendValue appends the encoding of value to dst.
+func appendValue(dst []byte, value any) []byte {
+	switch value := value.(type) {
+	case nil:
+		return append(dst, tagNull)
+	case bool:
+		if value {
+			return append(dst, tagTrue)
+		}
+		return append(dst, tagFalse)
+	case int64:
+		return binary.LittleEndian.AppendUint64(append(dst, tagInt), uint64(value))
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(dst, tagFloat), math.Float64bits(value))
+	case string:
+		dst = binary.AppendUvarint(append(dst, tagString), uint64(len(value)))
+		return append(dst, value...)
+	case time.Time:
+		dst = binary.AppendVarint(append(dst, tagTime), value.Unix())
+		return binary.AppendUvarint(dst, uint64(value.Nanosecond()))
+	case []byte:
+		dst = binary.AppendUvarint(append(dst, tagBlob), uint64(len(value)))
+		return append(dst, value...)
+	case *big.Rat:
+		// rats are kept in lowest terms, so equal values have equal encodings
+		encoded, _ := value.GobEncode()
+		dst = binary.AppendUvarint(append(dst, tagDecimal), uint64(len(encoded)))
+		return append(dst, encoded...)
+	}
+	panic(fmt.Sprintf(`unexpected value of type %T`, value))
+}
+
+// readValue decodes the value at the start of src, returning it and the number of bytes read.
+func readValue(src []byte) (any, int, error) {
+	if len(src) == 0 {
+		return nil, 0, errCorruptRun
+	}
+	switch src[0] {
+	case tagNull:
+		return nil, 1, nil
+	case tagFalse:
+		return false, 1, nil
+	case tagTrue:
+		return true, 1, nil
+	case tagInt, tagFloat:
+		if len(src) < 9 {
+			return nil, 0, errCorruptRun
+		}
+		bits := binary.LittleEndian.Uint64(src[1:])
+		if src[0] == tagInt {
+			return int64(bits), 9, nil
+		}
+		return math.Float64frombits(bits), 9, nil
+	case tagString, tagBlob, tagDecimal:
+		length, n := binary.Uvarint(src[1:])
+		if n <= 0 || uint64(len(src)-1-n) < length {
+			return nil, 0, errCorruptRun
+		}
+		data := src[1+n : 1+n+int(length)]
+		switch src[0] {
+		case tagString:
+			return string(data), 1 + n + int(length), nil
+		case tagDecimal:
+			value := new(big.Rat)
+			if value.GobDecode(data) != nil {
+				return nil, 0, errCorruptRun
+			}
+			return value, 1 + n + int(length), nil
+		}
+		return append([]byte{}, data...), 1 + n + int(length), nil
+	case tagTime:
+		seconds, n := binary.Varint(src[1:])
+		if n <= 0 {
+			return nil, 0, errCorruptRun
+		}
+		nanoseconds, m := binary.Uvarint(src[1+n:])
+		if m <= 0 {
+			return nil, 0, errCorruptRun
+		}
+		return time.Unix(seconds, int64(nanoseconds)).UTC(), 1 + n + m, nil
+	}
+	return nil, 0, errCorruptRun
+}
+
+// valueSize estimates the memory used by a value in a row, for deciding when to spill a sort to disk.
+func valueSize(value any) int64 {
+	const interfaceSize = 16
+	switch value := value.(type) {
+	case string:
+		return interfaceSize + 16 + int64(len(value))
+	case []byte:
+		return interfaceSize + 24 + int64(len(value))
+	case time.Time:
+		return interfaceSize + 24
+	case int64, float64:
+		return interfaceSize + 8
+	case *big.Rat:
+		return interfaceSize + 80 + int64(value.Num().BitLen()+value.Denom().BitLen())/8
+	default:
+		return interfaceSize
+	}
+}
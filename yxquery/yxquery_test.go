@@ -0,0 +1,337 @@
+package yxquery_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	yx "github.com/tlarsendataguy-yxdb/yxdb-go"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/csvconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/jsonlconv"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/yxquery"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJoinGroupAndOrder(t *testing.T) {
+	dir := writeTestFiles(t)
+	rows := query(t, fmt.Sprintf(`SELECT State, SUM(Amount) FROM '%v' JOIN '%v' USING (RegionId) GROUP BY State ORDER BY 2 DESC`,
+		filepath.Join(dir, `sales.yxdb`), filepath.Join(dir, `regions.yxdb`)))
+	expectedFields := []metafield.MetaInfoField{
+		{Name: `State`, Type: `String`, Size: 10},
+		{Name: `Sum_Amount`, Type: `FixedDecimal`, Size: 38, Scale: 2},
+	}
+	if fields := rows.Fields(); !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf(`expected %v but got %v`, expectedFields, fields)
+	}
+	expected := [][]any{
+		{`CA`, decimal.Decimal{Text: `22.75`, Scale: 2}},
+		{`NY`, decimal.Decimal{Text: `20.00`, Scale: 2}},
+	}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+}
+
+func TestProjectionAndFilter(t *testing.T) {
+	dir := writeTestFiles(t)
+	rows := query(t, `SELECT Product AS Name, Amount * 2 AS Doubled, UPPER(Product) || '-' || Id, YEAR(Date) FROM sales
+		WHERE Amount >= ? AND Product NOT LIKE 'C%' AND Date < '2024-02-15' ORDER BY Id DESC`,
+		yxquery.WithTable(`sales`, filepath.Join(dir, `sales.yxdb`)), yxquery.WithArgs(5))
+	expectedFields := []metafield.MetaInfoField{
+		{Name: `Name`, Type: `V_WString`, Size: 20},
+		{Name: `Doubled`, Type: `FixedDecimal`, Size: 38, Scale: 2},
+		{Name: `(UPPER(Product) || '-') || Id`, Type: `V_WString`},
+		{Name: `YEAR(Date)`, Type: `Int64`},
+	}
+	if fields := rows.Fields(); !reflect.DeepEqual(fields, expectedFields) {
+		t.Fatalf(`expected %v but got %v`, expectedFields, fields)
+	}
+	expected := [][]any{
+		{`b`, decimal.Decimal{Text: `10.50`, Scale: 2}, `B-3`, int64(2024)},
+		{`b`, decimal.Decimal{Text: `40.00`, Scale: 2}, `B-2`, int64(2024)},
+		{`a`, decimal.Decimal{Text: `21.00`, Scale: 2}, `A-1`, int64(2024)},
+	}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+}
+
+func TestLeftJoin(t *testing.T) {
+	dir := writeTestFiles(t)
+	sales, regions := yxquery.WithTable(`sales`, filepath.Join(dir, `sales.yxdb`)), yxquery.WithTable(`regions`, filepath.Join(dir, `regions.yxdb`))
+	rows := query(t, `SELECT s.Id, r.State FROM sales s LEFT JOIN regions r ON s.RegionId = r.RegionId AND r.State <> 'NY'
+		WHERE r.State IS NULL OR r.State = 'CA' ORDER BY r.State, s.Id`, sales, regions)
+	expected := [][]any{{int64(2), nil}, {int64(5), nil}, {int64(6), nil}, {int64(1), `CA`}, {int64(3), `CA`}, {int64(4), `CA`}}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+
+	rows = query(t, `SELECT State, COUNT(*), COUNT(DISTINCT Product), AVG(Amount), MIN(s.Date) FROM sales s, regions
+		WHERE s.RegionId = regions.RegionId GROUP BY 1 HAVING COUNT(*) > 1 ORDER BY MAX(Id)`, sales, regions)
+	expected = [][]any{{`CA`, int64(3), int64(3), 7.583333333333333, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+}
+
+func TestAggregateWithoutGroups(t *testing.T) {
+	dir := writeTestFiles(t)
+	rows := query(t, fmt.Sprintf(`SELECT COUNT(*), COUNT(Amount), SUM(Id), MAX(Product) FROM '%v' WHERE Id > 100`, filepath.Join(dir, `sales.yxdb`)))
+	expectedNames := []string{`Count`, `Count_Amount`, `Sum_Id`, `Max_Product`}
+	for index, field := range rows.Fields() {
+		if field.Name != expectedNames[index] {
+			t.Fatalf(`expected %v but got %v`, expectedNames[index], field.Name)
+		}
+	}
+	expected := [][]any{{int64(0), int64(0), nil, nil}}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+}
+
+func TestSortSpillsToDisk(t *testing.T) {
+	tempDir := t.TempDir()
+	rows := query(t, `SELECT RowCount, RowCount % 3 AS Remainder FROM '../test_files/LotsOfRecords.yxdb' ORDER BY Remainder DESC, RowCount LIMIT 5 OFFSET 33332`,
+		yxquery.WithSortMemory(64<<10), yxquery.WithTempDir(tempDir))
+	expected := [][]any{{int64(99998), int64(2)}, {int64(1), int64(1)}, {int64(4), int64(1)}, {int64(7), int64(1)}, {int64(10), int64(1)}}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+	files, _ := os.ReadDir(tempDir)
+	if len(files) != 0 {
+		t.Fatalf(`expected the temporary files to be removed but got %v`, len(files))
+	}
+
+	_, err := yxquery.Query(`SELECT RowCount * 92233720368547758 AS Product FROM '../test_files/LotsOfRecords.yxdb' ORDER BY Product`,
+		yxquery.WithSortMemory(1<<10), yxquery.WithTempDir(tempDir))
+	if err == nil {
+		t.Fatalf(`expected an integer overflow but got none`)
+	}
+	files, _ = os.ReadDir(tempDir)
+	if len(files) != 0 {
+		t.Fatalf(`expected the temporary files to be removed after an error but got %v`, len(files))
+	}
+
+	rows = query(t, `SELECT RowCount FROM '../test_files/LotsOfRecords.yxdb' ORDER BY RowCount DESC`, yxquery.WithSortMemory(1<<10), yxquery.WithTempDir(tempDir))
+	expectedValue := int64(100000)
+	for rows.Next() {
+		if value := rows.Values()[0]; value != expectedValue {
+			t.Fatalf(`expected %v but got %v`, expectedValue, value)
+		}
+		expectedValue--
+	}
+	if rows.Err() != nil || expectedValue != 0 {
+		t.Fatalf(`expected every row but stopped at %v with %v`, expectedValue, rows.Err())
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	dir := writeTestFiles(t)
+	sql := fmt.Sprintf(`SELECT Id, Product, Amount FROM '%v' WHERE RegionId IN (1, 2) ORDER BY Id`, filepath.Join(dir, `sales.yxdb`))
+
+	var csv bytes.Buffer
+	rows := query(t, sql)
+	writer, err := csvconv.NewWriter(&csv, rows.ListFields(), csvconv.Options{})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	err = rows.Copy(writer)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if expected := "Id,Product,Amount\n1,a,10.50\n2,b,20.00\n3,b,5.25\n"; csv.String() != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, csv.String())
+	}
+
+	var jsonl bytes.Buffer
+	rows = query(t, sql+` LIMIT 1`)
+	jsonWriter, err := jsonlconv.NewWriter(&jsonl, rows.ListFields())
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	err = rows.Copy(jsonWriter)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if expected := "{\"Id\":1,\"Product\":\"a\",\"Amount\":10.50}\n"; jsonl.String() != expected {
+		t.Fatalf("expected\n%v\nbut got\n%v", expected, jsonl.String())
+	}
+
+	path := filepath.Join(dir, `result.yxdb`)
+	rows = query(t, sql)
+	err = rows.WriteFile(path)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	rows = query(t, fmt.Sprintf(`SELECT * FROM '%v'`, path))
+	expected := [][]any{
+		{int64(1), `a`, decimal.Decimal{Text: `10.50`, Scale: 2}},
+		{int64(2), `b`, decimal.Decimal{Text: `20.00`, Scale: 2}},
+		{int64(3), `b`, decimal.Decimal{Text: `5.25`, Scale: 2}},
+	}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+}
+
+func TestDecimalsAreExact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `amounts.yxdb`)
+	writeFile(t, path, []metafield.MetaInfoField{{Name: `Amount`, Type: `FixedDecimal`, Size: 20, Scale: 2}},
+		[][]any{{1000000000000000.25}, {0.01}, {0.01}, {0.01}})
+	rows := query(t, fmt.Sprintf(`SELECT SUM(Amount), MAX(Amount) - 0.25, COUNT(CASE WHEN Amount = ? THEN 1 END) FROM '%v'`, path),
+		yxquery.WithArgs(decimal.Decimal{Text: `0.01`, Scale: 2}))
+	expected := [][]any{{decimal.Decimal{Text: `1000000000000000.28`, Scale: 2}, 1e15, int64(3)}}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+
+	rows = query(t, fmt.Sprintf(`SELECT -Amount + 1, ROUND(Amount, 1) FROM '%v' WHERE Amount > 1`, path))
+	expected = [][]any{{decimal.Decimal{Text: `-999999999999999.25`, Scale: 2}, decimal.Decimal{Text: `1000000000000000.30`, Scale: 2}}}
+	if values := readAll(t, rows); !reflect.DeepEqual(values, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, values)
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	dir := writeTestFiles(t)
+	sales := yxquery.WithTable(`sales`, filepath.Join(dir, `sales.yxdb`))
+	_, err := yxquery.Query(`SELECT 9223372036854775807 + 1 FROM sales`, sales)
+	if err == nil || err.Error() != `integer overflow in 9223372036854775807 + 1` {
+		t.Fatalf(`expected an integer overflow but got %v`, err)
+	}
+
+	for _, sql := range []string{`SELECT Id * 4611686018427387904 FROM sales`, `SELECT SUM(Id + 9223372036854775800) FROM sales`} {
+		rows, err := yxquery.Query(sql, sales)
+		if err == nil {
+			for rows.Next() {
+			}
+			err = rows.Err()
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), `integer overflow in`) {
+			t.Fatalf(`expected an integer overflow for %v but got %v`, sql, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	dir := writeTestFiles(t)
+	sales := yxquery.WithTable(`sales`, filepath.Join(dir, `sales.yxdb`))
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{`SELECT Id FROM missing`, `table 'missing' is not registered; quote file paths with single quotes`},
+		{`SELECT Id, Product FROM sales GROUP BY Id`, `field 'Product' must appear in GROUP BY or be used in an aggregate function`},
+		{`SELECT * FROM sales GROUP BY Id`, `* cannot be used with GROUP BY or aggregate functions`},
+		{`SELECT Id FROM sales WHERE SUM(Amount) > 1`, `aggregate function SUM(Amount) is not allowed in WHERE`},
+		{`SELECT Id FROM sales a JOIN sales b USING (Id) WHERE Product = 'a'`, `field 'Product' is ambiguous; qualify it with the name of its table`},
+		{`SELECT Id FROM sales JOIN sales USING (Id)`, `table 'sales' appears more than once; give it an alias`},
+		{`SELECT Id FROM sales ORDER BY 2`, `ORDER BY position 2 is not in the select list`},
+		{`SELECT DISTINCT Id FROM sales ORDER BY Product`, `ORDER BY Product must be in the select list of SELECT DISTINCT`},
+		{`SELECT Id FROM sales LIMIT Id`, `field 'Id' does not exist: field not found`},
+		{`SELECT Id FROM sales RIGHT JOIN sales b USING (Id)`, `RIGHT joins are not supported; swap the tables and use a LEFT JOIN`},
+	}
+	for _, c := range cases {
+		_, err := yxquery.Query(c.query, sales)
+		if err == nil || err.Error() != c.expected {
+			t.Fatalf("expected error for %v to be\n%v\nbut got\n%v", c.query, c.expected, err)
+		}
+	}
+
+	_, err := yxquery.Query(`SELECT Missing FROM sales`, sales)
+	if !errors.Is(err, yx.ErrFieldNotFound) {
+		t.Fatalf(`expected an error wrapping ErrFieldNotFound but got %v`, err)
+	}
+	_, err = yxquery.Query(`SELECT Id FROM sales WHERE Product > 1`, sales)
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected an error wrapping ErrTypeMismatch but got %v`, err)
+	}
+	_, err = yxquery.Query(`SELECT SUM(Product) FROM sales`, sales)
+	if !errors.Is(err, yx.ErrTypeMismatch) {
+		t.Fatalf(`expected an error wrapping ErrTypeMismatch but got %v`, err)
+	}
+}
+
+func query(t *testing.T, sql string, opts ...yxquery.Option) *yxquery.Rows {
+	rows, err := yxquery.Query(sql, opts...)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	t.Cleanup(func() { _ = rows.Close() })
+	return rows
+}
+
+func readAll(t *testing.T, rows *yxquery.Rows) [][]any {
+	var values [][]any
+	for rows.Next() {
+		values = append(values, rows.Values())
+	}
+	if rows.Err() != nil {
+		t.Fatalf(`expected no error but got: %v`, rows.Err().Error())
+	}
+	return values
+}
+
+// writeTestFiles writes sales.yxdb and regions.yxdb to a temporary directory and returns the directory.
+func writeTestFiles(t *testing.T) string {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, `sales.yxdb`), []metafield.MetaInfoField{
+		{Name: `Id`, Type: `Int32`},
+		{Name: `RegionId`, Type: `Int16`},
+		{Name: `Product`, Type: `V_WString`, Size: 20},
+		{Name: `Amount`, Type: `FixedDecimal`, Size: 10, Scale: 2},
+		{Name: `Date`, Type: `Date`},
+	}, [][]any{
+		{int64(1), int64(1), `a`, 10.5, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{int64(2), int64(2), `b`, 20.0, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{int64(3), int64(1), `b`, 5.25, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{int64(4), int64(3), `c`, 7.0, nil},
+		{int64(5), int64(4), `a`, nil, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{int64(6), nil, `d`, 1.0, nil},
+	})
+	writeFile(t, filepath.Join(dir, `regions.yxdb`), []metafield.MetaInfoField{
+		{Name: `RegionId`, Type: `Int32`},
+		{Name: `State`, Type: `String`, Size: 10},
+	}, [][]any{
+		{int64(1), `CA`},
+		{int64(2), `NY`},
+		{int64(3), `CA`},
+		{int64(5), `TX`},
+	})
+	return dir
+}
+
+func writeFile(t *testing.T, path string, fields []metafield.MetaInfoField, records [][]any) {
+	writer, err := yx.CreateFile(path, fields)
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	for _, record := range records {
+		for index, value := range record {
+			switch value := value.(type) {
+			case int64:
+				writer.WriteInt64WithIndex(index, value)
+			case float64:
+				writer.WriteFloat64WithIndex(index, value)
+			case string:
+				writer.WriteStringWithIndex(index, value)
+			case time.Time:
+				writer.WriteTimeWithIndex(index, value)
+			}
+		}
+		err = writer.WriteRecord()
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+}
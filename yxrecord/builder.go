@@ -0,0 +1,306 @@
+package yxrecord
+
+import (
+	"encoding/binary"
+	"errors"
+	m "github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
+	s "github.com/tlarsendataguy-yxdb/yxdb-go/setters"
+	"time"
+)
+
+// YxdbRecordBuilder encodes field values into the binary record format used by .yxdb files.
+//
+// Values are set field-by-field and then encoded with Build. Fields that have not been set since the last call to
+// Build are written as null.
+type YxdbRecordBuilder struct {
+	Fields         []YxdbField
+	FixedSize      int
+	HasVar         bool
+	nameToIndex    map[string]int
+	fixed          []byte
+	varFields      []int
+	varValues      map[int][]byte
+	blobSetters    map[int]s.BlobSetter
+	nullSetters    map[int]func()
+	boolSetters    map[int]func(bool)
+	byteSetters    map[int]func(byte)
+	int64Setters   map[int]func(int64) error
+	float64Setters map[int]func(float64) error
+	stringSetters  map[int]func(string)
+	timeSetters    map[int]func(time.Time)
+	blobValues     map[int]func([]byte)
+}
+
+func BuilderFromFieldList(fields []m.MetaInfoField) (*YxdbRecordBuilder, error) {
+	builder := &YxdbRecordBuilder{
+		Fields:         make([]YxdbField, 0, len(fields)),
+		nameToIndex:    make(map[string]int, len(fields)),
+		varValues:      make(map[int][]byte),
+		blobSetters:    make(map[int]s.BlobSetter),
+		nullSetters:    make(map[int]func()),
+		boolSetters:    make(map[int]func(bool)),
+		byteSetters:    make(map[int]func(byte)),
+		int64Setters:   make(map[int]func(int64) error),
+		float64Setters: make(map[int]func(float64) error),
+		stringSetters:  make(map[int]func(string)),
+		timeSetters:    make(map[int]func(time.Time)),
+		blobValues:     make(map[int]func([]byte)),
+	}
+	startAt := 0
+	for _, field := range fields {
+		switch field.Type {
+		case `Int16`:
+			builder.addInt64Setter(field, s.NewInt16Setter(startAt), s.NewNullSetter(startAt, 2))
+			startAt += 3
+		case `Int32`:
+			builder.addInt64Setter(field, s.NewInt32Setter(startAt), s.NewNullSetter(startAt, 4))
+			startAt += 5
+		case `Int64`:
+			builder.addInt64Setter(field, s.NewInt64Setter(startAt), s.NewNullSetter(startAt, 8))
+			startAt += 9
+		case `Float`:
+			builder.addFloat64Setter(field, s.NewFloatSetter(startAt), s.NewNullSetter(startAt, 4))
+			startAt += 5
+		case `Double`:
+			builder.addFloat64Setter(field, s.NewDoubleSetter(startAt), s.NewNullSetter(startAt, 8))
+			startAt += 9
+		case `FixedDecimal`:
+			builder.addFloat64Setter(field, s.NewFixedDecimalSetter(startAt, field.Size, field.Scale), s.NewNullSetter(startAt, field.Size))
+			startAt += field.Size + 1
+		case `String`:
+			builder.addStringSetter(field, s.NewStringSetter(startAt, field.Size), s.NewNullSetter(startAt, field.Size))
+			startAt += field.Size + 1
+		case `WString`:
+			builder.addStringSetter(field, s.NewWStringSetter(startAt, field.Size), s.NewNullSetter(startAt, field.Size*2))
+			startAt += (field.Size * 2) + 1
+		case `V_String`:
+			builder.addVarStringSetter(field, s.NewBlobSetter(startAt), func(value string) []byte { return []byte(value) })
+			startAt += 4
+		case `V_WString`:
+			builder.addVarStringSetter(field, s.NewBlobSetter(startAt), s.StringToUtf16Bytes)
+			startAt += 4
+		case `Date`:
+			builder.addTimeSetter(field, s.NewDateSetter(startAt), s.NewNullSetter(startAt, 10))
+			startAt += 11
+		case `DateTime`:
+			builder.addTimeSetter(field, s.NewDateTimeSetter(startAt), s.NewNullSetter(startAt, 19))
+			startAt += 20
+		case `Bool`:
+			builder.addBoolSetter(field, s.NewBoolSetter(startAt), s.NewBoolNullSetter(startAt))
+			startAt += 1
+		case `Byte`:
+			builder.addByteSetter(field, s.NewByteSetter(startAt), s.NewNullSetter(startAt, 1))
+			startAt += 2
+		case `Blob`, `SpatialObj`:
+			builder.addBlobSetter(field, s.NewBlobSetter(startAt))
+			startAt += 4
+		default:
+			return nil, errors.New("field type not supported, cannot build a yxdb record")
+		}
+	}
+	builder.FixedSize = startAt
+	builder.fixed = make([]byte, startAt)
+	builder.Reset()
+	return builder, nil
+}
+
+// Build encodes the values that have been set into a single record and resets all fields to null.
+//
+// The returned slice is newly allocated and is not modified by later calls to the builder.
+func (y *YxdbRecordBuilder) Build() []byte {
+	var record []byte
+	if y.HasVar {
+		record = make([]byte, y.FixedSize+4, y.FixedSize+4+y.varLength())
+	} else {
+		record = make([]byte, y.FixedSize)
+	}
+	copy(record, y.fixed)
+	for _, index := range y.varFields {
+		record = y.blobSetters[index](record, y.varValues[index])
+	}
+	if y.HasVar {
+		binary.LittleEndian.PutUint32(record[y.FixedSize:y.FixedSize+4], uint32(len(record)-y.FixedSize-4))
+	}
+	y.Reset()
+	return record
+}
+
+// Reset sets every field to null.
+func (y *YxdbRecordBuilder) Reset() {
+	for index := range y.Fields {
+		y.nullSetters[index]()
+	}
+}
+
+func (y *YxdbRecordBuilder) SetNullWithIndex(index int) {
+	setter, ok := y.nullSetters[index]
+	if !ok {
+		panic(invalidFieldIndex(index))
+	}
+	setter()
+}
+
+func (y *YxdbRecordBuilder) SetNullWithName(name string) {
+	y.SetNullWithIndex(y.indexOf(name))
+}
+
+func (y *YxdbRecordBuilder) SetBoolWithIndex(index int, value bool) {
+	setter, ok := y.boolSetters[index]
+	if !ok {
+		panic(invalidIndex(index, `bool`))
+	}
+	setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetBoolWithName(name string, value bool) {
+	y.SetBoolWithIndex(y.indexOf(name), value)
+}
+
+func (y *YxdbRecordBuilder) SetByteWithIndex(index int, value byte) {
+	setter, ok := y.byteSetters[index]
+	if !ok {
+		panic(invalidIndex(index, `byte`))
+	}
+	setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetByteWithName(name string, value byte) {
+	y.SetByteWithIndex(y.indexOf(name), value)
+}
+
+// SetInt64WithIndex sets an integer field. If the value does not fit in the field, the field is left unchanged and an
+// error wrapping setters.ErrOutOfRange is returned.
+func (y *YxdbRecordBuilder) SetInt64WithIndex(index int, value int64) error {
+	setter, ok := y.int64Setters[index]
+	if !ok {
+		panic(invalidIndex(index, `int64`))
+	}
+	return setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetInt64WithName(name string, value int64) error {
+	return y.SetInt64WithIndex(y.indexOf(name), value)
+}
+
+// SetFloat64WithIndex sets a numeric field. If the value does not fit in a FixedDecimal field, the field is left
+// unchanged and an error wrapping setters.ErrOutOfRange is returned.
+func (y *YxdbRecordBuilder) SetFloat64WithIndex(index int, value float64) error {
+	setter, ok := y.float64Setters[index]
+	if !ok {
+		panic(invalidIndex(index, `float64`))
+	}
+	return setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetFloat64WithName(name string, value float64) error {
+	return y.SetFloat64WithIndex(y.indexOf(name), value)
+}
+
+func (y *YxdbRecordBuilder) SetStringWithIndex(index int, value string) {
+	setter, ok := y.stringSetters[index]
+	if !ok {
+		panic(invalidIndex(index, `string`))
+	}
+	setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetStringWithName(name string, value string) {
+	y.SetStringWithIndex(y.indexOf(name), value)
+}
+
+func (y *YxdbRecordBuilder) SetTimeWithIndex(index int, value time.Time) {
+	setter, ok := y.timeSetters[index]
+	if !ok {
+		panic(invalidIndex(index, `time`))
+	}
+	setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetTimeWithName(name string, value time.Time) {
+	y.SetTimeWithIndex(y.indexOf(name), value)
+}
+
+func (y *YxdbRecordBuilder) SetBlobWithIndex(index int, value []byte) {
+	setter, ok := y.blobValues[index]
+	if !ok {
+		panic(invalidIndex(index, `blob`))
+	}
+	setter(value)
+}
+
+func (y *YxdbRecordBuilder) SetBlobWithName(name string, value []byte) {
+	y.SetBlobWithIndex(y.indexOf(name), value)
+}
+
+func (y *YxdbRecordBuilder) addBoolSetter(field m.MetaInfoField, setter s.BoolSetter, nullSetter s.NullSetter) {
+	index := y.addField(field, Boolean, nullSetter)
+	y.boolSetters[index] = func(value bool) { setter(y.fixed, value) }
+}
+
+func (y *YxdbRecordBuilder) addByteSetter(field m.MetaInfoField, setter s.ByteSetter, nullSetter s.NullSetter) {
+	index := y.addField(field, Byte, nullSetter)
+	y.byteSetters[index] = func(value byte) { setter(y.fixed, value) }
+}
+
+func (y *YxdbRecordBuilder) addInt64Setter(field m.MetaInfoField, setter s.Int64Setter, nullSetter s.NullSetter) {
+	index := y.addField(field, Int64, nullSetter)
+	y.int64Setters[index] = func(value int64) error { return setter(y.fixed, value) }
+}
+
+func (y *YxdbRecordBuilder) addFloat64Setter(field m.MetaInfoField, setter s.Float64Setter, nullSetter s.NullSetter) {
+	index := y.addField(field, Float64, nullSetter)
+	y.float64Setters[index] = func(value float64) error { return setter(y.fixed, value) }
+}
+
+func (y *YxdbRecordBuilder) addStringSetter(field m.MetaInfoField, setter s.StringSetter, nullSetter s.NullSetter) {
+	index := y.addField(field, String, nullSetter)
+	y.stringSetters[index] = func(value string) { setter(y.fixed, value) }
+}
+
+func (y *YxdbRecordBuilder) addTimeSetter(field m.MetaInfoField, setter s.TimeSetter, nullSetter s.NullSetter) {
+	index := y.addField(field, Date, nullSetter)
+	y.timeSetters[index] = func(value time.Time) { setter(y.fixed, value) }
+}
+
+func (y *YxdbRecordBuilder) addVarStringSetter(field m.MetaInfoField, setter s.BlobSetter, toBytes func(string) []byte) {
+	index := y.addVarField(field, String, setter)
+	y.stringSetters[index] = func(value string) { y.varValues[index] = toBytes(value) }
+}
+
+func (y *YxdbRecordBuilder) addBlobSetter(field m.MetaInfoField, setter s.BlobSetter) {
+	index := y.addVarField(field, Blob, setter)
+	y.blobValues[index] = func(value []byte) { y.varValues[index] = value }
+}
+
+func (y *YxdbRecordBuilder) addVarField(field m.MetaInfoField, dataType DataType, setter s.BlobSetter) int {
+	var index int
+	index = y.addField(field, dataType, func([]byte) { y.varValues[index] = nil })
+	y.blobSetters[index] = setter
+	y.varFields = append(y.varFields, index)
+	y.HasVar = true
+	return index
+}
+
+func (y *YxdbRecordBuilder) addField(field m.MetaInfoField, dataType DataType, nullSetter s.NullSetter) int {
+	index := len(y.Fields)
+	y.Fields = append(y.Fields, newYxdbField(field, dataType))
+	y.nameToIndex[field.Name] = index
+	y.nullSetters[index] = func() { nullSetter(y.fixed) }
+	return index
+}
+
+func (y *YxdbRecordBuilder) indexOf(name string) int {
+	index, ok := y.nameToIndex[name]
+	if !ok {
+		panic(invalidName(name))
+	}
+	return index
+}
+
+func (y *YxdbRecordBuilder) varLength() int {
+	length := 0
+	for _, index := range y.varFields {
+		length += len(y.varValues[index]) + 4
+	}
+	return length
+}
@@ -0,0 +1,111 @@
+package yxrecord
+
+import "fmt"
+
+// FieldType is the exact Alteryx type of a field, as declared in the MetaInfo XML of a .yxdb file.
+//
+// Several field types are read with the same ReadXxx methods; DataType returns that coarser category.
+type FieldType int
+
+const (
+	TypeBool FieldType = iota
+	TypeByte
+	TypeInt16
+	TypeInt32
+	TypeInt64
+	TypeFixedDecimal
+	TypeFloat
+	TypeDouble
+	TypeString
+	TypeWString
+	TypeV_String
+	TypeV_WString
+	TypeDate
+	TypeDateTime
+	TypeBlob
+	TypeSpatialObj
+)
+
+var fieldTypeNames = []string{
+	`Bool`,
+	`Byte`,
+	`Int16`,
+	`Int32`,
+	`Int64`,
+	`FixedDecimal`,
+	`Float`,
+	`Double`,
+	`String`,
+	`WString`,
+	`V_String`,
+	`V_WString`,
+	`Date`,
+	`DateTime`,
+	`Blob`,
+	`SpatialObj`,
+}
+
+var dataTypeNames = []string{
+	`Blob`,
+	`Boolean`,
+	`Byte`,
+	`Date`,
+	`Float64`,
+	`Int64`,
+	`String`,
+}
+
+// ParseFieldType returns the FieldType with the specified Alteryx type name, such as "V_WString".
+func ParseFieldType(name string) (FieldType, error) {
+	for index, typeName := range fieldTypeNames {
+		if typeName == name {
+			return FieldType(index), nil
+		}
+	}
+	return 0, fmt.Errorf(`'%v' is not a valid field type`, name)
+}
+
+// String returns the Alteryx name of the field type.
+func (t FieldType) String() string {
+	if t < 0 || int(t) >= len(fieldTypeNames) {
+		return fmt.Sprintf(`FieldType(%d)`, int(t))
+	}
+	return fieldTypeNames[t]
+}
+
+// DataType returns the category of ReadXxx methods used to read fields of this type.
+func (t FieldType) DataType() DataType {
+	switch t {
+	case TypeBool:
+		return Boolean
+	case TypeByte:
+		return Byte
+	case TypeInt16, TypeInt32, TypeInt64:
+		return Int64
+	case TypeFixedDecimal, TypeFloat, TypeDouble:
+		return Float64
+	case TypeString, TypeWString, TypeV_String, TypeV_WString:
+		return String
+	case TypeDate, TypeDateTime:
+		return Date
+	default:
+		return Blob
+	}
+}
+
+// IsVariableLength returns true if values of this type are stored in the variable-length portion of a record.
+func (t FieldType) IsVariableLength() bool {
+	switch t {
+	case TypeV_String, TypeV_WString, TypeBlob, TypeSpatialObj:
+		return true
+	default:
+		return false
+	}
+}
+
+func (d DataType) String() string {
+	if d < 0 || int(d) >= len(dataTypeNames) {
+		return fmt.Sprintf(`DataType(%d)`, int(d))
+	}
+	return dataTypeNames[d]
+}
@@ -3,11 +3,18 @@ package yxrecord
 import (
 	"errors"
 	"fmt"
+	"github.com/tlarsendataguy-yxdb/yxdb-go/decimal"
 	e "github.com/tlarsendataguy-yxdb/yxdb-go/extractors"
 	m "github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
 	"time"
 )
 
+// ErrFieldNotFound is returned by the TryExtractXxx methods when a field name or index does not exist.
+var ErrFieldNotFound = errors.New(`field not found`)
+
+// ErrTypeMismatch is returned by the TryExtractXxx methods when a field cannot be read as the requested type.
+var ErrTypeMismatch = errors.New(`field type mismatch`)
+
 type DataType int
 
 const (
@@ -21,9 +28,19 @@ const (
 )
 
 // YxdbField contains the name and type of field in a .yxdb file.
+//
+// Type is the category of ReadXxx methods used to read the field. FieldType is the exact Alteryx type of the field.
+// Size and Scale are the values declared in the MetaInfo XML. Size is kept for every field type: it is the maximum
+// number of characters in String, WString, V_String, and V_WString fields, the total number of characters in
+// FixedDecimal fields, the maximum number of bytes in Blob and SpatialObj fields, and the declared width of the other
+// types. It is 0 when the MetaInfo does not declare a size. Scale is the number of decimal places in FixedDecimal
+// fields and 0 for the other types.
 type YxdbField struct {
-	Name string
-	Type DataType
+	Name      string
+	Type      DataType
+	FieldType FieldType
+	Size      int
+	Scale     int
 }
 
 type YxdbRecord struct {
@@ -35,72 +52,67 @@ type YxdbRecord struct {
 	byteExtractors    map[int]e.ByteExtractor
 	int64Extractors   map[int]e.Int64Extractor
 	float64Extractors map[int]e.Float64Extractor
+	decimalExtractors map[int]e.DecimalExtractor
 	stringExtractors  map[int]e.StringExtractor
 	timeExtractors    map[int]e.TimeExtractor
 	blobExtractors    map[int]e.BlobExtractor
+	valueExtractors   []valueExtractor
 }
 
+type valueExtractor func([]byte) (any, bool)
+
 func FromFieldList(fields []m.MetaInfoField) (*YxdbRecord, error) {
-	record := &YxdbRecord{
-		Fields:            make([]YxdbField, 0, len(fields)),
-		nameToIndex:       make(map[string]int, len(fields)),
-		boolExtractors:    make(map[int]e.BoolExtractor),
-		byteExtractors:    make(map[int]e.ByteExtractor),
-		int64Extractors:   make(map[int]e.Int64Extractor),
-		float64Extractors: make(map[int]e.Float64Extractor),
-		stringExtractors:  make(map[int]e.StringExtractor),
-		timeExtractors:    make(map[int]e.TimeExtractor),
-		blobExtractors:    make(map[int]e.BlobExtractor),
-	}
+	record := newYxdbRecord(len(fields))
 	startAt := 0
 	for _, field := range fields {
 		switch field.Type {
 		case `Int16`:
-			record.addInt64Extractor(field.Name, e.NewInt16Extractor(startAt))
+			record.addInt64Extractor(field, e.NewInt16Extractor(startAt))
 			startAt += 3
 		case `Int32`:
-			record.addInt64Extractor(field.Name, e.NewInt32Extractor(startAt))
+			record.addInt64Extractor(field, e.NewInt32Extractor(startAt))
 			startAt += 5
 		case `Int64`:
-			record.addInt64Extractor(field.Name, e.NewInt64Extractor(startAt))
+			record.addInt64Extractor(field, e.NewInt64Extractor(startAt))
 			startAt += 9
 		case `Float`:
-			record.addFloat64Extractor(field.Name, e.NewFloatExtractor(startAt))
+			record.addFloat64Extractor(field, e.NewFloatExtractor(startAt))
 			startAt += 5
 		case `Double`:
-			record.addFloat64Extractor(field.Name, e.NewDoubleExtractor(startAt))
+			record.addFloat64Extractor(field, e.NewDoubleExtractor(startAt))
 			startAt += 9
 		case `FixedDecimal`:
-			record.addFloat64Extractor(field.Name, e.NewFixedDecimalExtractor(startAt, field.Size))
+			record.addFloat64Extractor(field, e.NewFixedDecimalExtractor(startAt, field.Size))
+			record.decimalExtractors[len(record.Fields)-1] = e.NewDecimalExtractor(startAt, field.Size, field.Scale)
 			startAt += field.Size + 1
 		case `String`:
-			record.addStringExtractor(field.Name, e.NewStringExtractor(startAt, field.Size))
+			record.addStringExtractor(field, e.NewStringExtractor(startAt, field.Size))
 			startAt += field.Size + 1
 		case `WString`:
-			record.addStringExtractor(field.Name, e.NewWStringExtractor(startAt, field.Size))
+			record.addStringExtractor(field, e.NewWStringExtractor(startAt, field.Size))
 			startAt += (field.Size * 2) + 1
 		case `V_String`:
-			record.addStringExtractor(field.Name, e.NewV_StringExtractor(startAt))
+			record.addStringExtractor(field, e.NewV_StringExtractor(startAt))
 			startAt += 4
 			record.HasVar = true
 		case `V_WString`:
-			record.addStringExtractor(field.Name, e.NewV_WStringExtractor(startAt))
+			record.addStringExtractor(field, e.NewV_WStringExtractor(startAt))
 			startAt += 4
 			record.HasVar = true
 		case `Date`:
-			record.addTimeExtractor(field.Name, e.NewDateExtractor(startAt))
+			record.addTimeExtractor(field, e.NewDateExtractor(startAt))
 			startAt += 11
 		case `DateTime`:
-			record.addTimeExtractor(field.Name, e.NewDateTimeExtractor(startAt))
+			record.addTimeExtractor(field, e.NewDateTimeExtractor(startAt))
 			startAt += 20
 		case `Bool`:
-			record.addBoolExtractor(field.Name, e.NewBoolExtractor(startAt))
+			record.addBoolExtractor(field, e.NewBoolExtractor(startAt))
 			startAt += 1
 		case `Byte`:
-			record.addByteExtractor(field.Name, e.NewByteExtractor(startAt))
+			record.addByteExtractor(field, e.NewByteExtractor(startAt))
 			startAt += 2
 		case `Blob`, `SpatialObj`:
-			record.addBlobExtractor(field.Name, e.NewBlobExtractor(startAt))
+			record.addBlobExtractor(field, e.NewBlobExtractor(startAt))
 			startAt += 4
 			record.HasVar = true
 		default:
@@ -108,9 +120,69 @@ func FromFieldList(fields []m.MetaInfoField) (*YxdbRecord, error) {
 		}
 	}
 	record.FixedSize = startAt
+	record.buildValueExtractors()
 	return record, nil
 }
 
+// Project returns a record that reads only the named fields, in the order they are listed, from the same buffers as
+// y. Fields are indexed by their position in names.
+//
+// If a name does not exist, Project returns an error wrapping ErrFieldNotFound.
+func (y *YxdbRecord) Project(names []string) (*YxdbRecord, error) {
+	projected := newYxdbRecord(len(names))
+	projected.FixedSize = y.FixedSize
+	projected.HasVar = y.HasVar
+	for newIndex, name := range names {
+		index, err := y.IndexOf(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := projected.nameToIndex[name]; exists {
+			return nil, fmt.Errorf(`field '%v' is projected more than once`, name)
+		}
+		projected.Fields = append(projected.Fields, y.Fields[index])
+		projected.nameToIndex[name] = newIndex
+		copyExtractor(y.boolExtractors, projected.boolExtractors, index, newIndex)
+		copyExtractor(y.byteExtractors, projected.byteExtractors, index, newIndex)
+		copyExtractor(y.int64Extractors, projected.int64Extractors, index, newIndex)
+		copyExtractor(y.float64Extractors, projected.float64Extractors, index, newIndex)
+		copyExtractor(y.decimalExtractors, projected.decimalExtractors, index, newIndex)
+		copyExtractor(y.stringExtractors, projected.stringExtractors, index, newIndex)
+		copyExtractor(y.timeExtractors, projected.timeExtractors, index, newIndex)
+		copyExtractor(y.blobExtractors, projected.blobExtractors, index, newIndex)
+	}
+	projected.buildValueExtractors()
+	return projected, nil
+}
+
+func newYxdbRecord(fieldCount int) *YxdbRecord {
+	return &YxdbRecord{
+		Fields:            make([]YxdbField, 0, fieldCount),
+		nameToIndex:       make(map[string]int, fieldCount),
+		boolExtractors:    make(map[int]e.BoolExtractor),
+		byteExtractors:    make(map[int]e.ByteExtractor),
+		int64Extractors:   make(map[int]e.Int64Extractor),
+		float64Extractors: make(map[int]e.Float64Extractor),
+		decimalExtractors: make(map[int]e.DecimalExtractor),
+		stringExtractors:  make(map[int]e.StringExtractor),
+		timeExtractors:    make(map[int]e.TimeExtractor),
+		blobExtractors:    make(map[int]e.BlobExtractor),
+	}
+}
+
+func copyExtractor[T any](from map[int]T, to map[int]T, fromIndex int, toIndex int) {
+	if extractor, ok := from[fromIndex]; ok {
+		to[toIndex] = extractor
+	}
+}
+
+func (y *YxdbRecord) buildValueExtractors() {
+	y.valueExtractors = make([]valueExtractor, len(y.Fields))
+	for index := range y.Fields {
+		y.valueExtractors[index] = y.newValueExtractor(index)
+	}
+}
+
 func (y *YxdbRecord) ExtractInt64WithIndex(index int, buffer []byte) (int64, bool) {
 	extractor, ok := y.int64Extractors[index]
 	if !ok {
@@ -143,6 +215,22 @@ func (y *YxdbRecord) ExtractFloat64WithName(name string, buffer []byte) (float64
 	return y.ExtractFloat64WithIndex(index, buffer)
 }
 
+func (y *YxdbRecord) ExtractDecimalWithIndex(index int, buffer []byte) (decimal.Decimal, bool) {
+	extractor, ok := y.decimalExtractors[index]
+	if !ok {
+		panic(invalidIndex(index, `decimal`))
+	}
+	return extractor(buffer)
+}
+
+func (y *YxdbRecord) ExtractDecimalWithName(name string, buffer []byte) (decimal.Decimal, bool) {
+	index, ok := y.nameToIndex[name]
+	if !ok {
+		panic(invalidName(name))
+	}
+	return y.ExtractDecimalWithIndex(index, buffer)
+}
+
 func (y *YxdbRecord) ExtractStringWithIndex(index int, buffer []byte) (string, bool) {
 	extractor, ok := y.stringExtractors[index]
 	if !ok {
@@ -223,51 +311,337 @@ func (y *YxdbRecord) ExtractBlobWithName(name string, buffer []byte) []byte {
 	return y.ExtractBlobWithIndex(index, buffer)
 }
 
-func (y *YxdbRecord) addInt64Extractor(name string, extractor e.Int64Extractor) {
-	index := y.addFieldNameToIndexMap(name, Int64)
+func (y *YxdbRecord) TryExtractInt64WithIndex(index int, buffer []byte) (int64, bool, error) {
+	extractor, ok := y.int64Extractors[index]
+	if !ok {
+		return 0, false, y.invalidIndexError(index, `int64`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractInt64WithName(name string, buffer []byte) (int64, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return 0, false, err
+	}
+	return y.TryExtractInt64WithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractFloat64WithIndex(index int, buffer []byte) (float64, bool, error) {
+	extractor, ok := y.float64Extractors[index]
+	if !ok {
+		return 0, false, y.invalidIndexError(index, `float64`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractFloat64WithName(name string, buffer []byte) (float64, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return 0, false, err
+	}
+	return y.TryExtractFloat64WithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractDecimalWithIndex(index int, buffer []byte) (decimal.Decimal, bool, error) {
+	extractor, ok := y.decimalExtractors[index]
+	if !ok {
+		return decimal.Decimal{}, false, y.invalidIndexError(index, `decimal`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractDecimalWithName(name string, buffer []byte) (decimal.Decimal, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return decimal.Decimal{}, false, err
+	}
+	return y.TryExtractDecimalWithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractStringWithIndex(index int, buffer []byte) (string, bool, error) {
+	extractor, ok := y.stringExtractors[index]
+	if !ok {
+		return ``, false, y.invalidIndexError(index, `string`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractStringWithName(name string, buffer []byte) (string, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return ``, false, err
+	}
+	return y.TryExtractStringWithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractTimeWithIndex(index int, buffer []byte) (time.Time, bool, error) {
+	extractor, ok := y.timeExtractors[index]
+	if !ok {
+		return time.Time{}, false, y.invalidIndexError(index, `time`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractTimeWithName(name string, buffer []byte) (time.Time, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return y.TryExtractTimeWithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractBoolWithIndex(index int, buffer []byte) (bool, bool, error) {
+	extractor, ok := y.boolExtractors[index]
+	if !ok {
+		return false, false, y.invalidIndexError(index, `bool`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractBoolWithName(name string, buffer []byte) (bool, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return false, false, err
+	}
+	return y.TryExtractBoolWithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractByteWithIndex(index int, buffer []byte) (byte, bool, error) {
+	extractor, ok := y.byteExtractors[index]
+	if !ok {
+		return 0, false, y.invalidIndexError(index, `byte`)
+	}
+	value, isNull := extractor(buffer)
+	return value, isNull, nil
+}
+
+func (y *YxdbRecord) TryExtractByteWithName(name string, buffer []byte) (byte, bool, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return 0, false, err
+	}
+	return y.TryExtractByteWithIndex(index, buffer)
+}
+
+func (y *YxdbRecord) TryExtractBlobWithIndex(index int, buffer []byte) ([]byte, error) {
+	extractor, ok := y.blobExtractors[index]
+	if !ok {
+		return nil, y.invalidIndexError(index, `blob`)
+	}
+	return extractor(buffer), nil
+}
+
+func (y *YxdbRecord) TryExtractBlobWithName(name string, buffer []byte) ([]byte, error) {
+	index, err := y.IndexOf(name)
+	if err != nil {
+		return nil, err
+	}
+	return y.TryExtractBlobWithIndex(index, buffer)
+}
+
+// ExtractValueWithIndex reads the field at the specified index as the Go type used by its ExtractXxx method: byte,
+// bool, int64, float64, string, time.Time, or []byte. FixedDecimal fields are read as decimal.Decimal. Null values
+// are returned as nil.
+//
+// Blob values are copied out of buffer, so they remain valid after the buffer is reused.
+func (y *YxdbRecord) ExtractValueWithIndex(index int, buffer []byte) (any, bool) {
+	if index < 0 || index >= len(y.valueExtractors) {
+		panic(invalidFieldIndex(index))
+	}
+	return y.valueExtractors[index](buffer)
+}
+
+func (y *YxdbRecord) ExtractValueWithName(name string, buffer []byte) (any, bool) {
+	index, ok := y.nameToIndex[name]
+	if !ok {
+		panic(invalidName(name))
+	}
+	return y.ExtractValueWithIndex(index, buffer)
+}
+
+// IndexOf returns the index of the field with the specified name.
+//
+// If the name is not valid, IndexOf returns an error wrapping ErrFieldNotFound.
+func (y *YxdbRecord) IndexOf(name string) (int, error) {
+	index, ok := y.nameToIndex[name]
+	if !ok {
+		return 0, &fieldError{message: invalidName(name), kind: ErrFieldNotFound}
+	}
+	return index, nil
+}
+
+// BoolExtractor returns the extractor used by ExtractBoolWithIndex for the field at the specified index, so that
+// callers reading many records can look it up once rather than for every record. The other XxxExtractor methods do
+// the same for their ExtractXxxWithIndex counterparts.
+//
+// If the field at the specified index is not a boolean field, BoolExtractor returns false.
+func (y *YxdbRecord) BoolExtractor(index int) (e.BoolExtractor, bool) {
+	extractor, ok := y.boolExtractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) ByteExtractor(index int) (e.ByteExtractor, bool) {
+	extractor, ok := y.byteExtractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) Int64Extractor(index int) (e.Int64Extractor, bool) {
+	extractor, ok := y.int64Extractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) Float64Extractor(index int) (e.Float64Extractor, bool) {
+	extractor, ok := y.float64Extractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) DecimalExtractor(index int) (e.DecimalExtractor, bool) {
+	extractor, ok := y.decimalExtractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) StringExtractor(index int) (e.StringExtractor, bool) {
+	extractor, ok := y.stringExtractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) TimeExtractor(index int) (e.TimeExtractor, bool) {
+	extractor, ok := y.timeExtractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) BlobExtractor(index int) (e.BlobExtractor, bool) {
+	extractor, ok := y.blobExtractors[index]
+	return extractor, ok
+}
+
+func (y *YxdbRecord) newValueExtractor(index int) valueExtractor {
+	if extractor, ok := y.decimalExtractors[index]; ok {
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	}
+	switch y.Fields[index].Type {
+	case Int64:
+		extractor := y.int64Extractors[index]
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	case Float64:
+		extractor := y.float64Extractors[index]
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	case String:
+		extractor := y.stringExtractors[index]
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	case Date:
+		extractor := y.timeExtractors[index]
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	case Boolean:
+		extractor := y.boolExtractors[index]
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	case Byte:
+		extractor := y.byteExtractors[index]
+		return func(buffer []byte) (any, bool) { return nullable(extractor(buffer)) }
+	default:
+		extractor := y.blobExtractors[index]
+		return func(buffer []byte) (any, bool) {
+			value := extractor(buffer)
+			if value == nil {
+				return nil, true
+			}
+			return append([]byte{}, value...), false
+		}
+	}
+}
+
+func nullable[T any](value T, isNull bool) (any, bool) {
+	if isNull {
+		return nil, true
+	}
+	return value, false
+}
+
+func (y *YxdbRecord) addInt64Extractor(field m.MetaInfoField, extractor e.Int64Extractor) {
+	index := y.addFieldNameToIndexMap(field, Int64)
 	y.int64Extractors[index] = extractor
 }
 
-func (y *YxdbRecord) addFloat64Extractor(name string, extractor e.Float64Extractor) {
-	index := y.addFieldNameToIndexMap(name, Float64)
+func (y *YxdbRecord) addFloat64Extractor(field m.MetaInfoField, extractor e.Float64Extractor) {
+	index := y.addFieldNameToIndexMap(field, Float64)
 	y.float64Extractors[index] = extractor
 }
 
-func (y *YxdbRecord) addStringExtractor(name string, extractor e.StringExtractor) {
-	index := y.addFieldNameToIndexMap(name, String)
+func (y *YxdbRecord) addStringExtractor(field m.MetaInfoField, extractor e.StringExtractor) {
+	index := y.addFieldNameToIndexMap(field, String)
 	y.stringExtractors[index] = extractor
 }
 
-func (y *YxdbRecord) addTimeExtractor(name string, extractor e.TimeExtractor) {
-	index := y.addFieldNameToIndexMap(name, Date)
+func (y *YxdbRecord) addTimeExtractor(field m.MetaInfoField, extractor e.TimeExtractor) {
+	index := y.addFieldNameToIndexMap(field, Date)
 	y.timeExtractors[index] = extractor
 }
 
-func (y *YxdbRecord) addBoolExtractor(name string, extractor e.BoolExtractor) {
-	index := y.addFieldNameToIndexMap(name, Boolean)
+func (y *YxdbRecord) addBoolExtractor(field m.MetaInfoField, extractor e.BoolExtractor) {
+	index := y.addFieldNameToIndexMap(field, Boolean)
 	y.boolExtractors[index] = extractor
 }
 
-func (y *YxdbRecord) addByteExtractor(name string, extractor e.ByteExtractor) {
-	index := y.addFieldNameToIndexMap(name, Byte)
+func (y *YxdbRecord) addByteExtractor(field m.MetaInfoField, extractor e.ByteExtractor) {
+	index := y.addFieldNameToIndexMap(field, Byte)
 	y.byteExtractors[index] = extractor
 }
 
-func (y *YxdbRecord) addBlobExtractor(name string, extractor e.BlobExtractor) {
-	index := y.addFieldNameToIndexMap(name, Blob)
+func (y *YxdbRecord) addBlobExtractor(field m.MetaInfoField, extractor e.BlobExtractor) {
+	index := y.addFieldNameToIndexMap(field, Blob)
 	y.blobExtractors[index] = extractor
 }
 
-func (y *YxdbRecord) addFieldNameToIndexMap(name string, dataType DataType) int {
+func (y *YxdbRecord) addFieldNameToIndexMap(field m.MetaInfoField, dataType DataType) int {
 	index := len(y.Fields)
-	y.Fields = append(y.Fields, YxdbField{
-		Name: name,
-		Type: dataType,
-	})
-	y.nameToIndex[name] = index
+	y.Fields = append(y.Fields, newYxdbField(field, dataType))
+	y.nameToIndex[field.Name] = index
 	return index
 }
 
+func newYxdbField(field m.MetaInfoField, dataType DataType) YxdbField {
+	fieldType, _ := ParseFieldType(field.Type)
+	yxdbField := YxdbField{
+		Name:      field.Name,
+		Type:      dataType,
+		FieldType: fieldType,
+		Size:      field.Size,
+	}
+	if fieldType == TypeFixedDecimal {
+		yxdbField.Scale = field.Scale
+	}
+	return yxdbField
+}
+
+func (y *YxdbRecord) invalidIndexError(index int, dataType string) error {
+	if index < 0 || index >= len(y.Fields) {
+		return &fieldError{message: invalidFieldIndex(index), kind: ErrFieldNotFound}
+	}
+	return &fieldError{message: invalidIndex(index, dataType), kind: ErrTypeMismatch}
+}
+
+// fieldError keeps the same message as the panics raised by the ExtractXxx methods while allowing callers to test
+// for the kind of error with errors.Is.
+type fieldError struct {
+	message string
+	kind    error
+}
+
+func (f *fieldError) Error() string {
+	return f.message
+}
+
+func (f *fieldError) Unwrap() error {
+	return f.kind
+}
+
 func invalidIndex(index int, dataType string) string {
 	return fmt.Sprintf(`field at index %v is not a %v field`, index, dataType)
 }
@@ -275,3 +649,7 @@ func invalidIndex(index int, dataType string) string {
 func invalidName(name string) string {
 	return fmt.Sprintf(`field '%v' does not exist`, name)
 }
+
+func invalidFieldIndex(index int) string {
+	return fmt.Sprintf(`field index %v does not exist`, index)
+}
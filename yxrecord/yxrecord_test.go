@@ -1,6 +1,7 @@
 package yxrecord_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/tlarsendataguy-yxdb/yxdb-go/metafield"
 	r "github.com/tlarsendataguy-yxdb/yxdb-go/yxrecord"
@@ -138,6 +139,182 @@ func TestReadSpatialObjRecord(t *testing.T) {
 	checkBlobValue(t, record, source, []byte{})
 }
 
+func TestTryExtractValue(t *testing.T) {
+	record := loadRecordWithValueColumn("Int16", 2)
+	source := []byte{23, 0, 0}
+
+	value, isNull, err := record.TryExtractInt64WithName(`value`, source)
+	if err != nil || isNull || value != 23 {
+		t.Fatalf(`expected 23, not null and no error but got %v, %v and %v`, value, isNull, err)
+	}
+	_, _, err = record.TryExtractByteWithIndex(0, source)
+	if !errors.Is(err, r.ErrTypeMismatch) {
+		t.Fatalf(`expected ErrTypeMismatch but got %v`, err)
+	}
+	_, _, err = record.TryExtractInt64WithIndex(1, source)
+	if !errors.Is(err, r.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+	_, err = record.TryExtractBlobWithName(`invalid`, source)
+	if !errors.Is(err, r.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+}
+
+func TestFieldTypes(t *testing.T) {
+	typeNames := []string{`Bool`, `Byte`, `Int16`, `Int32`, `Int64`, `FixedDecimal`, `Float`, `Double`, `String`,
+		`WString`, `V_String`, `V_WString`, `Date`, `DateTime`, `Blob`, `SpatialObj`}
+	for _, typeName := range typeNames {
+		record := loadRecordWithValueColumn(typeName, 10)
+		field := record.Fields[0]
+		if fieldType := field.FieldType.String(); fieldType != typeName {
+			t.Fatalf(`expected field type %v but got %v`, typeName, fieldType)
+		}
+		parsed, err := r.ParseFieldType(typeName)
+		if err != nil {
+			t.Fatalf(`expected no error but got: %v`, err.Error())
+		}
+		if parsed != field.FieldType {
+			t.Fatalf(`expected %v but got %v`, field.FieldType, parsed)
+		}
+	}
+}
+
+func TestFieldSizeAndScale(t *testing.T) {
+	record, _ := r.FromFieldList([]metafield.MetaInfoField{
+		{Name: `decimal`, Type: `FixedDecimal`, Size: 19, Scale: 6},
+		{Name: `string`, Type: `WString`, Size: 50},
+		{Name: `int`, Type: `Int32`, Size: 4},
+		{Name: `text`, Type: `V_WString`, Size: 1000},
+		{Name: `blob`, Type: `Blob`, Size: 2147483647},
+	})
+	expected := []r.YxdbField{
+		{Name: `decimal`, Type: r.Float64, FieldType: r.TypeFixedDecimal, Size: 19, Scale: 6},
+		{Name: `string`, Type: r.String, FieldType: r.TypeWString, Size: 50},
+		{Name: `int`, Type: r.Int64, FieldType: r.TypeInt32, Size: 4},
+		{Name: `text`, Type: r.String, FieldType: r.TypeV_WString, Size: 1000},
+		{Name: `blob`, Type: r.Blob, FieldType: r.TypeBlob, Size: 2147483647},
+	}
+	if !reflect.DeepEqual(record.Fields, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, record.Fields)
+	}
+}
+
+func TestParseInvalidFieldType(t *testing.T) {
+	_, err := r.ParseFieldType(`Int128`)
+	if err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+	if fieldType := r.FieldType(99).String(); fieldType != `FieldType(99)` {
+		t.Fatalf(`expected FieldType(99) but got %v`, fieldType)
+	}
+	if dataType := r.Float64.String(); dataType != `Float64` {
+		t.Fatalf(`expected Float64 but got %v`, dataType)
+	}
+}
+
+func TestProject(t *testing.T) {
+	record, _ := r.FromFieldList([]metafield.MetaInfoField{
+		{Name: `first`, Type: `Int16`},
+		{Name: `second`, Type: `Bool`},
+		{Name: `third`, Type: `String`, Size: 2},
+	})
+	source := []byte{23, 0, 0, 1, 65, 66, 0}
+
+	projected, err := record.Project([]string{`third`, `first`})
+	if err != nil {
+		t.Fatalf(`expected no error but got: %v`, err.Error())
+	}
+	if len(projected.Fields) != 2 || projected.FixedSize != record.FixedSize {
+		t.Fatalf(`expected 2 fields with fixed size %v but got %v and %v`, record.FixedSize, projected.Fields, projected.FixedSize)
+	}
+	if value, _ := projected.ExtractStringWithIndex(0, source); value != `AB` {
+		t.Fatalf(`expected AB but got %v`, value)
+	}
+	if value, _ := projected.ExtractInt64WithIndex(1, source); value != 23 {
+		t.Fatalf(`expected 23 but got %v`, value)
+	}
+	if _, _, err = projected.TryExtractBoolWithName(`second`, source); !errors.Is(err, r.ErrFieldNotFound) {
+		t.Fatalf(`expected ErrFieldNotFound but got %v`, err)
+	}
+	if _, err = record.Project([]string{`first`, `first`}); err == nil {
+		t.Fatalf(`expected an error but got none`)
+	}
+}
+
+func TestExtractorLookup(t *testing.T) {
+	record, _ := r.FromFieldList([]metafield.MetaInfoField{
+		{Name: `first`, Type: `Int16`},
+		{Name: `second`, Type: `Bool`},
+	})
+	source := []byte{23, 0, 0, 1}
+
+	extractor, ok := record.Int64Extractor(0)
+	if !ok {
+		t.Fatalf(`expected an int64 extractor for field 0`)
+	}
+	if value, isNull := extractor(source); value != 23 || isNull {
+		t.Fatalf(`expected 23 but got %v (null: %v)`, value, isNull)
+	}
+	if _, ok = record.Int64Extractor(1); ok {
+		t.Fatalf(`expected no int64 extractor for field 1`)
+	}
+	if _, ok = record.BoolExtractor(2); ok {
+		t.Fatalf(`expected no bool extractor for field 2`)
+	}
+}
+
+func TestBuildFixedRecord(t *testing.T) {
+	fields := []metafield.MetaInfoField{
+		{Name: `int`, Type: `Int32`},
+		{Name: `value`, Type: `String`, Size: 5},
+	}
+	builder, _ := r.BuilderFromFieldList(fields)
+	builder.SetInt64WithName(`int`, 23)
+	builder.SetStringWithIndex(1, `abc`)
+	actual := builder.Build()
+
+	expected := []byte{23, 0, 0, 0, 0, 97, 98, 99, 0, 0, 0}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, actual)
+	}
+	actual = builder.Build()
+	expected = []byte{0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf(`expected %v but got %v`, expected, actual)
+	}
+}
+
+func TestBuildVarRecord(t *testing.T) {
+	fields := []metafield.MetaInfoField{
+		{Name: `blob`, Type: `Blob`},
+		{Name: `value`, Type: `V_WString`},
+	}
+	builder, _ := r.BuilderFromFieldList(fields)
+	if !builder.HasVar || builder.FixedSize != 8 {
+		t.Fatalf(`expected HasVar with fixed size 8 but got %v and %v`, builder.HasVar, builder.FixedSize)
+	}
+	builder.SetStringWithName(`value`, `hello world!`)
+	builder.SetBlobWithIndex(0, []byte{1, 2, 3, 4})
+	source := builder.Build()
+
+	record, _ := r.FromFieldList(fields)
+	checkStringValue(t, record, source, `hello world!`)
+	if blob := record.ExtractBlobWithIndex(0, source); !reflect.DeepEqual(blob, []byte{1, 2, 3, 4}) {
+		t.Fatalf(`expected [1 2 3 4] but got %v`, blob)
+	}
+}
+
+func TestBuildWithInvalidType(t *testing.T) {
+	defer func() {
+		if r := recover(); r != `field at index 0 is not a time field` {
+			t.Fatalf(`expected 'field at index 0 is not a time field' but got '%v'`, r)
+		}
+	}()
+	builder, _ := r.BuilderFromFieldList([]metafield.MetaInfoField{{Name: `value`, Type: `Bool`}})
+	builder.SetTimeWithIndex(0, time.Now())
+}
+
 func checkRecord(t *testing.T, record *r.YxdbRecord, dataType r.DataType, hasVar bool, fixedSize int) {
 	if fields := len(record.Fields); fields != 1 {
 		t.Fatalf(`expected 1 field but got %v`, fields)
@@ -148,6 +325,9 @@ func checkRecord(t *testing.T, record *r.YxdbRecord, dataType r.DataType, hasVar
 	if actualType := record.Fields[0].Type; actualType != dataType {
 		t.Fatalf(`expected '%v' data type but got %v`, dataType, actualType)
 	}
+	if fieldType := record.Fields[0].FieldType; fieldType.DataType() != dataType {
+		t.Fatalf(`expected field type %v to map to '%v' but got '%v'`, fieldType, dataType, fieldType.DataType())
+	}
 	if hasVar != record.HasVar {
 		t.Fatalf(`expected HasVar of %v but got %v`, hasVar, record.HasVar)
 	}